@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 对局进行中切换难度、重新开始、重玩种子、打开谜题或关闭窗口时先询问是否放弃，
+// 确认后记为放弃的一局，见 stats.go
+type abandonPrompt struct {
+	yes, no *Button
+	then    func() error // 确认放弃后执行的操作
+}
+
+// 对局进行中时先询问，否则直接执行 then
+func (g *Game) confirmAbandon(then func() error) error {
+	if !g.inProgress() {
+		return then()
+	}
+	w := 100
+	y := g.screenHeight()/2 + 10
+	g.abandon = &abandonPrompt{
+		yes:  &Button{X: g.screenWidth()/2 - w - 10, Y: y, W: w, H: 34, Text: "是"},
+		no:   &Button{X: g.screenWidth()/2 + 10, Y: y, W: w, H: 34, Text: "否"},
+		then: then,
+	}
+	return nil
+}
+
+// 处理询问框的输入，询问期间不响应其他操作。回车或 Y 确认，Esc 或 N 取消
+func (g *Game) updateAbandon() error {
+	p := g.abandon
+	x, y := cursorPosition()
+	p.yes.Hover, p.no.Hover = p.yes.Contains(x, y), p.no.Contains(x, y)
+	click := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	switch {
+	case click && p.yes.Hover, inpututil.IsKeyJustPressed(ebiten.KeyEnter), inpututil.IsKeyJustPressed(ebiten.KeyY):
+		g.abandon = nil
+		g.publish(EventGameAbandoned)
+		return p.then()
+	case click && p.no.Hover, inpututil.IsKeyJustPressed(ebiten.KeyEscape), inpututil.IsKeyJustPressed(ebiten.KeyN):
+		g.abandon = nil
+	}
+	return nil
+}
+
+func (g *Game) drawAbandon(screen *ebiten.Image) {
+	p := g.abandon
+	if p == nil {
+		return
+	}
+	vector.DrawFilledRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		activeTheme.MenuOverlay, false)
+	g.drawCentered(screen, tr("放弃当前游戏？"), g.screenHeight()/2-20)
+	g.drawButton(screen, p.yes)
+	g.drawButton(screen, p.no)
+}
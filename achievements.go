@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// 成就：对局结束时按统计数据和本局情况检查，解锁时弹出提示，
+// 解锁时间保存在统计数据中。机器人参与的对局不计
+type achievement struct {
+	id    string
+	title string // 中文，显示时经过 tr
+	desc  string
+	check func(s *Stats, e Event) bool
+}
+
+var achievements = []achievement{
+	{"first-win", "初次胜利", "赢下第一局", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon
+	}},
+	{"medium-60", "游刃有余", "60 秒内赢下中等难度", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && e.Difficulty == Medium && e.Variant == "" && e.Elapsed < 60*time.Second
+	}},
+	{"hard-100", "百秒困难", "100 秒内赢下困难难度", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && e.Difficulty == Hard && e.Variant == "" && e.Elapsed < 100*time.Second
+	}},
+	{"huge", "巨大胜利", "赢下巨大难度", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && e.Difficulty == Huge
+	}},
+	{"streak-10", "十连胜", "同一难度连续赢下 10 局", func(s *Stats, e Event) bool {
+		return e.Difficulty != Custom && s.forVariant(e.Difficulty, e.Variant).CurrentStreak >= 10
+	}},
+	// 普通规则下获胜需要插完所有的旗，因此只在无旗模式中达成
+	{"no-flags", "片旗不留", "在无旗模式中赢下中等或更难的一局", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && hasVariantPart(e.Variant, "nf") &&
+			(e.Difficulty == Medium || e.Difficulty == Hard || e.Difficulty == Huge)
+	}},
+	{"daily", "每日一练", "完成一次每日挑战", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && e.Challenge != nil && e.Challenge.Kind == challengeDaily
+	}},
+	{"knight", "马步通关", "赢下一局马步玩法", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && hasVariantPart(e.Variant, "knight")
+	}},
+	{"puzzle", "解谜者", "完成一个谜题", func(s *Stats, e Event) bool {
+		return e.Type == EventGameWon && e.Variant == "puzzle"
+	}},
+	{"games-100", "百战老兵", "累计玩满 100 局", func(s *Stats, e Event) bool {
+		return s.playedForAchievements() >= 100
+	}},
+}
+
+func hasVariantPart(variant, part string) bool {
+	for _, p := range strings.Split(variant, "+") {
+		if p == part {
+			return true
+		}
+	}
+	return false
+}
+
+// 计入成就的总局数：自定义难度的小棋盘可以很快刷局，放弃的对局也不算
+func (s *Stats) playedForAchievements() int {
+	played := 0
+	for key, ds := range s.Difficulties {
+		if key == difficultyKeys[Custom] || strings.HasPrefix(key, difficultyKeys[Custom]+"/") {
+			continue
+		}
+		played += ds.Played - ds.Abandoned
+	}
+	return played
+}
+
+// 本次运行中解锁的成就，在退出小结中列出
+var sessionAchievements []string
+
+// 检查本局结束后新解锁的成就，在统计数据记录本局之后调用
+func (s *Stats) checkAchievements(e Event) {
+	if e.Variant == "bot" || e.Variant == "assist" {
+		return
+	}
+	for _, a := range achievements {
+		if _, ok := s.Achievements[a.id]; ok || !a.check(s, e) {
+			continue
+		}
+		if s.Achievements == nil {
+			s.Achievements = make(map[string]time.Time)
+		}
+		s.Achievements[a.id] = time.Now()
+		sessionAchievements = append(sessionAchievements, a.title)
+		showToast(tr("成就解锁") + ": " + tr(a.title))
+		announceCosmetics(a.id)
+	}
+}
+
+// 成就一览：已解锁的在前，按解锁时间排列，未解锁的显示为灰色。
+// 窗口较窄，说明只在鼠标悬停时显示在底部
+func (g *Game) drawAchievements(screen *ebiten.Image) {
+	list := make([]achievement, len(achievements))
+	copy(list, achievements)
+	unlocked := globalStats.Achievements
+	sort.SliceStable(list, func(i, j int) bool {
+		ti, oki := unlocked[list[i].id]
+		tj, okj := unlocked[list[j].id]
+		if oki != okj {
+			return oki
+		}
+		return oki && ti.Before(tj)
+	})
+
+	g.drawCentered(screen, fmt.Sprintf("%d/%d", len(unlocked), len(achievements)), 56)
+	_, cy := cursorPosition()
+	locked := color.RGBA{130, 130, 130, 255}
+	for i, a := range list {
+		y := 86 + i*22
+		clr, mark := activeTheme.Text, "✓ "
+		at, ok := unlocked[a.id]
+		if !ok {
+			clr, mark = locked, "   "
+		}
+		text.Draw(screen, mark+tr(a.title), g.gameFont, 16, y, clr)
+		if cy > y-16 && cy <= y+6 {
+			desc := tr(a.desc)
+			if ok {
+				desc += "  " + at.Format("2006-01-02")
+			}
+			g.drawCentered(screen, desc, g.screenHeight()-56)
+		}
+	}
+}
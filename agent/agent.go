@@ -0,0 +1,168 @@
+// Package agent 定义外部机器人与游戏交互的接口：机器人收到玩家可见的棋盘，
+// 返回下一步操作。除了 Go 接口，还提供按行传输 JSON 的管道协议，
+// 可以通过标准输入输出或子进程接入任意语言编写的机器人
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// State 中格子的取值，0-8 为已翻开格子显示的数字
+const (
+	Unknown = -1 // 未翻开
+	Flag    = -2 // 插了旗
+	Missing = -3 // 不规则形状以外的格子
+)
+
+// 对局结果
+const (
+	ResultWon  = "won"
+	ResultLost = "lost"
+)
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// State 是交给机器人的可见棋盘，不包含地雷的真实位置
+type State struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Mines    int    `json:"mines"`              // 地雷总数
+	Topology string `json:"topology,omitempty"` // 相邻规则，空为周围 8 格
+	Cells    []int  `json:"cells"`              // 行优先存储，取值见 Unknown 等
+	Moves    int    `json:"moves"`              // 已经进行的操作数
+	Result   string `json:"result,omitempty"`   // 对局结束时为 ResultWon 或 ResultLost
+}
+
+func (s *State) At(x, y int) int {
+	return s.Cells[y*s.Width+x]
+}
+
+// 操作类型
+const (
+	Reveal  = "reveal"  // 翻开格子
+	Mark    = "flag"    // 插旗
+	Unmark  = "unflag"  // 取消旗子或问号
+	Restart = "restart" // 对局结束后重新开始，棋盘设置不变
+	Stop    = "stop"    // 停止操作，交还给玩家
+)
+
+// Action 是机器人的一步操作。Note 和 Reasons 可选，
+// 用于在界面上说明这一步的依据并高亮相关的格子
+type Action struct {
+	Kind    string  `json:"action"`
+	X       int     `json:"x"`
+	Y       int     `json:"y"`
+	Note    string  `json:"note,omitempty"`
+	Reasons []Point `json:"reasons,omitempty"`
+}
+
+// Agent 根据当前棋盘决定下一步。对局结束后仍会再调用一次，
+// 此时 State.Result 不为空，只能返回 Restart 或 Stop
+type Agent interface {
+	Act(s State) (Action, error)
+}
+
+// Pipe 通过管道与外部机器人交互：每次写入一行 State 的 JSON，读取一行 Action 的 JSON
+type Pipe struct {
+	mu     sync.Mutex // 上一次调用还没有收到回复时，下一次调用需要等待
+	r      *bufio.Scanner
+	w      io.Writer
+	closer func() error
+}
+
+func NewPipe(r io.Reader, w io.Writer) *Pipe {
+	s := bufio.NewScanner(r)
+	// 大棋盘的状态可能很长，回复同样放宽上限
+	s.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	return &Pipe{r: s, w: w}
+}
+
+// Stdio 使用本进程的标准输入输出，适合由机器人程序启动游戏
+func Stdio() *Pipe {
+	return NewPipe(os.Stdin, os.Stdout)
+}
+
+// Command 启动机器人程序，通过它的标准输入输出交互，标准错误直接输出到本进程
+func Command(name string, args ...string) (*Pipe, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建管道失败: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建管道失败: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动机器人失败: %v", err)
+	}
+	p := NewPipe(stdout, stdin)
+	p.closer = func() error {
+		stdin.Close()
+		return cmd.Wait()
+	}
+	return p, nil
+}
+
+func (p *Pipe) Act(s State) (Action, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return Action{}, fmt.Errorf("序列化失败: %v", err)
+	}
+	if _, err := p.w.Write(append(data, '\n')); err != nil {
+		return Action{}, fmt.Errorf("发送棋盘失败: %v", err)
+	}
+	if !p.r.Scan() {
+		if err := p.r.Err(); err != nil {
+			return Action{}, fmt.Errorf("读取操作失败: %v", err)
+		}
+		return Action{}, errors.New("机器人已退出")
+	}
+	var a Action
+	if err := json.Unmarshal(p.r.Bytes(), &a); err != nil {
+		return Action{}, fmt.Errorf("解析操作失败: %v", err)
+	}
+	return a, nil
+}
+
+// Close 结束机器人程序，标准输入输出不会关闭
+func (p *Pipe) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer()
+}
+
+// Validate 检查操作在棋盘 s 上是否有效
+func (a Action) Validate(s State) error {
+	switch a.Kind {
+	case Restart, Stop:
+		return nil
+	case Reveal, Mark, Unmark:
+	default:
+		return fmt.Errorf("未知操作: %q", a.Kind)
+	}
+	if s.Result != "" {
+		return fmt.Errorf("对局已经结束，不能执行 %s", a.Kind)
+	}
+	if a.X < 0 || a.X >= s.Width || a.Y < 0 || a.Y >= s.Height {
+		return fmt.Errorf("坐标超出棋盘: %d, %d", a.X, a.Y)
+	}
+	if c := s.At(a.X, a.Y); c != Unknown && c != Flag {
+		return fmt.Errorf("格子已经翻开或不存在: %d, %d", a.X, a.Y)
+	}
+	return nil
+}
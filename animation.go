@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+// 由 Update 每帧推进的动画，tick 返回 false 表示动画已经结束。
+// 动画只影响显示，对局状态在操作时已经确定
+type animation interface {
+	tick(now time.Time) bool
+}
+
+// 翻开格子时播放展开的波纹，踩中地雷时播放爆炸
+func (g *Game) subscribeAnimations(bus *EventBus) {
+	bus.Subscribe(EventCellRevealed, func(e Event) {
+		if g.gameOver {
+			g.explode(e.X, e.Y)
+			return
+		}
+		g.startRevealWave(e.X, e.Y, e.Opened)
+	})
+}
+
+// 推进所有进行中的动画，结束的动画被移除
+func (g *Game) updateAnimations() {
+	g.ticks++
+	now := time.Now()
+	if g.wave != nil && !g.wave.tick(now) {
+		g.wave = nil
+	}
+	running := g.animations[:0]
+	for _, a := range g.animations {
+		if a.tick(now) {
+			running = append(running, a)
+		}
+	}
+	g.animations = running
+}
+
+// 多帧贴图的播放方式，帧随游戏的 tick 推进，每秒 60 tick。
+// strip 为资源中的多帧贴图名，由 tools/assets 生成
+type spriteAnimation struct {
+	strip         string
+	frames        int
+	ticksPerFrame int
+	loop          bool // 不循环的动画停在最后一帧
+}
+
+// 按静态贴图名索引的动画，关闭“动画”时使用静态贴图
+var spriteAnimations = map[string]spriteAnimation{
+	"mine":     {"fuse", 4, 6, true},
+	"flag":     {"flagwave", 4, 10, true},
+	"exploded": {"explosion", 6, 5, false},
+}
+
+// 多帧贴图中第 f 帧的贴图名
+func frameName(strip string, f int) string {
+	return strip + "." + strconv.Itoa(f)
+}
+
+// 播放 ticks 个 tick 后的帧号
+func (a spriteAnimation) frame(ticks int) int {
+	f := ticks / a.ticksPerFrame
+	if a.loop {
+		return f % a.frames
+	}
+	if f >= a.frames {
+		return a.frames - 1
+	}
+	return f
+}
+
+// 格子上多帧贴图当前的帧号加一，0 表示使用静态贴图
+func (g *Game) cellFrame(x, y int) int {
+	if !globalConfig.Animations {
+		return 0
+	}
+	cell := g.shownCell(x, y)
+	var name string
+	ticks := g.ticks
+	switch {
+	case cell.revealed && cell.hasMine && g.gameOver && x == g.explodedX && y == g.explodedY:
+		name, ticks = "exploded", g.ticks-g.lostTick
+	case cell.revealed && cell.hasMine:
+		name = "mine"
+	case !cell.revealed && cell.flagged && !(g.gameOver && !cell.hasMine):
+		// 相邻的旗帜错开相位，整片旗帜像波浪一样起伏
+		name, ticks = "flag", g.ticks+(x+y)*3
+	default:
+		return 0
+	}
+	return spriteAnimations[name].frame(ticks) + 1
+}
+
+// 按帧号选择贴图，frame 为 0 或没有动画时返回静态贴图名
+func animatedSprite(name string, frame int) string {
+	a, ok := spriteAnimations[name]
+	if !ok || frame == 0 {
+		return name
+	}
+	return frameName(a.strip, frame-1)
+}
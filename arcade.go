@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+// 街机模式：从小棋盘开始，每过一关棋盘变大、地雷变密，倍率随之增加，
+// 踩雷即结束本轮，总分记入街机排行榜。所有关卡共用一个排行榜
+const (
+	arcadeMaxEntries     = 10
+	arcadeStartSize      = 8
+	arcadeMaxWidth       = 30
+	arcadeMaxHeight      = 20
+	arcadeStartDensity   = 0.12
+	arcadeMaxDensity     = 0.22
+	arcadeMultiplierStep = 0.5 // 每过一关倍率增加的量
+)
+
+// 第 level 关（从 0 开始）的棋盘：宽度每关加一，高度每两关加一，密度每关增加一个百分点
+func arcadeLevel(level int) DifficultyConfig {
+	w := clamp(arcadeStartSize+level, arcadeStartSize, arcadeMaxWidth)
+	h := clamp(arcadeStartSize+level/2, arcadeStartSize, arcadeMaxHeight)
+	density := math.Min(arcadeStartDensity+0.01*float64(level), arcadeMaxDensity)
+	return DifficultyConfig{w, h, int(math.Round(float64(w*h) * density))}
+}
+
+// 进行中的一轮，换局时保留
+type arcadeRun struct {
+	level      int
+	score      int
+	multiplier float64
+	over       bool
+	lastPoints int // 上一关得到的分数，在结果界面显示
+}
+
+var arcade *arcadeRun
+
+// 本关通过时得到的分数：3BV 越高、倍率越大分数越高
+func (r *arcadeRun) points(bbbv int) int {
+	return int(float64(bbbv*10) * r.multiplier)
+}
+
+func (g *Game) startArcade() error {
+	arcade = &arcadeRun{multiplier: 1}
+	return g.beginArcadeLevel()
+}
+
+func (g *Game) beginArcadeLevel() error {
+	if err := g.newVariantRoundSized(Custom, arcadeLevel(arcade.level), board.Square8{}, ""); err != nil {
+		return err
+	}
+	g.arcade = arcade
+	showToast(fmt.Sprintf("%s %d", tr("关卡"), arcade.level+1))
+	return nil
+}
+
+// 结果界面的“重启”：通关后进入下一关，失败后开始新的一轮
+func (g *Game) continueArcade() error {
+	if g.arcade.over {
+		return g.startArcade()
+	}
+	if !g.won {
+		// 关卡进行中重新开始视为放弃本轮，不计入排行榜
+		return g.startArcade()
+	}
+	return g.beginArcadeLevel()
+}
+
+// 一轮的最终成绩
+type arcadeEntry struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Level int       `json:"level"` // 通过的关数
+	Date  time.Time `json:"date"`
+}
+
+type ArcadeScores struct {
+	Entries []arcadeEntry `json:"entries"`
+
+	name  string
+	store storage.Storage
+}
+
+var globalArcade = &ArcadeScores{}
+
+func loadArcadeScores(store storage.Storage) (*ArcadeScores, error) {
+	s := &ArcadeScores{name: "arcade.json", store: store}
+	err := loadFile(s.store, s.name, "arcade", s)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("读取街机排行榜失败: %v", err)
+	}
+	return s, nil
+}
+
+func (s *ArcadeScores) save() error {
+	if s.name == "" {
+		return nil
+	}
+	return saveFile(s.store, s.name, "arcade", s)
+}
+
+// 加入排行榜，返回名次（从 0 开始），没有进入前几名时返回 -1
+func (s *ArcadeScores) add(e arcadeEntry) int {
+	s.Entries = append(s.Entries, e)
+	sort.SliceStable(s.Entries, func(i, j int) bool { return s.Entries[i].Score > s.Entries[j].Score })
+	if len(s.Entries) > arcadeMaxEntries {
+		s.Entries = s.Entries[:arcadeMaxEntries]
+	}
+	for i := range s.Entries {
+		if s.Entries[i] == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// 通关时累计分数并提高倍率，踩雷时结束本轮并记入排行榜
+func (s *ArcadeScores) subscribe(bus *EventBus) {
+	bus.Subscribe(EventGameWon, func(e Event) {
+		if e.Variant != "arcade" || arcade == nil {
+			return
+		}
+		arcade.lastPoints = arcade.points(e.BBBV)
+		arcade.score += arcade.lastPoints
+		arcade.level++
+		arcade.multiplier += arcadeMultiplierStep
+	})
+	bus.Subscribe(EventGameLost, func(e Event) {
+		if e.Variant != "arcade" || arcade == nil || arcade.over {
+			return
+		}
+		arcade.over = true
+		rank := s.add(arcadeEntry{Name: playerName(), Score: arcade.score, Level: arcade.level, Date: time.Now()})
+		if err := s.save(); err != nil {
+			slog.Warn("保存街机排行榜失败", "err", err)
+		}
+		if rank == 0 && arcade.score > 0 {
+			showToast(tr("街机新纪录"))
+		}
+	})
+}
+
+// 状态栏中的关卡和分数
+func (g *Game) arcadeStatus() string {
+	return fmt.Sprintf("%s %d · %d ×%.1f", tr("关卡"), g.arcade.level+1, g.arcade.score, g.arcade.multiplier)
+}
+
+// 结果界面的第一行
+func (g *Game) arcadeResult() string {
+	if g.arcade.over {
+		return fmt.Sprintf("%s: %d", tr("总分"), g.arcade.score)
+	}
+	return fmt.Sprintf("+%d  %s: %d", g.arcade.lastPoints, tr("总分"), g.arcade.score)
+}
+
+func (g *Game) arcadeButtons() []*menuButton {
+	half := (g.screenWidth() - 30) / 2
+	back := g.backButton()
+	back.X, back.Y, back.W, back.H = 20+half, g.screenHeight()-44, half, 34
+	return []*menuButton{
+		{Button: &Button{X: 10, Y: g.screenHeight() - 44, W: half, H: 34, Text: "开始"}, action: g.startArcade},
+		back,
+	}
+}
+
+func (g *Game) drawArcadeScores(screen *ebiten.Image) {
+	if len(globalArcade.Entries) == 0 {
+		g.drawCentered(screen, tr("还没有记录"), 90)
+		return
+	}
+	for i, e := range globalArcade.Entries {
+		y := 80 + i*22
+		line := fmt.Sprintf("%2d. %s", i+1, e.Name)
+		text.Draw(screen, line, g.gameFont, 16, y, activeTheme.Text)
+		detail := fmt.Sprintf("%d  (%s %d)", e.Score, tr("关卡"), e.Level)
+		text.Draw(screen, detail, g.gameFont, g.screenWidth()-130, y, activeTheme.Text)
+	}
+}
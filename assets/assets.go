@@ -1,18 +1,54 @@
-package assets
-
-import (
-	"embed"
-)
-
-//go:embed images/* sounds/*
-var Files embed.FS
-
-// GetImage 获取图片数据
-func GetImage(name string) ([]byte, error) {
-	return Files.ReadFile("images/" + name)
-}
-
-// GetSound 获取音效数据
-func GetSound(name string) ([]byte, error) {
-	return Files.ReadFile("sounds/" + name)
-}
+package assets
+
+import (
+	"embed"
+)
+
+//go:embed images/* sounds/* puzzles/* changelog.json
+var Files embed.FS
+
+// GetImage 获取图片数据
+func GetImage(name string) ([]byte, error) {
+	return Files.ReadFile("images/" + name)
+}
+
+// ListImages 列出所有图片文件名
+func ListImages() ([]string, error) {
+	entries, err := Files.ReadDir("images")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+// GetSound 获取音效数据
+func GetSound(name string) ([]byte, error) {
+	return Files.ReadFile("sounds/" + name)
+}
+
+// ListPuzzlePacks 列出内置的谜题包，按文件名排序
+func ListPuzzlePacks() ([]string, error) {
+	entries, err := Files.ReadDir("puzzles")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+// GetPuzzlePack 获取谜题包数据
+func GetPuzzlePack(name string) ([]byte, error) {
+	return Files.ReadFile("puzzles/" + name)
+}
+
+// GetChangelog 获取更新日志，最新版本在前
+func GetChangelog() ([]byte, error) {
+	return Files.ReadFile("changelog.json")
+}
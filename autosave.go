@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+const (
+	checkpointName     = "autosave.json"
+	checkpointInterval = 2 * time.Second // 两次自动保存的最短间隔
+)
+
+// 进行中对局的自动存档。对局正常结束或退出时删除，
+// 启动时仍然存在说明上次异常退出，可以恢复。棋盘按 board.State 的格式保存，其余是对局的设置
+type checkpoint struct {
+	board.State
+	Difficulty string           `json:"difficulty"`
+	StartX     int              `json:"start_x"`
+	StartY     int              `json:"start_y"`
+	Moves      []checkpointMove `json:"moves"`
+	Challenge  *challenge       `json:"challenge,omitempty"`
+	Shape      string           `json:"shape,omitempty"`
+	Puzzle     *puzzle          `json:"puzzle,omitempty"`
+	Moved      []relocation     `json:"moved,omitempty"` // 轻松模式中移走的地雷，地雷层中已经是移动后的位置
+	Progress   []time.Duration  `json:"progress,omitempty"`
+	Retry      bool             `json:"retry,omitempty"` // 重试的棋盘，恢复后仍单独记录
+	Assisted   bool             `json:"assisted,omitempty"`
+	SafeCell   bool             `json:"safe_cell,omitempty"`
+	NoFlags    bool             `json:"no_flags,omitempty"`
+	Countdown  bool             `json:"countdown,omitempty"`
+	Lives      int              `json:"lives,omitempty"` // 开局时的生命数，已用掉的见 Moved
+	SavedAt    time.Time        `json:"saved_at"`
+}
+
+type checkpointMove struct {
+	Kind moveKind      `json:"kind"`
+	X    int           `json:"x"`
+	Y    int           `json:"y"`
+	At   time.Duration `json:"at"`
+}
+
+// 上次自动保存的时间和步数，全局保存以便重启后依然有效
+var autosave struct {
+	at    time.Time
+	moves int
+}
+
+func (cp *checkpoint) difficulty() Difficulty {
+	d, _ := difficultyByKey(cp.Difficulty)
+	return d
+}
+
+func (g *Game) checkpoint() *checkpoint {
+	cp := &checkpoint{
+		Difficulty: difficultyKeys[g.difficulty],
+		StartX:     g.startX,
+		StartY:     g.startY,
+		Challenge:  g.challenge,
+		Shape:      g.shapeName(),
+		Puzzle:     g.puzzle,
+		Moved:      g.relocations,
+		SavedAt:    time.Now(),
+	}
+	if s := g.boardState(); s != nil {
+		cp.State = *s
+	}
+	for _, m := range g.moves {
+		cp.Moves = append(cp.Moves, checkpointMove{Kind: m.kind, X: m.x, Y: m.y, At: m.at})
+	}
+	cp.Progress = g.splits
+	cp.Retry = g.retry
+	cp.Assisted = g.assisted
+	cp.SafeCell = g.safeCell
+	cp.NoFlags = g.noFlags
+	cp.Countdown = g.countdown
+	cp.Lives = g.lives
+	return cp
+}
+
+// 对局有新操作时自动保存，联机对局无法恢复网络状态，不保存
+func (g *Game) updateAutosave() {
+	if !g.inProgress() || g.race != nil || g.coop != nil {
+		return
+	}
+	if len(g.moves) == autosave.moves || time.Since(autosave.at) < checkpointInterval {
+		return
+	}
+	autosave.at, autosave.moves = time.Now(), len(g.moves)
+	if err := saveFile(g.store, checkpointName, "checkpoint", g.checkpoint()); err != nil {
+		slog.Warn("自动保存失败", "err", err)
+	}
+}
+
+func (g *Game) clearCheckpoint() {
+	autosave.moves = 0
+	for _, name := range []string{checkpointName, checkpointName + ".bak"} {
+		if err := g.store.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("删除自动存档失败", "err", err)
+		}
+	}
+}
+
+// 对局结束后不再需要存档
+func (g *Game) subscribeAutosave(bus *EventBus) {
+	handler := func(Event) { g.clearCheckpoint() }
+	bus.Subscribe(EventGameWon, handler)
+	bus.Subscribe(EventGameLost, handler)
+}
+
+func loadCheckpoint(store storage.Storage) (*checkpoint, error) {
+	cp := &checkpoint{}
+	if err := loadFile(store, checkpointName, "checkpoint", cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// 启动时检查是否有异常退出留下的存档，有则询问是否恢复
+func (g *Game) checkRestore() {
+	cp, err := loadCheckpoint(g.store)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("读取自动存档失败", "err", err)
+			g.clearCheckpoint()
+		}
+		return
+	}
+	g.restore = cp
+	if g.scene == SceneMainMenu {
+		g.switchScene(SceneRestore)
+	}
+}
+
+// 按存档重建对局：按地雷层布雷，再还原每个格子的状态和计时。已经结束的对局不能恢复
+func (g *Game) restoreCheckpoint(cp *checkpoint) error {
+	if err := cp.State.Validate(); err != nil {
+		return err
+	}
+	if cp.Puzzle != nil {
+		if err := g.restorePuzzle(cp); err != nil {
+			return err
+		}
+	} else if err := g.restoreLayout(cp); err != nil {
+		return err
+	}
+	g.challenge = cp.Challenge
+	// 按存档时的规则继续，不受之后修改的设置影响
+	g.noFlags = cp.NoFlags
+	g.assisted = cp.Assisted
+	g.countdown = cp.Countdown
+	g.lives = cp.Lives
+	g.applyCellRows(cp.Cells)
+	for _, m := range cp.Moves {
+		g.moves = append(g.moves, move{kind: m.Kind, x: m.X, y: m.Y, at: m.At})
+	}
+
+	g.splits = cp.Progress
+	g.firstClick = false
+	elapsed := time.Duration(cp.ElapsedMS) * time.Millisecond
+	g.elapsedTime = elapsed
+	g.startTime = time.Now().Add(-elapsed)
+	// 开局时删除了存档，立即重新保存
+	autosave.moves = -1
+	return nil
+}
+
+// 按地雷层布雷，轻松模式中移走的地雷已经在新的位置
+func (g *Game) restoreLayout(cp *checkpoint) error {
+	d, ok := difficultyByKey(cp.Difficulty)
+	if !ok {
+		return fmt.Errorf("未知难度: %s", cp.Difficulty)
+	}
+	if size := g.sizeFor(d); cp.Width != size.GridWidth || cp.Height != size.GridHeight {
+		return fmt.Errorf("存档尺寸不匹配: %dx%d", cp.Width, cp.Height)
+	}
+	if err := g.newRound(d); err != nil {
+		return err
+	}
+
+	g.seed = cp.Seed
+	if cp.Topology != "" {
+		t, ok := board.TopologyByName(cp.Topology)
+		if !ok {
+			return fmt.Errorf("未知棋盘类型: %s", cp.Topology)
+		}
+		g.topology = t
+	}
+	if cp.Shape != "" {
+		mask, err := g.loadShape(cp.Shape)
+		if err != nil {
+			return err
+		}
+		g.shape = mask
+	}
+	g.safeCell = cp.SafeCell
+	mines := cp.MineGrid()
+	n := 0
+	for _, row := range mines {
+		for _, mine := range row {
+			if mine {
+				n++
+			}
+		}
+	}
+	if n != g.mineCount() {
+		return fmt.Errorf("存档的地雷数不匹配: %d", n)
+	}
+	g.setMines(mines, cp.StartX, cp.StartY)
+	g.relocations = cp.Moved
+	g.retry = cp.Retry
+	return nil
+}
+
+// 谜题的地雷保存在存档中，起点是否翻开也以存档为准
+func (g *Game) restorePuzzle(cp *checkpoint) error {
+	if err := cp.Puzzle.validate(); err != nil {
+		return err
+	}
+	if w, h := cp.Puzzle.size(); cp.Width != w || cp.Height != h {
+		return fmt.Errorf("存档尺寸不匹配: %dx%d", cp.Width, cp.Height)
+	}
+	if err := g.beginPuzzle(cp.Puzzle); err != nil {
+		return err
+	}
+	for y := range g.grid {
+		for x := range g.grid[y] {
+			g.grid[y][x].revealed = false
+		}
+	}
+	g.revealedSafe = 0
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import "fmt"
+
+// 计算棋盘的 3BV（通关所需的最少点击数）及已完成的部分：
+// 每个空白连通区域（连同边缘数字）算 1，不与空白格相邻的数字格各算 1
+func (g *Game) compute3BV() (total, solved int) {
+	height, width := len(g.grid), len(g.grid[0])
+	visited := make([][]bool, height)
+	for y := range visited {
+		visited[y] = make([]bool, width)
+	}
+
+	// 先统计空白区域
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := g.grid[y][x]
+			if visited[y][x] || cell.hasMine || cell.neighbors != 0 || !g.exists(x, y) {
+				continue
+			}
+			total++
+			if g.markOpening(visited, x, y) {
+				solved++
+			}
+		}
+	}
+
+	// 再统计孤立的数字格
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := g.grid[y][x]
+			if visited[y][x] || cell.hasMine || !g.exists(x, y) {
+				continue
+			}
+			total++
+			if cell.revealed {
+				solved++
+			}
+		}
+	}
+	return total, solved
+}
+
+// 标记从 (x, y) 开始的空白区域及其边缘数字，返回区域是否已被翻开
+func (g *Game) markOpening(visited [][]bool, x, y int) bool {
+	opened := false
+	stack := [][2]int{{x, y}}
+	visited[y][x] = true
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		cell := g.grid[p[1]][p[0]]
+		if cell.revealed {
+			opened = true
+		}
+		if cell.neighbors != 0 {
+			continue
+		}
+		for _, n := range g.adjacency().Neighbors(nil, len(g.grid[0]), len(g.grid), p[0], p[1]) {
+			if !visited[n[1]][n[0]] {
+				visited[n[1]][n[0]] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return opened
+}
+
+// 结果界面显示的 3BV、3BV/s 和点击效率
+func (g *Game) efficiencyLines() []string {
+	total, solved := g.compute3BV()
+	lines := []string{fmt.Sprintf("3BV: %d/%d", solved, total)}
+
+	if seconds := g.elapsedTime.Seconds(); seconds > 0 {
+		lines = append(lines, fmt.Sprintf("3BV/s: %.2f", float64(solved)/seconds))
+	}
+	if clicks := len(g.moves); clicks > 0 {
+		lines = append(lines, fmt.Sprintf("%s: %d%%", tr("效率"), solved*100/clicks))
+	}
+	if g.boardHash != "" {
+		lines = append(lines, tr("布局")+": "+g.boardHash)
+	}
+	return lines
+}
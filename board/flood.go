@@ -0,0 +1,25 @@
+// Package board 提供与界面无关的棋盘算法
+package board
+
+// FloodFill 在方格棋盘上从 (x, y) 开始连锁翻开格子，见 FloodFillOn
+func FloodFill(width, height, x, y int, open func(x, y int) bool) {
+	FloodFillOn(Square8{}, width, height, x, y, open)
+}
+
+// FloodFillOn 从 (x, y) 开始按拓扑 t 连锁翻开格子，使用显式栈，超大棋盘也不会栈溢出。
+// open 翻开一个格子，返回 true 表示该格子是空白格，需要继续翻开相邻的格子；
+// 已翻开或不应翻开的格子应返回 false。越界的坐标不会传给 open。
+func FloodFillOn(t Topology, width, height, x, y int, open func(x, y int) bool) {
+	if x < 0 || x >= width || y < 0 || y >= height {
+		return
+	}
+	stack := [][2]int{{x, y}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !open(p[0], p[1]) {
+			continue
+		}
+		stack = t.Neighbors(stack, width, height, p[0], p[1])
+	}
+}
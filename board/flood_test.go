@@ -0,0 +1,283 @@
+package board
+
+import "testing"
+
+// 测试用的简单棋盘
+type testBoard struct {
+	w, h      int
+	mines     [][]bool
+	flagged   [][]bool
+	revealed  [][]bool
+	openCalls int
+}
+
+func newTestBoard(w, h int) *testBoard {
+	b := &testBoard{w: w, h: h}
+	b.mines = grid(w, h)
+	b.flagged = grid(w, h)
+	b.revealed = grid(w, h)
+	return b
+}
+
+func grid(w, h int) [][]bool {
+	g := make([][]bool, h)
+	for y := range g {
+		g[y] = make([]bool, w)
+	}
+	return g
+}
+
+func (b *testBoard) neighbors(x, y int) int {
+	n := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nx, ny := x+dx, y+dy
+			if nx >= 0 && nx < b.w && ny >= 0 && ny < b.h && b.mines[ny][nx] {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func (b *testBoard) open(x, y int) bool {
+	b.openCalls++
+	if b.revealed[y][x] || b.flagged[y][x] || b.mines[y][x] {
+		return false
+	}
+	b.revealed[y][x] = true
+	return b.neighbors(x, y) == 0
+}
+
+func (b *testBoard) countRevealed() int {
+	n := 0
+	for _, row := range b.revealed {
+		for _, r := range row {
+			if r {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestFloodFillEmptyBoard(t *testing.T) {
+	b := newTestBoard(200, 200)
+	FloodFill(b.w, b.h, 0, 0, b.open)
+	if got := b.countRevealed(); got != 200*200 {
+		t.Fatalf("翻开 %d 个格子，期望 %d", got, 200*200)
+	}
+	// 每个格子最多被相邻的 8 个格子各压栈一次
+	if b.openCalls > 9*200*200 {
+		t.Errorf("open 调用次数过多: %d", b.openCalls)
+	}
+}
+
+func TestFloodFillFromCenter(t *testing.T) {
+	b := newTestBoard(200, 200)
+	b.mines[199][199] = true
+	FloodFill(b.w, b.h, 100, 100, b.open)
+	if got, want := b.countRevealed(), 200*200-1; got != want {
+		t.Fatalf("翻开 %d 个格子，期望 %d", got, want)
+	}
+	if b.revealed[199][199] {
+		t.Error("地雷被翻开")
+	}
+}
+
+func TestFloodFillStopsAtWall(t *testing.T) {
+	// 第 100 列是一整列地雷，左侧连锁翻开时不应越过
+	b := newTestBoard(200, 200)
+	for y := 0; y < b.h; y++ {
+		b.mines[y][100] = true
+	}
+	FloodFill(b.w, b.h, 0, 0, b.open)
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			want := x < 100
+			if b.revealed[y][x] != want {
+				t.Fatalf("(%d, %d) 翻开状态为 %v，期望 %v", x, y, b.revealed[y][x], want)
+			}
+		}
+	}
+}
+
+func TestFloodFillSkipsFlags(t *testing.T) {
+	b := newTestBoard(200, 200)
+	b.flagged[50][50] = true
+	FloodFill(b.w, b.h, 199, 199, b.open)
+	if b.revealed[50][50] {
+		t.Error("插旗的格子被翻开")
+	}
+	if got, want := b.countRevealed(), 200*200-1; got != want {
+		t.Fatalf("翻开 %d 个格子，期望 %d", got, want)
+	}
+}
+
+func TestFloodFillNumberedStart(t *testing.T) {
+	b := newTestBoard(200, 200)
+	b.mines[0][1] = true
+	FloodFill(b.w, b.h, 0, 0, b.open)
+	if got := b.countRevealed(); got != 1 {
+		t.Fatalf("从数字格开始翻开了 %d 个格子，期望 1", got)
+	}
+}
+
+func TestFloodFillOutOfBounds(t *testing.T) {
+	b := newTestBoard(10, 10)
+	FloodFill(b.w, b.h, -1, 5, b.open)
+	FloodFill(b.w, b.h, 5, 10, b.open)
+	if b.openCalls != 0 {
+		t.Errorf("越界坐标调用了 open %d 次", b.openCalls)
+	}
+}
+
+// 100x100、1000 个地雷的棋盘上从安全区连锁翻开
+func BenchmarkFloodFill100x100(b *testing.B) {
+	benchmarkFloodFill(b, 100, 100, 1000)
+}
+
+// 没有地雷的 1000x1000 棋盘，一次翻开全部一百万个格子
+func BenchmarkFloodFill1000x1000Empty(b *testing.B) {
+	benchmarkFloodFill(b, 1000, 1000, 0)
+}
+
+func benchmarkFloodFill(b *testing.B, w, h, mines int) {
+	layout := PlaceMines(w, h, mines, 1, w/2, h/2)
+	counts := CountNeighbors(layout)
+	revealed := grid(w, h)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range revealed {
+			for x := range row {
+				row[x] = false
+			}
+		}
+		FloodFill(w, h, w/2, h/2, func(x, y int) bool {
+			if revealed[y][x] || layout[y][x] {
+				return false
+			}
+			revealed[y][x] = true
+			return counts[y][x] == 0
+		})
+	}
+}
+
+func TestFloodFillTorusWraps(t *testing.T) {
+	// 第 5 列是一整列地雷，普通棋盘上从左侧开始只能翻开左半边，环面上可以绕到右侧
+	b := newTestBoard(10, 10)
+	for y := 0; y < b.h; y++ {
+		b.mines[y][5] = true
+	}
+	counts := CountNeighborsOn(Torus{}, b.mines)
+	FloodFillOn(Torus{}, b.w, b.h, 0, 0, func(x, y int) bool {
+		if b.revealed[y][x] || b.mines[y][x] {
+			return false
+		}
+		b.revealed[y][x] = true
+		return counts[y][x] == 0
+	})
+	if !b.revealed[0][7] {
+		t.Error("没有越过边缘翻开右侧的格子")
+	}
+	if got, want := b.countRevealed(), 90; got != want {
+		t.Fatalf("翻开 %d 个格子，期望 %d", got, want)
+	}
+}
+
+func TestFloodFillFixtures(t *testing.T) {
+	cases := []struct {
+		name   string
+		mines  []string
+		x, y   int
+		opened []string // o 为应当翻开的格子
+	}{
+		{
+			"被地雷围住的空白区",
+			[]string{".....", ".***.", ".*.*.", ".***.", "....."},
+			2, 2,
+			[]string{"#####", "#####", "##o##", "#####", "#####"},
+		},
+		{
+			"外圈连锁展开到数字为止",
+			[]string{".......", ".......", "..***..", "..*.*..", "..***..", ".......", "......."},
+			0, 0,
+			[]string{"ooooooo", "ooooooo", "oo###oo", "oo###oo", "oo###oo", "ooooooo", "ooooooo"},
+		},
+		{
+			"对角缝隙也会展开",
+			[]string{"..*..", "..*..", "**...", ".....", "....."},
+			4, 4,
+			[]string{"###oo", "###oo", "##ooo", "ooooo", "ooooo"},
+		},
+		{
+			"点在数字上只翻开一格",
+			[]string{"*....", ".....", ".....", ".....", "....."},
+			1, 1,
+			[]string{"#####", "#o###", "#####", "#####", "#####"},
+		},
+	}
+	for _, c := range cases {
+		b := newTestBoard(len(c.mines[0]), len(c.mines))
+		b.mines = parseMines(c.mines...)
+		FloodFill(b.w, b.h, c.x, c.y, b.open)
+		for y, row := range c.opened {
+			for x := range row {
+				if want := row[x] == 'o'; b.revealed[y][x] != want {
+					t.Errorf("%s: (%d, %d) 翻开状态为 %v，期望 %v", c.name, x, y, b.revealed[y][x], want)
+				}
+			}
+		}
+	}
+}
+
+// 在随机棋盘上从首次点击开始连锁翻开，再逐个翻开剩下的安全格子，检查每一步的不变量
+func FuzzReveal(f *testing.F) {
+	f.Add(int64(1), uint8(9), uint8(9), uint16(10), uint8(4), uint8(4))
+	f.Add(int64(7), uint8(30), uint8(16), uint16(99), uint8(0), uint8(0))
+	f.Add(int64(3), uint8(1), uint8(20), uint16(15), uint8(0), uint8(19))
+	f.Fuzz(func(t *testing.T, seed int64, w, h uint8, n uint16, sx, sy uint8) {
+		var sq Square8
+		width, height := 1+int(w)%40, 1+int(h)%40
+		x, y := int(sx)%width, int(sy)%height
+		safeArea := 1 + len(sq.Neighbors(nil, width, height, x, y))
+		count := int(n) % (width*height - safeArea + 1)
+
+		b := newTestBoard(width, height)
+		b.mines = PlaceMines(width, height, count, seed, x, y)
+		counts := CountNeighbors(b.mines)
+		FloodFill(width, height, x, y, b.open)
+
+		if !b.revealed[y][x] || counts[y][x] != 0 {
+			t.Fatalf("首次点击 (%d, %d) 没有翻开空白格子", x, y)
+		}
+		for cy := 0; cy < height; cy++ {
+			for cx := 0; cx < width; cx++ {
+				if !b.revealed[cy][cx] {
+					continue
+				}
+				if b.mines[cy][cx] {
+					t.Fatalf("地雷 (%d, %d) 被翻开", cx, cy)
+				}
+				// 翻开的空白格子周围必须全部翻开
+				for _, p := range sq.Neighbors(nil, width, height, cx, cy) {
+					if counts[cy][cx] == 0 && !b.revealed[p[1]][p[0]] {
+						t.Fatalf("空白格子 (%d, %d) 的相邻格子 (%d, %d) 没有翻开", cx, cy, p[0], p[1])
+					}
+				}
+			}
+		}
+
+		safe := width*height - count
+		for cy := 0; cy < height; cy++ {
+			for cx := 0; cx < width; cx++ {
+				if !b.mines[cy][cx] {
+					FloodFill(width, height, cx, cy, b.open)
+				}
+			}
+		}
+		if got := b.countRevealed(); !Won(safe, got, count, 0, false) {
+			t.Fatalf("翻开 %d 个安全格子后没有获胜，共 %d 个", got, safe)
+		}
+	})
+}
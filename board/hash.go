@@ -0,0 +1,37 @@
+package board
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// HashLength 是 Hash 返回的十六进制字符数
+const HashLength = 16
+
+// Hash 返回地雷布局的规范哈希：只取决于棋盘尺寸和每个格子是否有地雷，
+// 与生成布局的种子和起始位置无关，不同种子得到相同布局时哈希相同
+func Hash(mines [][]bool) string {
+	h := sha256.New()
+	var size [8]byte
+	height, width := len(mines), 0
+	if height > 0 {
+		width = len(mines[0])
+	}
+	binary.BigEndian.PutUint32(size[:4], uint32(width))
+	binary.BigEndian.PutUint32(size[4:], uint32(height))
+	h.Write(size[:])
+
+	// 按行优先逐位打包
+	bits := make([]byte, (width*height+7)/8)
+	for y, row := range mines {
+		for x, m := range row {
+			if m {
+				i := y*width + x
+				bits[i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+	h.Write(bits)
+	return hex.EncodeToString(h.Sum(nil))[:HashLength]
+}
@@ -0,0 +1,111 @@
+package board
+
+import "math"
+
+// Mask 是不规则形状棋盘的轮廓：棋盘仍是 Width×Height 的矩形数组，
+// 只有轮廓内的格子存在，其余格子不放地雷、不参与计数也不能翻开
+type Mask struct {
+	Name          string
+	Width, Height int
+	cells         []bool
+}
+
+// NewMask 按 inside(x, y) 生成轮廓
+func NewMask(name string, width, height int, inside func(x, y int) bool) *Mask {
+	m := &Mask{Name: name, Width: width, Height: height, cells: make([]bool, width*height)}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			m.cells[y*width+x] = inside(x, y)
+		}
+	}
+	return m
+}
+
+// Contains 判断 (x, y) 是否在轮廓内，越界时为 false
+func (m *Mask) Contains(x, y int) bool {
+	return x >= 0 && x < m.Width && y >= 0 && y < m.Height && m.cells[y*m.Width+x]
+}
+
+// Count 返回轮廓内的格子数
+func (m *Mask) Count() int {
+	n := 0
+	for _, c := range m.cells {
+		if c {
+			n++
+		}
+	}
+	return n
+}
+
+// Shapes 是内置形状的名称
+var Shapes = []string{"heart", "diamond", "ring"}
+
+// ShapeMask 按名称生成 width×height 的内置形状，形状按格子中心在 [-1, 1] 范围内的坐标计算
+func ShapeMask(name string, width, height int) (*Mask, bool) {
+	var inside func(u, v float64) bool
+	switch name {
+	case "heart":
+		inside = func(u, v float64) bool {
+			x, y := u*1.2, v*1.25+0.15
+			a := x*x + y*y - 1
+			return a*a*a-x*x*y*y*y <= 0
+		}
+	case "diamond":
+		inside = func(u, v float64) bool {
+			return math.Abs(u)+math.Abs(v) <= 1.05
+		}
+	case "ring":
+		inside = func(u, v float64) bool {
+			d := u*u + v*v
+			return d <= 1 && d >= 0.2
+		}
+	default:
+		return nil, false
+	}
+	return NewMask(name, width, height, func(x, y int) bool {
+		u := (float64(x)+0.5)/float64(width)*2 - 1
+		v := 1 - (float64(y)+0.5)/float64(height)*2
+		return inside(u, v)
+	}), true
+}
+
+// Shaped 在 Base 拓扑上去掉轮廓以外的格子，轮廓外的格子没有相邻格子，也不是任何格子的相邻格子
+type Shaped struct {
+	Base Topology
+	Mask *Mask
+}
+
+func (s Shaped) Name() string { return s.Base.Name() }
+
+func (s Shaped) Neighbors(buf [][2]int, width, height, x, y int) [][2]int {
+	if !s.Mask.Contains(x, y) {
+		return buf
+	}
+	n := len(buf)
+	buf = s.Base.Neighbors(buf, width, height, x, y)
+	kept := buf[:n]
+	for _, p := range buf[n:] {
+		if s.Mask.Contains(p[0], p[1]) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// Exists 判断 (x, y) 在拓扑 t 中是否是棋盘上的格子，
+// 六边形棋盘角落的格子和轮廓以外的格子都不存在
+func Exists(t Topology, width, x, y int) bool {
+	switch t := t.(type) {
+	case Shaped:
+		return t.Mask.Contains(x, y) && Exists(t.Base, width, x, y)
+	case Hex:
+		return HexContains(width, x, y)
+	}
+	return true
+}
+
+// PlaceMinesShaped 与 PlaceMinesOn 相同，但只在轮廓内放置地雷，
+// 安全区为 (safeX, safeY) 及其在 Shaped{t, mask} 中的相邻格子
+func PlaceMinesShaped(t Topology, mask *Mask, count int, seed int64, safeX, safeY int) [][]bool {
+	return placeMines(Shaped{t, mask}, mask, mask.Width, mask.Height, count, seed, safeX, safeY)
+}
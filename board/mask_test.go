@@ -0,0 +1,70 @@
+package board
+
+import "testing"
+
+func TestShapeMasks(t *testing.T) {
+	for _, name := range Shapes {
+		m, ok := ShapeMask(name, 16, 16)
+		if !ok {
+			t.Fatalf("找不到内置形状 %s", name)
+		}
+		if n := m.Count(); n < 16*16/3 || n >= 16*16 {
+			t.Errorf("%s 有 %d 个格子", name, n)
+		}
+	}
+	if _, ok := ShapeMask("triangle", 16, 16); ok {
+		t.Error("找到了不存在的形状")
+	}
+}
+
+func TestPlaceMinesShaped(t *testing.T) {
+	mask, _ := ShapeMask("heart", 16, 16)
+	mines := PlaceMinesShaped(Square8{}, mask, 40, 5, 8, 8)
+	n := 0
+	for y, row := range mines {
+		for x, m := range row {
+			if !m {
+				continue
+			}
+			n++
+			if !mask.Contains(x, y) {
+				t.Errorf("轮廓外的 (%d, %d) 放置了地雷", x, y)
+			}
+		}
+	}
+	if n != 40 {
+		t.Fatalf("放置了 %d 个地雷，期望 40", n)
+	}
+}
+
+func TestShapedNeighbors(t *testing.T) {
+	// 只有左上角 2×2 的格子存在
+	mask := NewMask("corner", 4, 4, func(x, y int) bool { return x < 2 && y < 2 })
+	s := Shaped{Square8{}, mask}
+	if got := len(s.Neighbors(nil, 4, 4, 1, 1)); got != 3 {
+		t.Errorf("(1, 1) 有 %d 个相邻格子，期望 3", got)
+	}
+	if got := len(s.Neighbors(nil, 4, 4, 2, 2)); got != 0 {
+		t.Errorf("轮廓外的 (2, 2) 有 %d 个相邻格子，期望 0", got)
+	}
+}
+
+func TestExists(t *testing.T) {
+	mask := NewMask("corner", 4, 4, func(x, y int) bool { return x < 2 && y < 2 })
+	cases := []struct {
+		t    Topology
+		x, y int
+		want bool
+	}{
+		{Square8{}, 3, 3, true},
+		{Shaped{Square8{}, mask}, 1, 1, true},
+		{Shaped{Square8{}, mask}, 2, 1, false},
+		{Hex{}, 0, 0, false}, // 5×5 六边形棋盘的左上角不存在
+		{Hex{}, 2, 0, true},
+	}
+	for _, c := range cases {
+		if got := Exists(c.t, 5, c.x, c.y); got != c.want {
+			t.Errorf("Exists(%s, %d, %d) = %v，期望 %v", c.t.Name(), c.x, c.y, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package board
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MBF 是其他扫雷工具通用的二进制棋盘格式：宽、高各一个字节，
+// 地雷数为两个字节的大端整数，随后每颗地雷的 x、y 各一个字节。
+// 只记录经典矩形棋盘的地雷位置，不包含翻开状态
+const (
+	mbfHeader  = 4
+	MBFMaxSize = 255
+)
+
+// 解析和编码共用的限制：至少一颗地雷，至少一个安全格子
+func checkMBF(w, h, n int) error {
+	if w <= 0 || h <= 0 || w > MBFMaxSize || h > MBFMaxSize {
+		return fmt.Errorf("无效的棋盘尺寸: %dx%d", w, h)
+	}
+	if n == 0 || n >= w*h || n > 0xffff {
+		return fmt.Errorf("无效的地雷数: %d", n)
+	}
+	return nil
+}
+
+// ParseMBF 解析 MBF 格式的棋盘，返回地雷布局
+func ParseMBF(data []byte) ([][]bool, error) {
+	if len(data) < mbfHeader {
+		return nil, fmt.Errorf("MBF 文件太短: %d 字节", len(data))
+	}
+	w, h := int(data[0]), int(data[1])
+	n := int(binary.BigEndian.Uint16(data[2:4]))
+	if err := checkMBF(w, h, n); err != nil {
+		return nil, err
+	}
+	if len(data) != mbfHeader+2*n {
+		return nil, fmt.Errorf("MBF 文件长度与地雷数不符: %d 字节", len(data))
+	}
+	mines := make([][]bool, h)
+	for y := range mines {
+		mines[y] = make([]bool, w)
+	}
+	for i := 0; i < n; i++ {
+		x, y := int(data[mbfHeader+2*i]), int(data[mbfHeader+2*i+1])
+		if x >= w || y >= h {
+			return nil, fmt.Errorf("地雷 (%d, %d) 超出棋盘", x, y)
+		}
+		if mines[y][x] {
+			return nil, fmt.Errorf("地雷 (%d, %d) 重复", x, y)
+		}
+		mines[y][x] = true
+	}
+	return mines, nil
+}
+
+// EncodeMBF 把地雷布局编码为 MBF 格式，地雷按行优先排列。
+// 与 ParseMBF 的限制相同，编码得到的文件总能重新导入
+func EncodeMBF(mines [][]bool) ([]byte, error) {
+	h, w := len(mines), 0
+	if h > 0 {
+		w = len(mines[0])
+	}
+	data := []byte{byte(w), byte(h), 0, 0}
+	n := 0
+	for y, row := range mines {
+		if len(row) != w {
+			return nil, fmt.Errorf("第 %d 行的宽度不一致", y+1)
+		}
+		for x, m := range row {
+			if m {
+				data = append(data, byte(x), byte(y))
+				n++
+			}
+		}
+	}
+	if err := checkMBF(w, h, n); err != nil {
+		return nil, fmt.Errorf("MBF 不支持的棋盘: %v", err)
+	}
+	binary.BigEndian.PutUint16(data[2:4], uint16(n))
+	return data, nil
+}
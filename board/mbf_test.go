@@ -0,0 +1,84 @@
+package board
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMBFRoundTrip(t *testing.T) {
+	mines := PlaceMines(30, 16, 99, 7, 3, 4)
+	data, err := EncodeMBF(mines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 4+2*99 {
+		t.Fatalf("编码后 %d 字节，期望 %d", len(data), 4+2*99)
+	}
+	got, err := ParseMBF(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Hash(got) != Hash(mines) {
+		t.Fatal("解析后的布局与原布局不同")
+	}
+}
+
+func TestParseMBF(t *testing.T) {
+	// 3x2，两颗地雷在 (0, 0) 和 (2, 1)
+	mines, err := ParseMBF([]byte{3, 2, 0, 2, 0, 0, 2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mines[0][0] || !mines[1][2] || mines[0][1] {
+		t.Fatalf("布局不正确: %v", mines)
+	}
+	data, _ := EncodeMBF(mines)
+	if !bytes.Equal(data, []byte{3, 2, 0, 2, 0, 0, 2, 1}) {
+		t.Fatalf("重新编码得到 %v", data)
+	}
+}
+
+func TestParseMBFInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{3, 2, 0},                // 太短
+		{0, 2, 0, 1, 0, 0},       // 宽度为 0
+		{3, 2, 0, 2, 0, 0},       // 长度与地雷数不符
+		{3, 2, 0, 1, 3, 0},       // 超出棋盘
+		{3, 2, 0, 2, 1, 1, 1, 1}, // 重复
+		{2, 1, 0, 2, 0, 0, 1, 0}, // 没有安全格子
+		{3, 2, 0, 0},             // 没有地雷
+	} {
+		if _, err := ParseMBF(data); err == nil {
+			t.Errorf("%v 应当解析失败", data)
+		}
+	}
+}
+
+func TestEncodeMBFInvalid(t *testing.T) {
+	grid := func(w, h int) [][]bool {
+		mines := make([][]bool, h)
+		for y := range mines {
+			mines[y] = make([]bool, w)
+		}
+		return mines
+	}
+	full := grid(2, 2)
+	for _, row := range full {
+		for x := range row {
+			row[x] = true
+		}
+	}
+	wide := grid(MBFMaxSize+1, 1)
+	wide[0][0] = true
+	for name, mines := range map[string][][]bool{
+		"空棋盘":    nil,
+		"没有地雷":   grid(3, 2),
+		"没有安全格子": full,
+		"太宽":     wide,
+		"宽度不一致":  {{true, false}, {false}},
+	} {
+		if _, err := EncodeMBF(mines); err == nil {
+			t.Errorf("%s: 应当编码失败", name)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package board
+
+// PlaceMines 按 seed 在 width×height 的方格棋盘上放置 count 个地雷，(safeX, safeY) 及其周围
+// 8 格不放地雷，超出棋盘的部分忽略。相同参数总是得到相同的布局。
+// 返回 mines[y][x]，为 true 表示有地雷
+func PlaceMines(width, height, count int, seed int64, safeX, safeY int) [][]bool {
+	return PlaceMinesOn(Square8{}, width, height, count, seed, safeX, safeY)
+}
+
+// PlaceMinesOn 与 PlaceMines 相同，安全区为 (safeX, safeY) 及其在拓扑 t 中的相邻格子
+func PlaceMinesOn(t Topology, width, height, count int, seed int64, safeX, safeY int) [][]bool {
+	return placeMines(t, nil, width, height, count, seed, safeX, safeY)
+}
+
+// PlaceMinesCell 与 PlaceMinesOn 相同，但安全区只有 (safeX, safeY) 一个格子，
+// 首次点击可能翻开数字。mask 为 nil 时整个矩形都是棋盘
+func PlaceMinesCell(mask *Mask, width, height, count int, seed int64, safeX, safeY int) [][]bool {
+	return placeMinesAvoiding(mask, width, height, count, seed, map[[2]int]bool{{safeX, safeY}: true})
+}
+
+// mask 为 nil 时整个矩形都是棋盘
+func placeMines(t Topology, mask *Mask, width, height, count int, seed int64, safeX, safeY int) [][]bool {
+	safe := map[[2]int]bool{{safeX, safeY}: true}
+	for _, p := range t.Neighbors(nil, width, height, safeX, safeY) {
+		safe[p] = true
+	}
+	return placeMinesAvoiding(mask, width, height, count, seed, safe)
+}
+
+func placeMinesAvoiding(mask *Mask, width, height, count int, seed int64, safe map[[2]int]bool) [][]bool {
+	mines := make([][]bool, height)
+	cells := make([]bool, width*height)
+	for y := range mines {
+		mines[y] = cells[y*width : (y+1)*width]
+	}
+
+	rng := NewRand(seed)
+	placed := 0
+	for placed < count {
+		x := rng.IntN(width)
+		y := rng.IntN(height)
+		if !mines[y][x] && !safe[[2]int{x, y}] && (mask == nil || mask.Contains(x, y)) {
+			mines[y][x] = true
+			placed++
+		}
+	}
+	return mines
+}
+
+// CountNeighbors 返回方格棋盘上每个格子周围 8 格的地雷数
+func CountNeighbors(mines [][]bool) [][]int {
+	return CountNeighborsOn(Square8{}, mines)
+}
+
+// CountNeighborsOn 返回每个格子在拓扑 t 中相邻的地雷数
+func CountNeighborsOn(t Topology, mines [][]bool) [][]int {
+	height := len(mines)
+	if height == 0 {
+		return nil
+	}
+	width := len(mines[0])
+	counts := make([][]int, height)
+	cells := make([]int, width*height)
+	for y := range counts {
+		counts[y] = cells[y*width : (y+1)*width]
+	}
+	var buf [][2]int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !mines[y][x] {
+				continue
+			}
+			// 相邻关系是对称的，地雷给它的每个相邻格子加一
+			buf = t.Neighbors(buf[:0], width, height, x, y)
+			for _, p := range buf {
+				counts[p[1]][p[0]]++
+			}
+		}
+	}
+	return counts
+}
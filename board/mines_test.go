@@ -0,0 +1,239 @@
+package board
+
+import "testing"
+
+func TestPlaceMines(t *testing.T) {
+	mines := PlaceMines(100, 100, 1000, 42, 50, 50)
+	n := 0
+	for y, row := range mines {
+		for x, m := range row {
+			if !m {
+				continue
+			}
+			n++
+			if x >= 49 && x <= 51 && y >= 49 && y <= 51 {
+				t.Errorf("安全区 (%d, %d) 放置了地雷", x, y)
+			}
+		}
+	}
+	if n != 1000 {
+		t.Fatalf("放置了 %d 个地雷，期望 1000", n)
+	}
+}
+
+func TestPlaceMinesDeterministic(t *testing.T) {
+	a := PlaceMines(30, 16, 99, 7, 3, 4)
+	b := PlaceMines(30, 16, 99, 7, 3, 4)
+	for y := range a {
+		for x := range a[y] {
+			if a[y][x] != b[y][x] {
+				t.Fatalf("相同种子在 (%d, %d) 得到不同布局", x, y)
+			}
+		}
+	}
+}
+
+func TestPlaceMinesCell(t *testing.T) {
+	// 除安全格子外全部是地雷，相邻格子也不例外
+	mines := PlaceMinesCell(nil, 10, 10, 99, 5, 4, 4)
+	if mines[4][4] {
+		t.Fatal("安全格子 (4, 4) 放置了地雷")
+	}
+	if !mines[3][3] || !mines[5][5] {
+		t.Error("安全格子的相邻格子不应受保护")
+	}
+}
+
+func TestCountNeighbors(t *testing.T) {
+	mines := grid(3, 3)
+	mines[0][0] = true
+	mines[2][2] = true
+	counts := CountNeighbors(mines)
+	want := [][]int{
+		{0, 1, 0},
+		{1, 2, 1},
+		{0, 1, 0},
+	}
+	for y := range want {
+		for x := range want[y] {
+			if !mines[y][x] && counts[y][x] != want[y][x] {
+				t.Errorf("(%d, %d) 周围地雷数为 %d，期望 %d", x, y, counts[y][x], want[y][x])
+			}
+		}
+	}
+}
+
+func BenchmarkPlaceMines100x100(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		PlaceMines(100, 100, 1000, int64(i), 50, 50)
+	}
+}
+
+func BenchmarkPlaceMines1000x1000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		PlaceMines(1000, 1000, 150000, int64(i), 500, 500)
+	}
+}
+
+func BenchmarkCountNeighbors100x100(b *testing.B) {
+	mines := PlaceMines(100, 100, 1000, 1, 50, 50)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CountNeighbors(mines)
+	}
+}
+
+func TestCountNeighborsTorus(t *testing.T) {
+	mines := grid(4, 4)
+	mines[0][0] = true
+	counts := CountNeighborsOn(Torus{}, mines)
+	for _, p := range [][2]int{{3, 3}, {3, 0}, {0, 3}, {1, 1}} {
+		if counts[p[1]][p[0]] != 1 {
+			t.Errorf("(%d, %d) 周围的地雷数为 %d，期望 1", p[0], p[1], counts[p[1]][p[0]])
+		}
+	}
+	if counts[2][2] != 0 {
+		t.Errorf("(2, 2) 周围的地雷数为 %d，期望 0", counts[2][2])
+	}
+}
+
+func TestPlaceMinesTorusSafeArea(t *testing.T) {
+	mines := PlaceMinesOn(Torus{}, 10, 10, 80, 3, 0, 0)
+	for _, p := range [][2]int{{9, 9}, {0, 9}, {9, 0}, {1, 1}} {
+		if mines[p[1]][p[0]] {
+			t.Errorf("跨过边缘的安全区 (%d, %d) 放置了地雷", p[0], p[1])
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := PlaceMines(30, 16, 99, 7, 3, 4)
+	if Hash(a) != Hash(PlaceMines(30, 16, 99, 7, 3, 4)) {
+		t.Fatal("相同布局的哈希不同")
+	}
+	if len(Hash(a)) != HashLength {
+		t.Fatalf("哈希长度为 %d，期望 %d", len(Hash(a)), HashLength)
+	}
+	if Hash(a) == Hash(PlaceMines(30, 16, 99, 8, 3, 4)) {
+		t.Error("不同布局的哈希相同")
+	}
+	// 地雷位置相同但尺寸不同的棋盘是不同的布局
+	if Hash(grid(4, 2)) == Hash(grid(2, 4)) {
+		t.Error("不同尺寸的空棋盘哈希相同")
+	}
+}
+
+// 按字符画解析地雷布局，* 为地雷，其他字符为安全格子
+func parseMines(rows ...string) [][]bool {
+	mines := grid(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x := range row {
+			mines[y][x] = row[x] == '*'
+		}
+	}
+	return mines
+}
+
+func countMines(mines [][]bool) int {
+	n := 0
+	for _, row := range mines {
+		for _, m := range row {
+			if m {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestPlaceMinesConstraints(t *testing.T) {
+	cases := []struct {
+		name          string
+		topo          Topology
+		w, h, count   int
+		safeX, safeY  int
+		safeAreaCells int
+	}{
+		{"初级", Square8{}, 9, 9, 10, 4, 4, 9},
+		{"高级角落", Square8{}, 30, 16, 99, 0, 0, 4},
+		{"边上", Square8{}, 30, 16, 99, 15, 0, 6},
+		{"只剩安全区", Square8{}, 9, 9, 72, 4, 4, 9},
+		{"单行", Square8{}, 20, 1, 10, 10, 0, 3},
+		{"没有地雷", Square8{}, 9, 9, 0, 4, 4, 9},
+		{"四邻格", Square4{}, 9, 9, 76, 4, 4, 5},
+		{"环面角落", Torus{}, 9, 9, 72, 0, 0, 9},
+		{"马步", Knight{}, 9, 9, 72, 4, 4, 9},
+	}
+	for _, c := range cases {
+		for seed := int64(0); seed < 20; seed++ {
+			mines := PlaceMinesOn(c.topo, c.w, c.h, c.count, seed, c.safeX, c.safeY)
+			if n := countMines(mines); n != c.count {
+				t.Fatalf("%s: 放置了 %d 个地雷，期望 %d", c.name, n, c.count)
+			}
+			safe := append(c.topo.Neighbors(nil, c.w, c.h, c.safeX, c.safeY), [2]int{c.safeX, c.safeY})
+			if len(safe) != c.safeAreaCells {
+				t.Fatalf("%s: 安全区有 %d 个格子，期望 %d", c.name, len(safe), c.safeAreaCells)
+			}
+			for _, p := range safe {
+				if mines[p[1]][p[0]] {
+					t.Fatalf("%s: 种子 %d 在安全区 (%d, %d) 放置了地雷", c.name, seed, p[0], p[1])
+				}
+			}
+		}
+	}
+}
+
+// 首次点击总是翻开空白格子，连锁展开；只保护一个格子时首次点击不是地雷
+func TestFirstClickSafety(t *testing.T) {
+	for _, topo := range []Topology{Square8{}, Square4{}, Torus{}, Knight{}} {
+		for seed := int64(0); seed < 50; seed++ {
+			x, y := int(seed%9), int(seed/9%9)
+			mines := PlaceMinesOn(topo, 9, 9, 40, seed, x, y)
+			if n := CountNeighborsOn(topo, mines)[y][x]; mines[y][x] || n != 0 {
+				t.Fatalf("%s: 种子 %d 的首次点击 (%d, %d) 周围有 %d 个地雷", topo.Name(), seed, x, y, n)
+			}
+		}
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		if PlaceMinesCell(nil, 9, 9, 80, seed, 8, 8)[8][8] {
+			t.Fatalf("种子 %d 的安全格子放置了地雷", seed)
+		}
+	}
+}
+
+func TestCountNeighborsFixtures(t *testing.T) {
+	cases := []struct {
+		mines []string
+		want  []string // 地雷位置的数字不检查
+	}{
+		{
+			[]string{"...", ".*.", "..."},
+			[]string{"111", "1*1", "111"},
+		},
+		{
+			[]string{"***", "*.*", "***"},
+			[]string{"***", "*8*", "***"},
+		},
+		{
+			[]string{"*..*", "....", "*..*"},
+			[]string{"*11*", "2222", "*11*"},
+		},
+		{
+			[]string{"*.*.*"},
+			[]string{"*2*2*"},
+		},
+	}
+	for i, c := range cases {
+		counts := CountNeighbors(parseMines(c.mines...))
+		for y, row := range c.want {
+			for x := range row {
+				if row[x] == '*' {
+					continue
+				}
+				if want := int(row[x] - '0'); counts[y][x] != want {
+					t.Errorf("第 %d 组: (%d, %d) 周围地雷数为 %d，期望 %d", i+1, x, y, counts[y][x], want)
+				}
+			}
+		}
+	}
+}
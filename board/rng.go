@@ -0,0 +1,21 @@
+package board
+
+import "math/rand/v2"
+
+// NewSource 返回以 seed 为种子的随机数来源，相同种子总是得到相同的序列。
+// 布雷以及其他需要按种子重现的随机过程都从这里创建来源，不使用全局的随机数
+func NewSource(seed int64) rand.Source {
+	return rand.NewPCG(uint64(seed), pcgStream)
+}
+
+// NewRand 返回以 seed 为种子的随机数生成器，见 NewSource
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(NewSource(seed))
+}
+
+// PCG 的第二个种子固定不变，改动会让所有种子对应的布局都发生变化
+const pcgStream = 0x6d696e6573776565
+
+// LayoutVersion 是按种子布雷的算法版本：1 使用 math/rand，2 使用 PCG。
+// 同一个种子在不同版本中得到的布局不同，种子代码、挑战和成绩提交都带上这个版本
+const LayoutVersion = 2
@@ -0,0 +1,29 @@
+package board
+
+// Won 判断对局是否获胜：safe 个安全格子全部翻开，requireFlags 为 true 时 mines 颗地雷还要全部插上旗。
+// 只是插对了所有旗子而安全格子没有全部翻开时不算获胜
+func Won(safe, revealedSafe, mines, flaggedMines int, requireFlags bool) bool {
+	return revealedSafe == safe && (!requireFlags || flaggedMines == mines)
+}
+
+// ChordTargets 返回在数字为 n 的格子 (x, y) 上快速翻开时要翻开的格子：周围的旗数等于 n 时，
+// 为周围既未翻开也未插旗的格子，否则不翻开任何格子。旗子插错时其中会有地雷
+func ChordTargets(t Topology, width, height, x, y, n int, revealed, flagged func(x, y int) bool) [][2]int {
+	around := t.Neighbors(nil, width, height, x, y)
+	flags := 0
+	for _, p := range around {
+		if flagged(p[0], p[1]) {
+			flags++
+		}
+	}
+	if n == 0 || flags != n {
+		return nil
+	}
+	var targets [][2]int
+	for _, p := range around {
+		if !revealed(p[0], p[1]) && !flagged(p[0], p[1]) {
+			targets = append(targets, p)
+		}
+	}
+	return targets
+}
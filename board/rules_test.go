@@ -0,0 +1,100 @@
+package board
+
+import "testing"
+
+func TestWon(t *testing.T) {
+	cases := []struct {
+		name                   string
+		safe, revealed         int
+		mines, flagged         int
+		requireFlags, expected bool
+	}{
+		{"翻开全部并插旗", 71, 71, 10, 10, true, true},
+		{"翻开全部但缺一面旗", 71, 71, 10, 9, true, false},
+		{"插对所有旗但还有格子未翻开", 71, 70, 10, 10, true, false},
+		{"只插旗不翻开", 71, 0, 10, 10, true, false},
+		{"无旗模式翻开全部即获胜", 71, 71, 10, 0, false, true},
+		{"无旗模式还有格子未翻开", 71, 70, 10, 0, false, false},
+		{"无旗模式忽略旗数", 71, 71, 10, 3, false, true},
+		{"刚开局", 71, 0, 10, 0, true, false},
+	}
+	for _, c := range cases {
+		if got := Won(c.safe, c.revealed, c.mines, c.flagged, c.requireFlags); got != c.expected {
+			t.Errorf("%s: Won = %v，期望 %v", c.name, got, c.expected)
+		}
+	}
+}
+
+// 一次连锁翻开全部安全格子后，需要插旗的规则下还要插完旗才获胜
+func TestWonAfterFloodFill(t *testing.T) {
+	b := newTestBoard(5, 5)
+	b.mines = parseMines(
+		"....*",
+		".....",
+		".....",
+		".....",
+		"*....",
+	)
+	FloodFill(b.w, b.h, 2, 2, b.open)
+	safe := b.w*b.h - 2
+	if got := b.countRevealed(); got != safe {
+		t.Fatalf("翻开 %d 个格子，期望 %d", got, safe)
+	}
+	if !Won(safe, b.countRevealed(), 2, 0, false) {
+		t.Error("无旗模式下翻开全部安全格子应当获胜")
+	}
+	if Won(safe, b.countRevealed(), 2, 0, true) {
+		t.Error("没有插旗时不应获胜")
+	}
+	if !Won(safe, b.countRevealed(), 2, 2, true) {
+		t.Error("翻开全部并插完旗应当获胜")
+	}
+}
+
+// 按玩家的操作翻开格子，返回是否踩雷：翻开地雷即失败，快速翻开时旗子插错会翻到地雷
+func (b *testBoard) click(x, y int, chord bool) bool {
+	cells := [][2]int{{x, y}}
+	if chord {
+		cells = ChordTargets(Square8{}, b.w, b.h, x, y, b.neighbors(x, y),
+			func(x, y int) bool { return b.revealed[y][x] },
+			func(x, y int) bool { return b.flagged[y][x] })
+	}
+	for _, p := range cells {
+		if b.mines[p[1]][p[0]] {
+			return true
+		}
+		FloodFill(b.w, b.h, p[0], p[1], b.open)
+	}
+	return false
+}
+
+func TestLost(t *testing.T) {
+	// * 为地雷，F 为插旗的地雷，f 为插错旗的安全格子，o 为已翻开的格子
+	cases := []struct {
+		name  string
+		rows  []string
+		x, y  int
+		chord bool
+		lost  bool
+	}{
+		{"翻开地雷", []string{"*..", "...", "..."}, 0, 0, false, true},
+		{"翻开安全格子", []string{"*..", "...", "..."}, 2, 2, false, false},
+		{"翻开插旗的地雷旁的数字", []string{"F..", ".o.", "..."}, 1, 0, false, false},
+		{"旗子插对时快速翻开", []string{"Fo.", "oo.", "..."}, 1, 1, true, false},
+		{"旗子插错时快速翻开", []string{"*f.", "oo.", "..."}, 0, 1, true, true},
+		{"旗数不够时快速翻开不翻开任何格子", []string{"*..", "oo.", "..."}, 0, 1, true, false},
+	}
+	for _, c := range cases {
+		b := newTestBoard(len(c.rows[0]), len(c.rows))
+		for y, row := range c.rows {
+			for x := range row {
+				b.mines[y][x] = row[x] == '*' || row[x] == 'F'
+				b.flagged[y][x] = row[x] == 'F' || row[x] == 'f'
+				b.revealed[y][x] = row[x] == 'o'
+			}
+		}
+		if got := b.click(c.x, c.y, c.chord); got != c.lost {
+			t.Errorf("%s: 踩雷 = %v，期望 %v", c.name, got, c.lost)
+		}
+	}
+}
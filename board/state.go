@@ -0,0 +1,157 @@
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StateVersion 是当前的棋盘状态格式版本，格式有不兼容的变化时递增
+const StateVersion = 1
+
+// 棋盘状态中地雷层和格子层使用的字符
+const (
+	StateMine     = '*' // 地雷
+	StateSafe     = '.' // 没有地雷
+	StateMissing  = ' ' // 不规则形状以外的格子，两层中都用空格
+	StateHidden   = '#' // 未翻开
+	StateRevealed = 'o' // 已翻开
+	StateFlag     = 'F' // 插了旗
+	StateQuestion = '?' // 标了问号
+)
+
+// State 是棋盘完整状态的 JSON 格式，自动存档、谜题编辑器、测试和外部工具共用。
+// 地雷布局和玩家看到的状态分两层保存，每层每行一个字符串，每个格子一个字符：
+//
+//	{
+//	  "version": 1,
+//	  "width": 4,
+//	  "height": 2,
+//	  "topology": "torus",
+//	  "seed": 1700000000,
+//	  "elapsed_ms": 12500,
+//	  "mines": ["*...", "..*."],
+//	  "cells": ["F#oo", "oo#?"]
+//	}
+//
+// topology 为空表示八邻格，其他取值见 TopologyByName。数字不保存，由地雷层和拓扑计算
+type State struct {
+	Version   int      `json:"version"`
+	Width     int      `json:"width"`
+	Height    int      `json:"height"`
+	Topology  string   `json:"topology,omitempty"`
+	Seed      int64    `json:"seed"`
+	ElapsedMS int64    `json:"elapsed_ms"`
+	Mines     []string `json:"mines"`
+	Cells     []string `json:"cells"`
+}
+
+// LoadState 解析并校验 JSON 格式的棋盘状态
+func LoadState(data []byte) (*State, error) {
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("解析棋盘状态失败: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save 校验后输出带缩进的 JSON
+func (s *State) Save() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化棋盘状态失败: %v", err)
+	}
+	return data, nil
+}
+
+// Validate 检查版本、尺寸、拓扑和两层的字符是否有效，并且对局还可以继续：
+// 没有翻开的地雷，也没有翻开全部安全格子
+func (s *State) Validate() error {
+	if s.Version < 1 || s.Version > StateVersion {
+		return fmt.Errorf("不支持的棋盘状态版本: %d", s.Version)
+	}
+	if s.Width <= 0 || s.Height <= 0 {
+		return fmt.Errorf("无效的棋盘尺寸: %dx%d", s.Width, s.Height)
+	}
+	if s.ElapsedMS < 0 {
+		return fmt.Errorf("无效的用时: %d", s.ElapsedMS)
+	}
+	if s.Topology != "" {
+		if _, ok := TopologyByName(s.Topology); !ok {
+			return fmt.Errorf("未知的拓扑: %s", s.Topology)
+		}
+	}
+	layers := []struct {
+		name  string
+		rows  []string
+		valid string
+	}{
+		{"mines", s.Mines, string([]rune{StateMine, StateSafe, StateMissing})},
+		{"cells", s.Cells, string([]rune{StateHidden, StateRevealed, StateFlag, StateQuestion, StateMissing})},
+	}
+	for _, l := range layers {
+		if len(l.rows) != s.Height {
+			return fmt.Errorf("%s 有 %d 行，应为 %d 行", l.name, len(l.rows), s.Height)
+		}
+		for y, row := range l.rows {
+			if len(row) != s.Width {
+				return fmt.Errorf("%s 第 %d 行的宽度不一致", l.name, y+1)
+			}
+			if i := strings.IndexFunc(row, func(r rune) bool { return !strings.ContainsRune(l.valid, r) }); i >= 0 {
+				return fmt.Errorf("%s 第 %d 行有无效的字符 %q", l.name, y+1, row[i])
+			}
+		}
+	}
+	hidden := 0 // 未翻开的安全格子
+	for y := range s.Mines {
+		for x := 0; x < s.Width; x++ {
+			if (s.Mines[y][x] == StateMissing) != (s.Cells[y][x] == StateMissing) {
+				return fmt.Errorf("第 %d 行第 %d 列的形状在两层中不一致", y+1, x+1)
+			}
+			revealed := s.Cells[y][x] == StateRevealed
+			if revealed && s.Mines[y][x] == StateMine {
+				return fmt.Errorf("第 %d 行第 %d 列的地雷已经翻开，对局已经结束", y+1, x+1)
+			}
+			if !revealed && s.Mines[y][x] == StateSafe {
+				hidden++
+			}
+		}
+	}
+	if hidden == 0 {
+		return fmt.Errorf("所有安全格子都已翻开，对局已经结束")
+	}
+	return nil
+}
+
+// MineGrid 返回地雷布局
+func (s *State) MineGrid() [][]bool {
+	mines := make([][]bool, s.Height)
+	for y, row := range s.Mines {
+		mines[y] = make([]bool, s.Width)
+		for x := range row {
+			mines[y][x] = row[x] == StateMine
+		}
+	}
+	return mines
+}
+
+// Mask 返回不规则形状的轮廓，完整的矩形棋盘返回 nil
+func (s *State) Mask() *Mask {
+	if !strings.ContainsRune(strings.Join(s.Mines, ""), StateMissing) {
+		return nil
+	}
+	return NewMask("", s.Width, s.Height, func(x, y int) bool {
+		return s.Mines[y][x] != StateMissing
+	})
+}
+
+// Cell 返回格子层中 (x, y) 的字符
+func (s *State) Cell(x, y int) byte {
+	return s.Cells[y][x]
+}
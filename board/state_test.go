@@ -0,0 +1,69 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	s := &State{
+		Version:   StateVersion,
+		Width:     4,
+		Height:    2,
+		Topology:  "torus",
+		Seed:      42,
+		ElapsedMS: 12500,
+		Mines:     []string{"*...", "..* "},
+		Cells:     []string{"F#oo", "oo? "},
+	}
+	data, err := s.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadState(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Seed != 42 || got.ElapsedMS != 12500 || got.Topology != "torus" || got.Cell(0, 0) != StateFlag {
+		t.Errorf("往返后的状态不一致: %+v", got)
+	}
+	mines := got.MineGrid()
+	if !mines[0][0] || !mines[1][2] || mines[0][1] {
+		t.Errorf("地雷层解析错误: %v", mines)
+	}
+	mask := got.Mask()
+	if mask == nil || mask.Contains(3, 1) || !mask.Contains(2, 1) {
+		t.Error("形状轮廓解析错误")
+	}
+}
+
+func TestStateValidate(t *testing.T) {
+	valid := func() State {
+		return State{Version: 1, Width: 3, Height: 1, Mines: []string{"*.."}, Cells: []string{"#o#"}}
+	}
+	if s := valid(); s.Validate() != nil || s.Mask() != nil {
+		t.Fatal("有效的状态未通过校验")
+	}
+	cases := map[string]func(s *State){
+		"版本": func(s *State) { s.Version = 2 },
+		"尺寸": func(s *State) { s.Width = 0 },
+		"拓扑": func(s *State) { s.Topology = "cube" },
+		"行数": func(s *State) { s.Cells = nil },
+		"宽度": func(s *State) { s.Mines = []string{"*..."} },
+		"字符": func(s *State) { s.Cells = []string{"#x#"} },
+		"形状": func(s *State) { s.Mines = []string{"* ."} },
+		"用时": func(s *State) { s.ElapsedMS = -1 },
+		"踩雷": func(s *State) { s.Cells = []string{"oo#"} },
+		"完成": func(s *State) { s.Cells = []string{"Foo"} },
+	}
+	for name, change := range cases {
+		s := valid()
+		change(&s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s无效的状态通过了校验", name)
+		}
+	}
+	if _, err := LoadState([]byte(`{"version":1`)); err == nil || !strings.Contains(err.Error(), "解析") {
+		t.Errorf("无效的 JSON: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package board
+
+// Topology 决定棋盘上哪些格子相邻，数字、连锁翻开和安全区都按它计算。
+// 格子总是存放在 width×height 的矩形数组中，拓扑只负责给出相邻的格子
+type Topology interface {
+	// Name 是拓扑的名称，用于存档和按名称查找
+	Name() string
+	// Neighbors 把 (x, y) 的相邻格子追加到 buf 后返回，不会返回棋盘以外的格子
+	Neighbors(buf [][2]int, width, height, x, y int) [][2]int
+}
+
+// Topologies 是所有可用的拓扑，第一个是默认的方格棋盘
+var Topologies = []Topology{Square8{}, Square4{}, Torus{}, Knight{}, Hex{}}
+
+// TopologyByName 按名称查找拓扑，找不到时 ok 为 false
+func TopologyByName(name string) (t Topology, ok bool) {
+	for _, t := range Topologies {
+		if t.Name() == name {
+			return t, true
+		}
+	}
+	return Square8{}, false
+}
+
+var (
+	kingMoves   = [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+	rookMoves   = [][2]int{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
+	knightMoves = [][2]int{{1, -2}, {2, -1}, {2, 1}, {1, 2}, {-1, 2}, {-2, 1}, {-2, -1}, {-1, -2}}
+	// 轴向坐标 (q, r) 中相邻格子的 6 个方向，x 为 q、y 为 r
+	hexMoves = [][2]int{{1, 0}, {1, -1}, {0, -1}, {-1, 0}, {-1, 1}, {0, 1}}
+)
+
+// 按偏移量列出棋盘内的格子
+func appendMoves(buf [][2]int, width, height, x, y int, moves [][2]int) [][2]int {
+	for _, m := range moves {
+		nx, ny := x+m[0], y+m[1]
+		if nx >= 0 && nx < width && ny >= 0 && ny < height {
+			buf = append(buf, [2]int{nx, ny})
+		}
+	}
+	return buf
+}
+
+// Square8 是经典的方格棋盘，周围 8 格相邻
+type Square8 struct{}
+
+func (Square8) Name() string { return "square" }
+
+func (Square8) Neighbors(buf [][2]int, width, height, x, y int) [][2]int {
+	return appendMoves(buf, width, height, x, y, kingMoves)
+}
+
+// Square4 只有上下左右 4 格相邻，数字最大为 4
+type Square4 struct{}
+
+func (Square4) Name() string { return "square4" }
+
+func (Square4) Neighbors(buf [][2]int, width, height, x, y int) [][2]int {
+	return appendMoves(buf, width, height, x, y, rookMoves)
+}
+
+// Torus 是上下、左右两边相连的方格棋盘，边缘的格子与对边的格子相邻。
+// 棋盘的宽和高都需要至少为 3，否则同一个格子会被当作多个相邻格子
+type Torus struct{}
+
+func (Torus) Name() string { return "torus" }
+
+func (Torus) Neighbors(buf [][2]int, width, height, x, y int) [][2]int {
+	if x < 0 || x >= width || y < 0 || y >= height {
+		return buf
+	}
+	for _, m := range kingMoves {
+		nx, ny := Wrap(width, height, x+m[0], y+m[1])
+		buf = append(buf, [2]int{nx, ny})
+	}
+	return buf
+}
+
+// Wrap 把任意坐标换算到环面棋盘上
+func Wrap(width, height, x, y int) (int, int) {
+	return ((x % width) + width) % width, ((y % height) + height) % height
+}
+
+// Knight 按国际象棋中马的走法相邻，数字表示马步可达的 8 个格子中的地雷数
+type Knight struct{}
+
+func (Knight) Name() string { return "knight" }
+
+func (Knight) Neighbors(buf [][2]int, width, height, x, y int) [][2]int {
+	return appendMoves(buf, width, height, x, y, knightMoves)
+}
+
+// Hex 是六边形棋盘：(x, y) 存放轴向坐标为 (x-R, y-R) 的格子，R = (width-1)/2，
+// 满足 |q|、|r|、|q+r| 都不超过 R 的位置才在棋盘上，其余位置不使用
+type Hex struct{}
+
+func (Hex) Name() string { return "hex" }
+
+func (Hex) Neighbors(buf [][2]int, width, height, x, y int) [][2]int {
+	if !HexContains(width, x, y) {
+		return buf
+	}
+	n := len(buf)
+	buf = appendMoves(buf, width, height, x, y, hexMoves)
+	kept := buf[:n]
+	for _, p := range buf[n:] {
+		if HexContains(width, p[0], p[1]) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// HexContains 判断 Hex 拓扑中 (x, y) 是否在六边形棋盘上
+func HexContains(width, x, y int) bool {
+	r := (width - 1) / 2
+	q, s := x-r, y-r
+	return abs(q) <= r && abs(s) <= r && abs(q+s) <= r
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
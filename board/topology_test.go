@@ -0,0 +1,57 @@
+package board
+
+import "testing"
+
+// 每种拓扑的相邻关系都应是对称的，CountNeighborsOn 依赖这一点
+func TestTopologySymmetric(t *testing.T) {
+	const w, h = 9, 9
+	for _, topo := range Topologies {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				for _, p := range topo.Neighbors(nil, w, h, x, y) {
+					found := false
+					for _, q := range topo.Neighbors(nil, w, h, p[0], p[1]) {
+						found = found || q == [2]int{x, y}
+					}
+					if !found {
+						t.Errorf("%s: (%d, %d) 与 (%d, %d) 的相邻关系不对称", topo.Name(), x, y, p[0], p[1])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTopologyNeighborCounts(t *testing.T) {
+	cases := []struct {
+		topo Topology
+		x, y int
+		want int
+	}{
+		{Square8{}, 4, 4, 8},
+		{Square8{}, 0, 0, 3},
+		{Square4{}, 4, 4, 4},
+		{Square4{}, 0, 0, 2},
+		{Torus{}, 0, 0, 8},
+		{Knight{}, 4, 4, 8},
+		{Knight{}, 0, 0, 2},
+		{Hex{}, 4, 4, 6},
+		{Hex{}, 8, 0, 3}, // 六边形的角
+	}
+	for _, c := range cases {
+		if got := len(c.topo.Neighbors(nil, 9, 9, c.x, c.y)); got != c.want {
+			t.Errorf("%s: (%d, %d) 有 %d 个相邻格子，期望 %d", c.topo.Name(), c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestTopologyByName(t *testing.T) {
+	for _, topo := range Topologies {
+		if got, ok := TopologyByName(topo.Name()); !ok || got != topo {
+			t.Errorf("按名称 %s 找到 %v", topo.Name(), got)
+		}
+	}
+	if _, ok := TopologyByName("triangle"); ok {
+		t.Error("找到了不存在的拓扑")
+	}
+}
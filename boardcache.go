@@ -0,0 +1,79 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 棋盘的离屏缓存：每帧只重绘外观发生变化的格子，再整体绘制到屏幕上
+type boardCache struct {
+	img   *ebiten.Image
+	looks [][]cellLook
+	theme Theme
+	skin  string // 旗帜样式，见 cosmetics.go
+	scale int    // 格子按 scale 倍大小绘制，见 boardScale
+}
+
+// 决定格子外观的全部状态，相同时格子无需重绘
+type cellLook struct {
+	cell        Cell
+	gameOver    bool
+	exploded    bool
+	hideNumbers bool
+	missing     bool // 不规则形状以外的格子，不绘制
+	frame       int  // 多帧贴图的帧号加一，0 表示使用静态贴图，见 animation.go
+}
+
+func (g *Game) cellLook(x, y int) cellLook {
+	return cellLook{
+		cell:        g.shownCell(x, y),
+		gameOver:    g.gameOver,
+		exploded:    g.gameOver && x == g.explodedX && y == g.explodedY,
+		hideNumbers: g.hidesNumbers(),
+		missing:     !g.exists(x, y),
+		frame:       g.cellFrame(x, y),
+	}
+}
+
+// 更新缓存中发生变化的格子，返回格子放大 scale 倍的完整棋盘图像
+func (g *Game) boardImage(scale int) *ebiten.Image {
+	config := g.size
+	size := cellSize * scale
+	w, h := config.GridWidth*size, config.GridHeight*size
+
+	c := g.boardCache
+	// 尺寸、缩放、主题或旗帜样式变化时整体重绘
+	if c == nil || c.img.Bounds().Dx() != w || c.img.Bounds().Dy() != h || c.theme != activeTheme || c.skin != activeFlagSkin().Name || c.scale != scale {
+		if c != nil {
+			c.img.Dispose()
+		}
+		c = &boardCache{img: ebiten.NewImage(w, h), theme: activeTheme, skin: activeFlagSkin().Name, scale: scale}
+		c.looks = make([][]cellLook, config.GridHeight)
+		for y := range c.looks {
+			c.looks[y] = make([]cellLook, config.GridWidth)
+		}
+		g.boardCache = c
+		for y := 0; y < config.GridHeight; y++ {
+			for x := 0; x < config.GridWidth; x++ {
+				c.looks[y][x] = g.cellLook(x, y)
+				g.drawLookSized(c.img, c.looks[y][x], float64(x*size), float64(y*size), float64(size), 1)
+			}
+		}
+		return c.img
+	}
+
+	for y := 0; y < config.GridHeight; y++ {
+		for x := 0; x < config.GridWidth; x++ {
+			look := g.cellLook(x, y)
+			if look == c.looks[y][x] {
+				continue
+			}
+			c.looks[y][x] = look
+			rect := image.Rect(x*size, y*size, (x+1)*size, (y+1)*size)
+			c.img.SubImage(rect).(*ebiten.Image).Clear()
+			g.drawLookSized(c.img, look, float64(x*size), float64(y*size), float64(size), 1)
+		}
+	}
+	return c.img
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"minesweeper/board"
+)
+
+// 棋盘状态文件：按 board.State 的格式导出当前对局的地雷布局、格子状态和用时，
+// 可以拖放到窗口中继续，也可以交给外部工具分析。导入的棋盘作为谜题处理，不计入排行
+
+// 每行一个字符串的格子状态，自动存档和棋盘状态文件共用
+func (g *Game) cellRows() []string {
+	rows := make([]string, 0, len(g.grid))
+	for y, row := range g.grid {
+		var b strings.Builder
+		for x, cell := range row {
+			switch {
+			case !g.exists(x, y):
+				b.WriteByte(board.StateMissing)
+			case cell.revealed:
+				b.WriteByte(board.StateRevealed)
+			case cell.flagged:
+				b.WriteByte(board.StateFlag)
+			case cell.questioned:
+				b.WriteByte(board.StateQuestion)
+			default:
+				b.WriteByte(board.StateHidden)
+			}
+		}
+		rows = append(rows, b.String())
+	}
+	return rows
+}
+
+// 按 cellRows 的格式还原格子状态，同时更新已翻开和已标记的计数
+func (g *Game) applyCellRows(rows []string) {
+	for y, row := range rows {
+		for x := 0; x < len(row) && y < g.gridHeight && x < g.gridWidth; x++ {
+			cell := &g.grid[y][x]
+			switch row[x] {
+			case board.StateRevealed:
+				cell.revealed = true
+				if !cell.hasMine {
+					g.revealedSafe++
+				}
+			case board.StateFlag:
+				cell.flagged = true
+				if cell.hasMine {
+					g.flaggedMines++
+				}
+			case board.StateQuestion:
+				cell.questioned = true
+			}
+		}
+	}
+}
+
+// 当前对局的棋盘状态，还没有布雷时返回 nil
+func (g *Game) boardState() *board.State {
+	if !g.minesPlaced {
+		return nil
+	}
+	s := &board.State{
+		Version:   board.StateVersion,
+		Width:     g.gridWidth,
+		Height:    g.gridHeight,
+		Topology:  g.topologyName(),
+		Seed:      g.seed,
+		ElapsedMS: g.elapsedTime.Milliseconds(),
+		Cells:     g.cellRows(),
+	}
+	for y, row := range g.grid {
+		var b strings.Builder
+		for x, cell := range row {
+			switch {
+			case !g.exists(x, y):
+				b.WriteByte(board.StateMissing)
+			case cell.hasMine:
+				b.WriteByte(board.StateMine)
+			default:
+				b.WriteByte(board.StateSafe)
+			}
+		}
+		s.Mines = append(s.Mines, b.String())
+	}
+	return s
+}
+
+// 从棋盘状态继续对局。地雷层转换为谜题，起点为第一个翻开的安全格子，
+// 没有翻开的格子时为第一个安全格子，自动存档恢复时以此重建
+func (g *Game) loadBoardState(s *board.State) error {
+	t, ok := squareTopology(s.Topology)
+	if !ok {
+		return fmt.Errorf("不支持的棋盘类型: %s", s.Topology)
+	}
+	p := &puzzle{Name: tr("棋盘文件"), Topology: s.Topology, StartX: -1, StartY: -1}
+	for _, row := range s.Mines {
+		p.Rows = append(p.Rows, strings.ReplaceAll(row, string(board.StateMissing), string(puzzleMissing)))
+	}
+	for y := 0; y < s.Height && p.StartX < 0; y++ {
+		for x := 0; x < s.Width; x++ {
+			if s.Mines[y][x] == board.StateSafe && s.Cell(x, y) == board.StateRevealed {
+				p.StartX, p.StartY = x, y
+				break
+			}
+		}
+	}
+	for y := 0; y < s.Height && p.StartX < 0; y++ {
+		if x := strings.IndexByte(s.Mines[y], board.StateSafe); x >= 0 {
+			p.StartX, p.StartY = x, y
+		}
+	}
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	if err := g.newVariantRoundSized(Custom, DifficultyConfig{s.Width, s.Height, p.mineCount()}, t, ""); err != nil {
+		return err
+	}
+	g.shape = s.Mask()
+	g.puzzle = p
+	g.seed = s.Seed
+
+	g.setMines(s.MineGrid(), p.StartX, p.StartY)
+	g.applyCellRows(s.Cells)
+
+	elapsed := time.Duration(s.ElapsedMS) * time.Millisecond
+	g.firstClick = false
+	g.elapsedTime = elapsed
+	g.startTime = time.Now().Add(-elapsed)
+	showToast(p.title())
+	return nil
+}
+
+func (g *Game) exportBoardState() error {
+	name, err := g.writeBoardState()
+	if err != nil {
+		slog.Warn("导出棋盘失败", "err", err)
+		showToast(tr("导出棋盘失败"))
+		return nil
+	}
+	showToast(tr("已导出棋盘") + ": " + filepath.Base(name))
+	return nil
+}
+
+// 把当前对局的棋盘状态保存到档案存储的 boards 目录，返回文件名
+func (g *Game) writeBoardState() (string, error) {
+	s := g.boardState()
+	if s == nil {
+		return "", fmt.Errorf("还没有布雷")
+	}
+	data, err := s.Save()
+	if err != nil {
+		return "", err
+	}
+	name := "boards/board-" + time.Now().Format("20060102-150405") + ".json"
+	if err := g.store.Write(name, data); err != nil {
+		return "", fmt.Errorf("保存棋盘失败: %v", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log/slog"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/agent"
+	"minesweeper/board"
+	"minesweeper/solver"
+)
+
+// 机器人演示：机器人按可见信息一步一步地玩当前棋盘，
+// 标出每一步操作的格子和依据的数字，并显示说明。默认使用内置求解器，
+// 通过 --agent 可以接入外部机器人，见 agent 包。机器人参与过的对局单独记录，见 variant
+var botSpeeds = []time.Duration{2 * time.Second, time.Second, 500 * time.Millisecond, 250 * time.Millisecond, 100 * time.Millisecond, 0}
+
+// 演示速度在 botSpeeds 中的位置，换局后保留
+var botSpeed = 2
+
+// 通过 --agent 接入的外部机器人，nil 时使用内置求解器
+var externalAgent agent.Agent
+
+type botReply struct {
+	action agent.Action
+	err    error
+}
+
+type botState struct {
+	agent   agent.Agent
+	running bool
+	last    time.Time
+	reply   chan botReply // 等待中的回复，机器人在后台思考，不阻塞游戏循环
+	action  agent.Action  // 最近一步，用于高亮
+	stepped bool          // 已经走过至少一步
+}
+
+// 街机的关卡只有玩家自己通过才算分，机器人不可用
+func (g *Game) botAllowed() bool {
+	return g.probabilityAllowed() && g.arcade == nil
+}
+
+func botActive(g *Game) bool {
+	return g.bot != nil
+}
+
+func (g *Game) newBot() *botState {
+	a := externalAgent
+	if a == nil {
+		a = &solverAgent{topology: g.adjacency(), start: g.botStart(), mines: make(map[solver.Point]bool)}
+	}
+	return &botState{agent: a}
+}
+
+func (g *Game) toggleBot() error {
+	if g.arcade != nil {
+		showToast(tr("街机模式中不可用"))
+		return nil
+	}
+	if !g.botAllowed() {
+		showToast(tr("对战和挑战中不可用"))
+		return nil
+	}
+	if g.gameOver || g.won {
+		return nil
+	}
+	if g.bot == nil {
+		g.bot = g.newBot()
+	}
+	g.bot.running = !g.bot.running
+	g.bot.last = time.Time{}
+	return nil
+}
+
+func (g *Game) changeBotSpeed(d int) error {
+	botSpeed = clamp(botSpeed+d, 0, len(botSpeeds)-1)
+	showToast(fmt.Sprintf("%s %s", tr("机器人速度"), botSpeeds[botSpeed]))
+	return nil
+}
+
+// 到了下一步的时间就把棋盘交给机器人，收到回复后执行。
+// 对局结束后再问一次机器人，由它决定重新开始还是停下
+func (g *Game) updateBot() {
+	// 外部机器人在每局开始时自动接手
+	if g.bot == nil && externalAgent != nil && g.botAllowed() && !g.gameOver && !g.won {
+		g.bot = g.newBot()
+		g.bot.running = true
+	}
+	b := g.bot
+	if b == nil {
+		return
+	}
+	if b.reply != nil {
+		select {
+		case r := <-b.reply:
+			b.reply = nil
+			if r.err == nil {
+				r.err = r.action.Validate(g.agentState())
+			}
+			if r.err != nil {
+				slog.Warn("机器人出错", "err", r.err)
+				showToast(tr("机器人出错"))
+				b.running = false
+				return
+			}
+			g.applyBotAction(r.action)
+		default:
+		}
+		return
+	}
+	if !b.running || time.Since(b.last) < botSpeeds[botSpeed] {
+		return
+	}
+	b.last = time.Now()
+	s, a := g.agentState(), b.agent
+	b.reply = make(chan botReply, 1)
+	go func(reply chan<- botReply) {
+		action, err := a.Act(s)
+		reply <- botReply{action, err}
+	}(b.reply)
+}
+
+func (g *Game) applyBotAction(a agent.Action) {
+	b := g.bot
+	switch a.Kind {
+	case agent.Stop:
+		b.running = false
+		return
+	case agent.Restart:
+		if err := g.restartRound(); err != nil {
+			slog.Warn("重新开始失败", "err", err)
+		}
+		return
+	}
+	b.action, b.stepped = a, true
+	cell := &g.grid[a.Y][a.X]
+	switch a.Kind {
+	case agent.Reveal:
+		g.botUnmark(a.X, a.Y)
+		g.act(moveReveal, a.X, a.Y)
+	case agent.Mark:
+		// 无旗模式中只记下结论，不插旗
+		for !cell.flagged && !g.noFlags {
+			g.act(moveFlag, a.X, a.Y)
+		}
+	case agent.Unmark:
+		g.botUnmark(a.X, a.Y)
+	}
+}
+
+// 清掉格子上的旗子或问号
+func (g *Game) botUnmark(x, y int) {
+	cell := &g.grid[y][x]
+	for cell.flagged || cell.questioned {
+		g.act(moveFlag, x, y)
+	}
+}
+
+// 交给机器人的棋盘，插旗的格子标为 agent.Flag，问号视为未翻开
+func (g *Game) agentState() agent.State {
+	s := agent.State{
+		Width:    g.gridWidth,
+		Height:   g.gridHeight,
+		Mines:    g.mineCount(),
+		Topology: g.topologyName(),
+		Cells:    make([]int, g.gridWidth*g.gridHeight),
+		Moves:    len(g.moves),
+	}
+	for y, row := range g.grid {
+		for x, cell := range row {
+			v := agent.Unknown
+			switch {
+			case !g.exists(x, y):
+				v = agent.Missing
+			case cell.revealed && !cell.hasMine:
+				v = cell.neighbors
+			case cell.flagged:
+				v = agent.Flag
+			}
+			s.Cells[y*g.gridWidth+x] = v
+		}
+	}
+	if g.won {
+		s.Result = agent.ResultWon
+	} else if g.gameOver {
+		s.Result = agent.ResultLost
+	}
+	return s
+}
+
+// 第一步：重玩种子时从原来的起点开始，否则翻开离中心最近的格子
+func (g *Game) botStart() solver.Point {
+	if g.minesPlaced && g.startX >= 0 {
+		return solver.Point{X: g.startX, Y: g.startY}
+	}
+	best, bestD := solver.Point{}, -1
+	for y := 0; y < g.gridHeight; y++ {
+		for x := 0; x < g.gridWidth; x++ {
+			dx, dy := 2*x-g.gridWidth+1, 2*y-g.gridHeight+1
+			if d := dx*dx + dy*dy; g.exists(x, y) && (bestD < 0 || d < bestD) {
+				best, bestD = solver.Point{X: x, Y: y}, d
+			}
+		}
+	}
+	return best
+}
+
+// 内置求解器：每次给出一步推理，优先使用简单规则，无法推理时翻开地雷概率最低的格子
+type solverAgent struct {
+	topology board.Topology
+	start    solver.Point
+	mines    map[solver.Point]bool // 推理出的地雷。玩家插的旗不一定对，不作为依据
+}
+
+func (s *solverAgent) Act(st agent.State) (agent.Action, error) {
+	if st.Result != "" {
+		return agent.Action{Kind: agent.Stop}, nil
+	}
+	view := solver.NewView(st.Width, st.Height)
+	view.Topology = s.topology
+	opened := false
+	for i, c := range st.Cells {
+		if c >= 0 {
+			view.Cells[i] = c
+			opened = true
+		}
+	}
+	if !opened {
+		return agent.Action{Kind: agent.Reveal, X: s.start.X, Y: s.start.Y, Note: tr("开局")}, nil
+	}
+
+	for {
+		hint, ok := solver.NextHint(view, s.mines)
+		if !ok {
+			break
+		}
+		a := agent.Action{Kind: agent.Reveal, X: hint.Cell.X, Y: hint.Cell.Y, Note: tr("单格计数")}
+		if hint.Level == solver.LevelSubset {
+			a.Note = tr("子集推理")
+		}
+		for _, p := range hint.Reasons {
+			a.Reasons = append(a.Reasons, agent.Point{X: p.X, Y: p.Y})
+		}
+		if !hint.Mine {
+			return a, nil
+		}
+		s.mines[hint.Cell] = true
+		// 已经插了旗的地雷不需要再操作，继续找下一步
+		if st.At(hint.Cell.X, hint.Cell.Y) != agent.Flag {
+			a.Kind = agent.Mark
+			return a, nil
+		}
+	}
+	return s.guess(st, view), nil
+}
+
+func (s *solverAgent) guess(st agent.State, view *solver.View) agent.Action {
+	probs := solver.Probabilities(view, st.Mines)
+	best, bestP := solver.Point{X: -1}, 2.0
+	for y := 0; y < st.Height; y++ {
+		for x := 0; x < st.Width; x++ {
+			p := solver.Point{X: x, Y: y}
+			if c := st.At(x, y); (c != agent.Unknown && c != agent.Flag) || s.mines[p] {
+				continue
+			}
+			prob := 0.5
+			if probs != nil {
+				prob = probs[y*st.Width+x]
+			}
+			if prob < bestP {
+				best, bestP = p, prob
+			}
+		}
+	}
+	if best.X < 0 {
+		return agent.Action{Kind: agent.Stop}
+	}
+	return agent.Action{Kind: agent.Reveal, X: best.X, Y: best.Y, Note: fmt.Sprintf("%s %d%%", tr("猜测"), int(bestP*100+0.5))}
+}
+
+// 高亮最近一步：操作的格子按结论着色，依据的数字用黄色框出
+func (g *Game) drawBot(screen *ebiten.Image) {
+	b := g.bot
+	if b == nil || !b.stepped {
+		return
+	}
+	for _, p := range b.action.Reasons {
+		if p.X < 0 || p.X >= g.gridWidth || p.Y < 0 || p.Y >= g.gridHeight {
+			continue
+		}
+		px, py := g.cellScreenPos(p.X, p.Y)
+		vector.StrokeRect(screen, px+2, py+2, cellSize-4, cellSize-4, 2, color.RGBA{230, 200, 0, 255}, false)
+	}
+	clr := color.RGBA{0, 180, 230, 255}
+	if b.action.Kind == agent.Mark {
+		clr = color.RGBA{230, 60, 60, 255}
+	}
+	px, py := g.cellScreenPos(b.action.X, b.action.Y)
+	vector.StrokeRect(screen, px+1, py+1, cellSize-2, cellSize-2, 3, clr, false)
+}
+
+// 状态栏中显示的说明
+func (g *Game) botStatus() string {
+	b := g.bot
+	status := tr("机器人") + " " + botSpeeds[botSpeed].String()
+	if !b.running {
+		status = tr("机器人已暂停")
+	}
+	if b.action.Note != "" {
+		status += " · " + b.action.Note
+	}
+	return status
+}
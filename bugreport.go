@@ -0,0 +1,144 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	issueURL      = "https://github.com/jsfaint/minesweeper/issues/new"
+	maxReportLog  = 256 << 10 // 报告中最多包含日志末尾的字节数
+	issueBodySize = 4000      // 预填内容的上限，过长的地址浏览器可能打不开
+)
+
+// 当前版本，取更新日志中最新的条目
+func appVersion() string {
+	entries, err := loadChangelog()
+	if err != nil || len(entries) == 0 {
+		return "unknown"
+	}
+	return entries[0].Version
+}
+
+// 报告中的运行环境说明，同时用于预填问题内容
+func (g *Game) environmentReport() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Version: %s\n", appVersion())
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" || s.Key == "vcs.modified" {
+				fmt.Fprintf(&b, "%s: %s\n", s.Key, s.Value)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "Go: %s\n", runtime.Version())
+	fmt.Fprintf(&b, "OS: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	w, h := ebiten.WindowSize()
+	fmt.Fprintf(&b, "Window: %dx%d  Scale: %.2f  Fullscreen: %v\n", w, h, ebiten.DeviceScaleFactor(), fullscreen())
+	fmt.Fprintf(&b, "Profile: %q  Language: %s  Theme: %s\n", profile, currentLanguage(), globalConfig.Theme)
+	fmt.Fprintf(&b, "Difficulty: %s  Topology: %s\n", difficultyKeys[g.difficulty], g.topology.Name())
+	return b.String()
+}
+
+// 把版本、配置、日志和当前对局打包为 zip，返回文件路径
+func (g *Game) writeBugReport() (string, error) {
+	path, err := logPath()
+	if err != nil {
+		return "", fmt.Errorf("获取目录失败: %v", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建目录失败: %v", err)
+	}
+	name := filepath.Join(dir, "bugreport-"+time.Now().Format("20060102-150405")+".zip")
+	f, err := os.Create(name)
+	if err != nil {
+		return "", fmt.Errorf("创建报告失败: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	add := func(file string, data []byte) error {
+		w, err := zw.Create(file)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	if err := add("environment.txt", []byte(g.environmentReport())); err != nil {
+		return "", fmt.Errorf("写入报告失败: %v", err)
+	}
+	config, _ := json.MarshalIndent(globalConfig, "", "  ")
+	if err := add("config.json", config); err != nil {
+		return "", fmt.Errorf("写入报告失败: %v", err)
+	}
+	if data, err := readLogTail(path); err == nil {
+		if err := add(logName, data); err != nil {
+			return "", fmt.Errorf("写入报告失败: %v", err)
+		}
+	}
+	// 当前对局的棋盘和操作记录，与自动存档格式相同，可以用来重现问题
+	if g.minesPlaced {
+		board, _ := json.MarshalIndent(g.checkpoint(), "", "  ")
+		if err := add("board.json", board); err != nil {
+			return "", fmt.Errorf("写入报告失败: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("写入报告失败: %v", err)
+	}
+	return name, nil
+}
+
+// 日志末尾的部分，日志很长时只保留最近的内容
+func readLogTail(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > maxReportLog {
+		if _, err := f.Seek(-maxReportLog, io.SeekEnd); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
+
+// 生成报告并打开预填了环境信息的新问题页面，同时打开报告所在的目录方便附加
+func (g *Game) reportBug() error {
+	name, err := g.writeBugReport()
+	if err != nil {
+		slog.Warn("生成问题报告失败", "err", err)
+		showToast(tr("生成问题报告失败"))
+		return nil
+	}
+	slog.Info("已生成问题报告", "path", name)
+
+	body := "**Describe the problem**\n\n\n**Steps to reproduce**\n\n\n**Environment**\n```\n" +
+		g.environmentReport() + "```\n\nPlease attach " + filepath.Base(name) + "\n"
+	if len(body) > issueBodySize {
+		body = body[:issueBodySize]
+	}
+	if err := openFile(issueURL + "?body=" + url.QueryEscape(body)); err != nil {
+		slog.Warn("打开浏览器失败", "err", err)
+	}
+	if err := openFile(filepath.Dir(name)); err != nil {
+		slog.Warn("打开目录失败", "err", err)
+	}
+	showToast(tr("已生成问题报告") + ": " + filepath.Base(name))
+	return nil
+}
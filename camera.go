@@ -0,0 +1,157 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/board"
+)
+
+// 窗口最多显示的格子数，更大的棋盘通过镜头滚动查看
+const (
+	maxViewCols = 30
+	maxViewRows = 20
+	cameraSpeed = cellSize / 2 // 方向键每帧滚动的像素
+)
+
+// 棋盘可见区域的像素尺寸
+func (g *Game) viewWidth() int {
+	return clamp(g.gridWidth, 0, maxViewCols) * cellSize
+}
+
+func (g *Game) viewHeight() int {
+	return clamp(g.gridHeight, 0, maxViewRows) * cellSize
+}
+
+// 方向键和鼠标滚轮移动镜头
+func (g *Game) updateCamera() {
+	dx, dy := cameraInput()
+	g.moveCamera(g.camX+dx, g.camY+dy)
+}
+
+// 本帧方向键和鼠标滚轮要求的镜头移动量，按住 Shift 时滚轮横向滚动
+func cameraInput() (dx, dy int) {
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		dx -= cameraSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		dx += cameraSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+		dy -= cameraSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		dy += cameraSpeed
+	}
+
+	wx, wy := ebiten.Wheel()
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		wx, wy = wy, wx
+	}
+	dx -= int(wx * cellSize)
+	dy -= int(wy * cellSize)
+	return dx, dy
+}
+
+// 将镜头移动到 (x, y)，限制在棋盘范围内；环面棋盘越过边缘时回到对边
+func (g *Game) moveCamera(x, y int) {
+	if g.wraps() {
+		g.camX, g.camY = board.Wrap(g.gridWidth*cellSize, g.gridHeight*cellSize, x, y)
+		return
+	}
+	g.camX = clamp(x, 0, g.gridWidth*cellSize-g.viewWidth())
+	g.camY = clamp(y, 0, g.gridHeight*cellSize-g.viewHeight())
+}
+
+// 屏幕坐标到格子坐标的换算，所有按格子点击的界面都通过它命中格子。
+// 棋盘左上角画在 (originX, originY)，镜头已经滚动了 (scrollX, scrollY) 像素
+type gridMapper struct {
+	originX, originY int
+	scrollX, scrollY int
+	viewW, viewH     int // 可点击区域的像素尺寸，0 表示整个棋盘
+	width, height    int // 棋盘的格子数，0 表示无限大的棋盘，坐标可以为负，见 endless.go
+	wrap             bool
+	hexSize          float64 // 大于 0 时为尖顶六边形格子，origin 是 (0, 0) 格子的中心，见 hex.go
+}
+
+// 不在可点击区域或棋盘范围内时 ok 为 false
+func (m gridMapper) cell(px, py int) (x, y int, ok bool) {
+	px, py = px-m.originX, py-m.originY
+	if m.hexSize > 0 {
+		x, y = hexRound(float64(px), float64(py), m.hexSize)
+		return x, y, true
+	}
+	w, h := m.viewW, m.viewH
+	if w == 0 || h == 0 {
+		w, h = m.width*cellSize, m.height*cellSize
+	}
+	if px < 0 || py < 0 || px >= w || py >= h {
+		return 0, 0, false
+	}
+	x, y = floorDiv(px+m.scrollX, cellSize), floorDiv(py+m.scrollY, cellSize)
+	if m.width == 0 || m.height == 0 {
+		return x, y, true
+	}
+	if m.wrap {
+		x, y = board.Wrap(m.width, m.height, x, y)
+	}
+	return x, y, x < m.width && y < m.height
+}
+
+// 相对于 (0, 0) 格子中心的像素坐标所在的六边形，返回轴向坐标 (q, r)
+func hexRound(x, y, size float64) (q, r int) {
+	fq := (math.Sqrt(3)/3*x - y/3) / size
+	fr := (2.0 / 3 * y) / size
+	fs := -fq - fr
+
+	rq, rr, rs := math.Round(fq), math.Round(fr), math.Round(fs)
+	dq, dr, ds := math.Abs(rq-fq), math.Abs(rr-fr), math.Abs(rs-fs)
+	if dq > dr && dq > ds {
+		rq = -rr - rs
+	} else if dr > ds {
+		rr = -rq - rs
+	}
+	return int(rq), int(rr)
+}
+
+// 当前对局的换算，尺寸取自当前棋盘而不是默认难度
+func (g *Game) boardMapper() gridMapper {
+	return gridMapper{
+		scrollX: g.camX, scrollY: g.camY,
+		viewW: g.viewWidth(), viewH: g.viewHeight(),
+		width: g.gridWidth, height: g.gridHeight,
+		wrap: g.wraps(),
+	}
+}
+
+// 屏幕坐标对应的格子，不在棋盘可见区域内时 ok 为 false
+func (g *Game) cellAt(px, py int) (x, y int, ok bool) {
+	x, y, ok = g.boardMapper().cell(px, py)
+	return x, y, ok && g.exists(x, y)
+}
+
+// 格子左上角的屏幕坐标，环面棋盘上取格子在可见区域中出现的位置
+func (g *Game) cellScreenPos(x, y int) (float32, float32) {
+	px, py := x*cellSize-g.camX, y*cellSize-g.camY
+	if g.wraps() {
+		px, py = torusScreenPos(px, g.gridWidth*cellSize), torusScreenPos(py, g.gridHeight*cellSize)
+	}
+	return float32(px), float32(py)
+}
+
+// 棋盘可见区域，绘制到这里的内容不会超出棋盘覆盖到底部状态栏
+func (g *Game) boardView(screen *ebiten.Image) *ebiten.Image {
+	return screen.SubImage(image.Rect(0, 0, g.viewWidth(), g.viewHeight())).(*ebiten.Image)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
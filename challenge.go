@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/board"
+)
+
+// 挑战周期
+const (
+	challengeDaily  = "daily"
+	challengeWeekly = "weekly"
+)
+
+var challengeTitles = map[string]string{
+	challengeDaily:  "每日挑战",
+	challengeWeekly: "每周挑战",
+}
+
+// 挑战棋盘：同一周期内所有玩家的棋盘相同
+type challenge struct {
+	Kind       string `json:"kind"`
+	Period     string `json:"period"` // 每日为 2006-01-02，每周为 2006-W01
+	Difficulty string `json:"difficulty"`
+	Seed       int64  `json:"seed"`
+	StartX     int    `json:"start_x"`
+	StartY     int    `json:"start_y"`
+	Token      string `json:"token,omitempty"`  // 服务器签发，提交成绩时用于验证棋盘
+	Layout     int    `json:"layout,omitempty"` // 布雷算法的版本，见 board.LayoutVersion，旧服务器不返回
+
+	Online bool `json:"-"` // 是否来自服务器
+}
+
+// 挑战周期以 UTC 日期划分，保证各时区的玩家拿到同一个棋盘
+func challengePeriod(kind string, now time.Time) string {
+	now = now.UTC()
+	if kind == challengeWeekly {
+		year, week := now.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return now.Format("2006-01-02")
+}
+
+// 离线时根据日期推导出挑战棋盘，与服务器不可用的其他玩家结果一致
+func localChallenge(kind string, now time.Time) challenge {
+	c := challenge{Kind: kind, Period: challengePeriod(kind, now), Difficulty: "medium", Layout: board.LayoutVersion}
+	if kind == challengeWeekly {
+		c.Difficulty = "hard"
+	}
+	h := fnv.New64a()
+	h.Write([]byte(c.Kind + ":" + c.Period))
+	c.Seed = int64(h.Sum64() >> 1)
+
+	d, _ := difficultyByKey(c.Difficulty)
+	config := difficultySettings[d]
+	c.StartX, c.StartY = config.GridWidth/2, config.GridHeight/2
+	return c
+}
+
+var challengeClient = &http.Client{Timeout: 5 * time.Second}
+
+// 从服务器获取本周期的挑战，失败或未配置服务器时使用离线棋盘
+func fetchChallenge(server, kind string, now time.Time) challenge {
+	local := localChallenge(kind, now)
+	if server == "" {
+		return local
+	}
+
+	c, err := requestChallenge(server, kind, local.Period)
+	if err != nil {
+		slog.Warn("获取挑战失败，使用离线棋盘", "err", err)
+		return local
+	}
+	return c
+}
+
+func requestChallenge(server, kind, period string) (challenge, error) {
+	u := strings.TrimRight(server, "/") + "/api/challenge/" + url.PathEscape(kind) + "?period=" + url.QueryEscape(period) +
+		"&layout=" + strconv.Itoa(board.LayoutVersion)
+	resp, err := challengeClient.Get(u)
+	if err != nil {
+		return challenge{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return challenge{}, fmt.Errorf("服务器返回 %s", resp.Status)
+	}
+
+	var c challenge
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return challenge{}, fmt.Errorf("解析挑战失败: %v", err)
+	}
+	d, ok := difficultyByKey(c.Difficulty)
+	if !ok || c.Kind != kind {
+		return challenge{}, fmt.Errorf("无效的挑战: %s %s", c.Kind, c.Difficulty)
+	}
+	// 服务器按其他版本的算法验证棋盘时，同一个种子的布局不同，提交的成绩无法通过验证
+	if c.Layout != board.LayoutVersion {
+		return challenge{}, fmt.Errorf("服务器的布局版本不同: %d", c.Layout)
+	}
+	config := difficultySettings[d]
+	if c.StartX < 0 || c.StartX >= config.GridWidth || c.StartY < 0 || c.StartY >= config.GridHeight {
+		return challenge{}, fmt.Errorf("无效的起始格子: %d, %d", c.StartX, c.StartY)
+	}
+	c.Online = true
+	return c, nil
+}
+
+func (g *Game) challengeButtons() []*menuButton {
+	start := func(kind string) func() error {
+		return func() error {
+			if g.challengeFetch != nil {
+				return nil
+			}
+			result := make(chan challenge, 1)
+			g.challengeFetch = result
+			server := globalConfig.ChallengeServer
+			go func() {
+				result <- fetchChallenge(server, kind, time.Now())
+			}()
+			return nil
+		}
+	}
+	buttons := []*menuButton{
+		{Button: &Button{Text: challengeTitles[challengeDaily]}, action: start(challengeDaily)},
+		{Button: &Button{Text: challengeTitles[challengeWeekly]}, action: start(challengeWeekly)},
+		g.backButton(),
+	}
+	g.layoutMenuButtons(buttons, 100)
+	return buttons
+}
+
+// 等待获取挑战的结果，拿到后开局
+func (g *Game) updateChallenge() error {
+	if g.challengeFetch == nil {
+		return nil
+	}
+	select {
+	case c := <-g.challengeFetch:
+		g.challengeFetch = nil
+		return g.beginChallenge(c)
+	default:
+		return nil
+	}
+}
+
+func (g *Game) beginChallenge(c challenge) error {
+	difficulty, _ := difficultyByKey(c.Difficulty)
+	if err := g.newRound(difficulty); err != nil {
+		return err
+	}
+	g.seed = c.Seed
+	g.layoutMines(c.StartX, c.StartY)
+	g.challenge = &c
+	if !c.Online && globalConfig.ChallengeServer != "" {
+		showToast(tr("无法连接服务器，使用离线棋盘"))
+	} else {
+		showToast(tr(challengeTitles[c.Kind]) + " " + c.Period)
+	}
+	return nil
+}
+
+func (g *Game) drawChallengeStatus(screen *ebiten.Image) {
+	if g.challengeFetch != nil {
+		g.drawCentered(screen, tr("正在获取挑战..."), 80)
+	}
+}
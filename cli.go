@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"minesweeper/agent"
+	"minesweeper/board"
+)
+
+// 命令行启动参数，用于脚本和桌面快捷方式直接进入指定的对局
+type launchOptions struct {
+	difficulty string
+	width      int
+	height     int
+	mines      int
+	seed       int64
+	seedSet    bool
+	fullscreen bool
+	mute       bool
+	lang       string
+	topology   string
+	shape      string
+	puzzle     string
+	agent      string
+	logLevel   slog.Level
+}
+
+// 本次运行是否静音，只由 --mute 设置，不写入配置
+var muted bool
+
+// 本次运行是否全屏，只由 --fullscreen 设置，不写入配置
+var fullscreenOverride bool
+
+func parseLaunchOptions(args []string) (*launchOptions, error) {
+	o := &launchOptions{}
+	fs := flag.NewFlagSet("minesweeper", flag.ContinueOnError)
+	fs.StringVar(&o.difficulty, "difficulty", "", "直接开始的难度：easy、medium、hard 或 huge")
+	fs.IntVar(&o.width, "width", 0, "自定义棋盘宽度，需同时指定 --height 和 --mines")
+	fs.IntVar(&o.height, "height", 0, "自定义棋盘高度")
+	fs.IntVar(&o.mines, "mines", 0, "自定义地雷数")
+	fs.Int64Var(&o.seed, "seed", 0, "地雷布局的随机种子")
+	fs.BoolVar(&o.fullscreen, "fullscreen", false, "全屏启动")
+	fs.BoolVar(&o.mute, "mute", false, "本次运行静音")
+	fs.StringVar(&o.lang, "lang", "", "界面语言：zh 或 en")
+	fs.StringVar(&o.topology, "topology", "", "棋盘类型："+strings.Join(squareTopologies(), "、"))
+	fs.StringVar(&o.puzzle, "puzzle", "", "打开谜题文件或 MBF 棋盘文件")
+	fs.StringVar(&o.agent, "agent", "", "接入外部机器人：stdio 通过本进程的标准输入输出交互，否则作为命令启动")
+	fs.StringVar(&o.shape, "shape", "", "棋盘形状："+strings.Join(board.Shapes, "、")+"，或 custom 使用档案目录中的 "+customShapeFile)
+	level := fs.String("log-level", "info", "日志级别：debug、info、warn 或 error")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			o.seedSet = true
+		}
+	})
+
+	if o.difficulty != "" {
+		if _, ok := difficultyByKey(o.difficulty); !ok {
+			return nil, fmt.Errorf("未知难度: %s", o.difficulty)
+		}
+	}
+	if o.width != 0 || o.height != 0 || o.mines != 0 {
+		if o.difficulty != "" {
+			return nil, fmt.Errorf("--difficulty 不能与自定义尺寸同时使用")
+		}
+		if o.width < 5 || o.height < 5 || o.width > maxGridSize || o.height > maxGridSize {
+			return nil, fmt.Errorf("棋盘尺寸需要在 5 到 %d 之间: %dx%d", maxGridSize, o.width, o.height)
+		}
+		// 首次点击周围的 9 格不放地雷
+		if o.mines < 1 || o.mines > o.width*o.height-9 {
+			return nil, fmt.Errorf("地雷数需要在 1 到 %d 之间: %d", o.width*o.height-9, o.mines)
+		}
+	}
+	if o.topology != "" {
+		if _, ok := squareTopology(o.topology); !ok {
+			return nil, fmt.Errorf("未知棋盘类型: %s", o.topology)
+		}
+	}
+	if _, ok := shapeTitles[o.shape]; o.shape != "" && !ok {
+		return nil, fmt.Errorf("未知形状: %s", o.shape)
+	}
+	var err error
+	if o.logLevel, err = parseLogLevel(*level); err != nil {
+		return nil, err
+	}
+	if o.lang != "" {
+		found := false
+		for _, l := range languages {
+			found = found || l.Code == o.lang
+		}
+		if !found {
+			return nil, fmt.Errorf("未知语言: %s", o.lang)
+		}
+	}
+	return o, nil
+}
+
+// 在读取档案后应用启动参数，语言、全屏和静音只在本次运行中生效
+func (o *launchOptions) apply(g *Game) error {
+	if o.lang != "" {
+		langOverride = o.lang
+		setWindowTitle(tr("扫雷游戏"))
+	}
+	muted = o.mute
+	if o.fullscreen {
+		fullscreenOverride = true
+		applyWindowMode()
+	}
+	if err := o.startAgent(); err != nil {
+		return err
+	}
+
+	if o.puzzle != "" {
+		p, err := loadPuzzle(o.puzzle)
+		if err != nil {
+			return err
+		}
+		return g.beginPuzzle(p)
+	}
+
+	d, start := Easy, false
+	if o.difficulty != "" {
+		d, _ = difficultyByKey(o.difficulty)
+		start = true
+	}
+	if o.width != 0 {
+		difficultySettings[Custom] = DifficultyConfig{o.width, o.height, o.mines}
+		d, start = Custom, true
+	}
+	if o.seedSet || o.topology != "" || o.shape != "" || o.agent != "" {
+		start = true
+	}
+	if !start {
+		return nil
+	}
+	t, _ := squareTopology(o.topology)
+	if err := g.newVariantRound(d, t, o.shape); err != nil {
+		return err
+	}
+	if o.seedSet {
+		g.seed = o.seed
+	}
+	return nil
+}
+
+// 外部机器人在整个运行期间保持连接，每局开始时自动接手
+func (o *launchOptions) startAgent() error {
+	switch fields := strings.Fields(o.agent); {
+	case len(fields) == 0:
+	case o.agent == "stdio":
+		externalAgent = agent.Stdio()
+	default:
+		p, err := agent.Command(fields[0], fields[1:]...)
+		if err != nil {
+			return err
+		}
+		externalAgent = p
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestCustomSizeLimit(t *testing.T) {
+	cases := []struct {
+		width, height string
+		ok            bool
+	}{
+		{"5", "5", true},
+		{"128", "128", true},
+		{"129", "10", false},
+		{"10", "129", false},
+		{"500", "500", false},
+		{"4", "10", false},
+	}
+	for _, c := range cases {
+		_, err := parseLaunchOptions([]string{"--width", c.width, "--height", c.height, "--mines", "10"})
+		if (err == nil) != c.ok {
+			t.Errorf("%sx%s: err = %v", c.width, c.height, err)
+		}
+	}
+}
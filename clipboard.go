@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// 通过系统命令读写剪贴板
+func writeClipboard(s string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "clip")
+		// clip 按系统代码页读取输入，带 BOM 的 UTF-16 才能正确复制中文和 emoji
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, append([]uint16{0xfeff}, utf16.Encode([]rune(s))...))
+		cmd.Stdin = &buf
+		return cmd.Run()
+	case "darwin":
+		cmd = exec.Command("pbcopy")
+	default:
+		cmd = exec.Command("xclip", "-selection", "clipboard")
+	}
+	cmd.Stdin = strings.NewReader(s)
+	return cmd.Run()
+}
+
+// 对局结果的文字版，像 Wordle 一样用 emoji 画出结束时的棋盘：
+// 🟩 已翻开，⬜ 未翻开，🚩 旗帜，💣 地雷，💥 踩中的地雷，❌ 插错的旗
+func (g *Game) resultText() string {
+	var b strings.Builder
+	result := "✅"
+	if g.gameOver {
+		result = "💥"
+	}
+	b.WriteString(tr("扫雷游戏") + " " + g.roundTitle())
+	b.WriteString(" " + formatDuration(g.elapsedTime) + " " + result + "\n")
+	for y, row := range g.grid {
+		for x, cell := range row {
+			b.WriteString(resultEmoji(cell, g.exists(x, y), g.gameOver && x == g.explodedX && y == g.explodedY))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func resultEmoji(cell Cell, exists, exploded bool) string {
+	switch {
+	case !exists:
+		return "⬛"
+	case exploded:
+		return "💥"
+	case cell.flagged && !cell.hasMine:
+		return "❌"
+	case cell.flagged:
+		return "🚩"
+	case cell.hasMine && cell.revealed:
+		return "💣"
+	case cell.revealed:
+		return "🟩"
+	}
+	return "⬜"
+}
+
+func (g *Game) copyResult() error {
+	if err := writeClipboard(g.resultText()); err != nil {
+		slog.Warn("复制到剪贴板失败", "err", err)
+		showToast(tr("复制失败"))
+		return nil
+	}
+	showToast(tr("已复制结果"))
+	return nil
+}
+
+func readClipboard() (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", "Get-Clipboard")
+	case "darwin":
+		cmd = exec.Command("pbpaste")
+	default:
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-o")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
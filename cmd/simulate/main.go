@@ -0,0 +1,197 @@
+// simulate 不启动界面，按选定的策略批量模拟对局，输出各难度的胜率和耗时统计。
+//
+// 用法：
+//
+//	go run ./cmd/simulate -n 1000 -difficulty easy,medium -strategy solver,greedy -format csv
+//
+// 指定 -noguess 时按游戏的无猜模式生成棋盘，用于检验无猜生成器：
+// 可解率应接近 1，猜测次数应为 0。
+//
+//	go run ./cmd/simulate -n 200 -strategy solver -noguess subset
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"minesweeper/solver"
+)
+
+// 一种难度和策略组合的统计
+type summary struct {
+	Difficulty string  `json:"difficulty"`
+	Strategy   string  `json:"strategy"`
+	NoGuess    string  `json:"noguess,omitempty"`
+	Games      int     `json:"games"`
+	Wins       int     `json:"wins"`
+	WinRate    float64 `json:"win_rate"`
+	AvgClicks  float64 `json:"avg_clicks"`
+	AvgGuesses float64 `json:"avg_guesses"` // 每局平均猜测次数，不含第一次点击
+	MeanMs     float64 `json:"mean_ms"`
+	P50Ms      float64 `json:"p50_ms"`
+	P95Ms      float64 `json:"p95_ms"`
+	MaxMs      float64 `json:"max_ms"`
+	Distinct   int     `json:"distinct_boards"` // 不同地雷布局的数量，按 board.Hash 区分
+	Solvable   float64 `json:"solvable"`        // 无需猜测即可解开的棋盘比例
+	GenMs      float64 `json:"gen_ms"`          // 生成一个棋盘的平均用时
+}
+
+func main() {
+	log.SetFlags(0)
+	games := flag.Int("n", 1000, "每种难度和策略模拟的局数")
+	difficultyList := flag.String("difficulty", "easy,medium,hard", "难度，逗号分隔: "+strings.Join(difficultyOrder, ","))
+	strategyList := flag.String("strategy", strings.Join(strategyOrder, ","), "策略，逗号分隔: "+strings.Join(strategyOrder, ","))
+	seed := flag.Int64("seed", time.Now().UnixNano(), "随机种子，相同种子得到相同的棋盘序列")
+	format := flag.String("format", "json", "输出格式: json 或 csv")
+	output := flag.String("o", "", "输出文件，默认输出到标准输出")
+	noGuess := flag.String("noguess", "", "按无猜模式生成棋盘: any、trivial、subset 或 deep，默认随机布雷")
+	budget := flag.Duration("budget", 1500*time.Millisecond, "无猜模式生成一个棋盘的时间预算，与游戏相同")
+	flag.Parse()
+
+	if *games <= 0 {
+		log.Fatalf("局数必须大于 0: %d", *games)
+	}
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("未知输出格式: %s", *format)
+	}
+	mode, ok := noGuessModes[*noGuess]
+	if !ok {
+		log.Fatalf("未知无猜模式: %s", *noGuess)
+	}
+	diffs, err := parseList(*difficultyList, func(s string) bool { _, ok := difficulties[s]; return ok })
+	if err != nil {
+		log.Fatalf("难度参数无效: %v", err)
+	}
+	strats, err := parseList(*strategyList, func(s string) bool { _, ok := strategies[s]; return ok })
+	if err != nil {
+		log.Fatalf("策略参数无效: %v", err)
+	}
+
+	var results []summary
+	for _, d := range diffs {
+		for _, s := range strats {
+			start := time.Now()
+			r := simulate(d, s, *games, *seed, mode, *budget)
+			r.NoGuess = *noGuess
+			log.Printf("%s/%s: %d/%d 胜 (%.1f%%)，可解 %.1f%%，用时 %v", d, s, r.Wins, r.Games, r.WinRate*100,
+				r.Solvable*100, time.Since(start).Round(time.Millisecond))
+			results = append(results, r)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("创建输出文件失败: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "csv" {
+		err = writeCSV(w, results)
+	} else {
+		err = writeJSON(w, results)
+	}
+	if err != nil {
+		log.Fatalf("写入结果失败: %v", err)
+	}
+}
+
+// 解析逗号分隔的名称列表，去掉重复项
+func parseList(s string, valid func(string) bool) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if !valid(name) {
+			return nil, fmt.Errorf("未知名称 %q", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("列表为空")
+	}
+	return names, nil
+}
+
+// -noguess 的取值与 solver 中无猜模式的对应关系，any 只要求无需猜测
+var noGuessModes = map[string]string{
+	"":        "",
+	"any":     "any",
+	"trivial": solver.NoGuessTrivial,
+	"subset":  solver.NoGuessSubset,
+	"deep":    solver.NoGuessDeep,
+}
+
+// 用同一组种子模拟每种策略，不同策略面对的是相同的棋盘
+func simulate(diff, strat string, games int, seed int64, noGuess string, budget time.Duration) summary {
+	d, play := difficulties[diff], strategies[strat]
+	r := summary{Difficulty: diff, Strategy: strat, Games: games}
+	durations := make([]time.Duration, games)
+	clicks, guesses, solvable := 0, 0, 0
+	var total, generate time.Duration
+	boards := make(map[string]bool)
+	for i := 0; i < games; i++ {
+		g := playGame(d, seed+int64(i), play, noGuess, budget)
+		boards[g.hash] = true
+		if g.won {
+			r.Wins++
+		}
+		clicks += g.clicks
+		guesses += g.guesses
+		if g.solvable {
+			solvable++
+		}
+		durations[i] = g.duration
+		total += g.duration
+		generate += g.generate
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	r.WinRate = float64(r.Wins) / float64(games)
+	r.AvgClicks = float64(clicks) / float64(games)
+	r.AvgGuesses = float64(guesses) / float64(games)
+	r.MeanMs = ms(total / time.Duration(games))
+	r.P50Ms = ms(durations[games/2])
+	r.P95Ms = ms(durations[games*95/100])
+	r.MaxMs = ms(durations[games-1])
+	r.Distinct = len(boards)
+	r.Solvable = float64(solvable) / float64(games)
+	r.GenMs = ms(generate / time.Duration(games))
+	return r
+}
+
+func writeJSON(w io.Writer, results []summary) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func writeCSV(w io.Writer, results []summary) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"difficulty", "strategy", "noguess", "games", "wins", "win_rate", "avg_clicks", "avg_guesses",
+		"mean_ms", "p50_ms", "p95_ms", "max_ms", "distinct_boards", "solvable", "gen_ms"})
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	for _, r := range results {
+		cw.Write([]string{r.Difficulty, r.Strategy, r.NoGuess, strconv.Itoa(r.Games), strconv.Itoa(r.Wins), f(r.WinRate),
+			f(r.AvgClicks), f(r.AvgGuesses), f(r.MeanMs), f(r.P50Ms), f(r.P95Ms), f(r.MaxMs), strconv.Itoa(r.Distinct),
+			f(r.Solvable), f(r.GenMs)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
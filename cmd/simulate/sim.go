@@ -0,0 +1,215 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"minesweeper/board"
+	"minesweeper/solver"
+)
+
+type difficulty struct {
+	width, height, mines int
+}
+
+// 与游戏中的难度配置保持一致
+var difficulties = map[string]difficulty{
+	"easy":   {9, 9, 10},
+	"medium": {16, 16, 40},
+	"hard":   {30, 16, 99},
+	"huge":   {100, 100, 1000},
+}
+
+var difficultyOrder = []string{"easy", "medium", "hard", "huge"}
+
+// 一局模拟对局，只通过 view 向策略暴露玩家可见的信息
+type sim struct {
+	width, height int
+	mines         [][]bool
+	counts        [][]int
+	view          *solver.View
+	revealed      int
+	safeCells     int
+	rng           *rand.Rand
+	clicks        int
+	guesses       int
+}
+
+// 策略返回下一步要翻开的格子，guess 为 true 表示无法确定安全，只能猜测
+type strategy func(s *sim) (cells []solver.Point, guess bool)
+
+var strategies = map[string]strategy{
+	"random": randomStrategy,
+	"solver": solverStrategy,
+	"greedy": greedyStrategy,
+}
+
+var strategyOrder = []string{"random", "solver", "greedy"}
+
+type gameResult struct {
+	won      bool
+	clicks   int
+	guesses  int
+	duration time.Duration // 下完一局的用时，不含生成棋盘
+	generate time.Duration // 生成棋盘的用时
+	solvable bool          // 从第一次点击开始无需猜测即可解开
+	hash     string        // 地雷布局的哈希
+}
+
+// 按 seed 布雷并用策略下完一局。与游戏相同，第一次点击的格子及周围不会有地雷。
+// noGuess 不为空时与游戏的无猜模式一样，在 budget 内寻找符合推理深度的布局
+func playGame(d difficulty, seed int64, play strategy, noGuess string, budget time.Duration) gameResult {
+	rng := board.NewRand(seed)
+	firstX, firstY := rng.IntN(d.width), rng.IntN(d.height)
+	first := solver.Point{X: firstX, Y: firstY}
+	start := time.Now()
+	layout := rng.Int64()
+	if noGuess != "" {
+		layout, _ = solver.NoGuessSeed(d.width, d.height, d.mines, layout, first, noGuess, budget)
+	}
+	mines := board.PlaceMines(d.width, d.height, d.mines, layout, firstX, firstY)
+	generate := time.Since(start)
+	solvable := solver.Play(mines, first).Solved
+
+	start = time.Now()
+	s := &sim{
+		width:     d.width,
+		height:    d.height,
+		mines:     mines,
+		counts:    board.CountNeighbors(mines),
+		view:      solver.NewView(d.width, d.height),
+		safeCells: d.width*d.height - d.mines,
+		rng:       rng,
+	}
+
+	won := s.open(first)
+	for won && s.revealed < s.safeCells {
+		cells, guess := play(s)
+		if guess {
+			s.guesses++
+		}
+		for _, p := range cells {
+			if !s.open(p) {
+				won = false
+				break
+			}
+		}
+	}
+	return gameResult{won: won, clicks: s.clicks, guesses: s.guesses, duration: time.Since(start),
+		generate: generate, solvable: solvable, hash: board.Hash(mines)}
+}
+
+// 翻开一个格子，踩到地雷时返回 false
+func (s *sim) open(p solver.Point) bool {
+	if s.view.At(p.X, p.Y) != solver.Unknown {
+		return true
+	}
+	s.clicks++
+	if s.mines[p.Y][p.X] {
+		return false
+	}
+	board.FloodFill(s.width, s.height, p.X, p.Y, func(x, y int) bool {
+		if s.view.At(x, y) != solver.Unknown || s.mines[y][x] {
+			return false
+		}
+		s.view.Set(x, y, s.counts[y][x])
+		s.revealed++
+		return s.counts[y][x] == 0
+	})
+	return true
+}
+
+// 所有未翻开且不在 exclude 中的格子
+func (s *sim) unknown(exclude []solver.Point) []solver.Point {
+	skip := make(map[solver.Point]bool, len(exclude))
+	for _, p := range exclude {
+		skip[p] = true
+	}
+	var cells []solver.Point
+	for y := 0; y < s.height; y++ {
+		for x := 0; x < s.width; x++ {
+			p := solver.Point{X: x, Y: y}
+			if s.view.At(x, y) == solver.Unknown && !skip[p] {
+				cells = append(cells, p)
+			}
+		}
+	}
+	return cells
+}
+
+// 每步随机翻开一个未翻开的格子
+func randomStrategy(s *sim) ([]solver.Point, bool) {
+	cells := s.unknown(nil)
+	return []solver.Point{cells[s.rng.IntN(len(cells))]}, true
+}
+
+// 先翻开推理出的全部安全格子，无法推理时避开确定的地雷随机猜测
+func solverStrategy(s *sim) ([]solver.Point, bool) {
+	result := solver.Deduce(s.view)
+	if len(result.Safe) > 0 {
+		return result.Safe, false
+	}
+	cells := s.unknown(result.Mines)
+	return []solver.Point{cells[s.rng.IntN(len(cells))]}, true
+}
+
+// 与 solver 相同，但猜测时选择估计地雷概率最低的格子
+func greedyStrategy(s *sim) ([]solver.Point, bool) {
+	result := solver.Deduce(s.view)
+	if len(result.Safe) > 0 {
+		return result.Safe, false
+	}
+	cells := s.unknown(result.Mines)
+	return []solver.Point{s.safestGuess(cells, result.Mines)}, true
+}
+
+// 估计每个候选格子的地雷概率：取周围各个数字给出的概率中的最大值，
+// 不与任何数字相邻的格子使用剩余地雷的平均密度。概率相同时随机选择
+func (s *sim) safestGuess(cells, known []solver.Point) solver.Point {
+	mines := make(map[solver.Point]bool, len(known))
+	for _, p := range known {
+		mines[p] = true
+	}
+
+	density := float64(s.width*s.height-s.safeCells-len(known)) / float64(len(cells))
+	best, bestProb, ties := cells[0], 2.0, 0
+	for _, p := range cells {
+		prob, constrained := 0.0, false
+		for _, n := range s.view.Neighbors(p.X, p.Y) {
+			number := s.view.At(n.X, n.Y)
+			if number == solver.Unknown {
+				continue
+			}
+			remaining, unknown := number, 0
+			for _, q := range s.view.Neighbors(n.X, n.Y) {
+				switch {
+				case mines[q]:
+					remaining--
+				case s.view.At(q.X, q.Y) == solver.Unknown:
+					unknown++
+				}
+			}
+			if unknown > 0 {
+				constrained = true
+				if pr := float64(remaining) / float64(unknown); pr > prob {
+					prob = pr
+				}
+			}
+		}
+		if !constrained {
+			prob = density
+		}
+
+		switch {
+		case prob < bestProb:
+			best, bestProb, ties = p, prob, 1
+		case prob == bestProb:
+			// 蓄水池抽样，在概率相同的格子中等概率选择
+			ties++
+			if s.rng.IntN(ties) == 0 {
+				best = p
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"minesweeper/board"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 游戏中的一个动作。命令面板、快捷键和菜单按钮都通过命令表执行，
+// 新增动作只需在 commandList 中登记一次
+type command struct {
+	id      string
+	title   string     // 显示名称，中文，显示时经过 tr
+	key     ebiten.Key // 快捷键，noKey 表示没有
+	ctrl    bool       // 快捷键需要同时按住 Ctrl
+	playing bool       // 只在对局界面可用
+	enabled func(g *Game) bool
+	run     func(g *Game) error
+}
+
+const noKey ebiten.Key = -1
+
+func commandList() []*command {
+	scene := func(s Scene) func(g *Game) error {
+		return func(g *Game) error {
+			g.switchScene(s)
+			return nil
+		}
+	}
+	shapeRound := func(shape string) func(g *Game) error {
+		return func(g *Game) error {
+			return g.newVariantRound(g.difficulty, board.Square8{}, shape)
+		}
+	}
+	difficulty := func(d Difficulty) func(g *Game) error {
+		return func(g *Game) error {
+			return g.confirmAbandon(func() error { return g.newRound(d) })
+		}
+	}
+
+	return []*command{
+		{id: "restart", title: "重启", key: noKey, playing: true, run: func(g *Game) error { // 按键见 input.go
+			// 破纪录进行中的对局需要再按一次确认，防止误触
+			if g.onRecordPace() && time.Now().After(g.restartConfirmUntil) {
+				g.restartConfirmUntil = time.Now().Add(time.Second)
+				return nil
+			}
+			return g.confirmAbandon(g.restartRound)
+		}},
+		{id: "pause", title: "暂停", key: noKey, playing: true,
+			enabled: func(g *Game) bool { return g.pauseAllowed() },
+			run:     func(g *Game) error { return g.togglePause() }},
+		{id: "controls", title: "操作", key: noKey, run: scene(SceneControls)},
+		{id: "difficulty", title: "难度", key: noKey, run: func(g *Game) error {
+			g.switchScene(ScenePlaying)
+			g.showingDifficultyMenu = true
+			return nil
+		}},
+		{id: "easy", title: "简单模式", key: noKey, run: difficulty(Easy)},
+		{id: "medium", title: "中等模式", key: noKey, run: difficulty(Medium)},
+		{id: "hard", title: "困难模式", key: noKey, run: difficulty(Hard)},
+		{id: "huge", title: "巨大模式", key: noKey, run: difficulty(Huge)},
+		{id: "theme", title: "切换主题", key: noKey, run: func(g *Game) error {
+			// 跳过尚未解锁的主题
+			var names []string
+			for _, name := range themeNames() {
+				if cosmeticUnlocked(themes[name].Unlock) {
+					names = append(names, name)
+				}
+			}
+			next := 0
+			for i, name := range names {
+				if name == globalConfig.Theme {
+					next = (i + 1) % len(names)
+				}
+			}
+			globalConfig.Theme = names[next]
+			g.applySettings()
+			return nil
+		}},
+		{id: "copy-seed", title: "复制种子", key: noKey, playing: true,
+			enabled: func(g *Game) bool { return g.minesPlaced },
+			run: func(g *Game) error {
+				r := g.currentSeed()
+				if err := writeClipboard(r.code()); err != nil {
+					slog.Warn("复制到剪贴板失败", "err", err)
+					showToast(r.code())
+					return nil
+				}
+				showToast(tr("已复制种子"))
+				return nil
+			}},
+		{id: "review", title: "复盘", key: noKey, playing: true,
+			enabled: func(g *Game) bool { return g.gameOver || g.won },
+			run: func(g *Game) error {
+				g.toggleReview()
+				return nil
+			}},
+		{id: "menu", title: "主菜单", key: noKey, run: scene(SceneMainMenu)},
+		{id: "stats", title: "统计", key: noKey, run: scene(SceneStats)},
+		{id: "settings", title: "设置", key: noKey, run: scene(SceneSettings)},
+		{id: "history", title: "历史", key: noKey, run: scene(SceneSeeds)},
+		{id: "challenge", title: "挑战", key: noKey, run: scene(SceneChallenge)},
+		{id: "race", title: "对战", key: noKey, run: scene(SceneRace)},
+		{id: "endless", title: "无尽", key: noKey, run: scene(SceneEndless)},
+		{id: "hex", title: "六边形", key: noKey, run: scene(SceneHex)},
+		{id: "torus", title: "环面", key: noKey, run: func(g *Game) error {
+			return g.newVariantRound(g.difficulty, board.Torus{}, "")
+		}},
+		{id: "knight", title: "马步", key: noKey, run: func(g *Game) error {
+			return g.newVariantRound(g.difficulty, board.Knight{}, "")
+		}},
+		{id: "heart", title: "爱心", key: noKey, run: shapeRound("heart")},
+		{id: "diamond", title: "菱形", key: noKey, run: shapeRound("diamond")},
+		{id: "ring", title: "圆环", key: noKey, run: shapeRound("ring")},
+		{id: "custom-shape", title: "自定义形状", key: noKey, run: shapeRound("custom")},
+		{id: "puzzles", title: "谜题", key: noKey, run: func(g *Game) error {
+			puzzleList.packs = nil
+			g.switchScene(ScenePuzzles)
+			return nil
+		}},
+		{id: "drills", title: "图案练习", key: noKey, run: scene(SceneDrill)},
+		{id: "arcade", title: "街机模式", key: noKey, run: scene(SceneArcade)},
+		{id: "achievements", title: "成就", key: noKey, run: scene(SceneAchievements)},
+		{id: "cosmetics", title: "外观", key: noKey, run: scene(SceneCosmetics)},
+		{id: "editor", title: "谜题编辑器", key: noKey, run: func(g *Game) error { return g.openEditor() }},
+		{id: "square4", title: "四邻格", key: noKey, run: func(g *Game) error {
+			return g.newVariantRound(g.difficulty, board.Square4{}, "")
+		}},
+		{id: "scoreboard", title: "计分板", key: noKey, run: scene(SceneScoreboard)},
+		{id: "scoreboard-panel", title: "显示计分板", key: ebiten.KeyF9, run: func(g *Game) error {
+			globalScoreboard.Active = !globalScoreboard.Active
+			return globalScoreboard.save()
+		}},
+		{id: "next-player", title: "下一位", key: noKey,
+			enabled: func(g *Game) bool { return len(globalScoreboard.Players) > 1 },
+			run: func(g *Game) error {
+				globalScoreboard.next()
+				showToast(tr("下一位") + ": " + globalScoreboard.current().Name)
+				return globalScoreboard.save()
+			}},
+		{id: "fullscreen", title: "全屏", key: ebiten.KeyF11, run: func(g *Game) error {
+			setFullscreen(!fullscreen())
+			g.applySettings()
+			return nil
+		}},
+		{id: "debug", title: "调试模式", key: ebiten.KeyBackquote, run: func(g *Game) error {
+			debugMode = !debugMode
+			return nil
+		}},
+		{id: "trace", title: "录制追踪", key: ebiten.KeyT, ctrl: true,
+			enabled: func(g *Game) bool { return debugMode },
+			run: func(g *Game) error {
+				prof.toggleTrace(g.store)
+				return nil
+			}},
+		{id: "bot", title: "观看机器人", key: ebiten.KeyF6, playing: true, run: func(g *Game) error { return g.toggleBot() }},
+		{id: "bot-slower", title: "机器人减速", key: ebiten.KeyBracketLeft, playing: true, enabled: botActive, run: func(g *Game) error { return g.changeBotSpeed(-1) }},
+		{id: "bot-faster", title: "机器人加速", key: ebiten.KeyBracketRight, playing: true, enabled: botActive, run: func(g *Game) error { return g.changeBotSpeed(1) }},
+		{id: "retry", title: "重试此局", key: ebiten.KeyF3, playing: true,
+			enabled: func(g *Game) bool { return g.minesPlaced },
+			run:     func(g *Game) error { return g.retryBoard() }},
+		{id: "probability", title: "地雷概率", key: ebiten.KeyF4, playing: true, run: func(g *Game) error { return g.toggleProbability() }},
+		{id: "zoom-in", title: "放大", key: ebiten.KeyEqual, ctrl: true, run: func(g *Game) error { return g.zoom(1) }},
+		{id: "zoom-out", title: "缩小", key: ebiten.KeyMinus, ctrl: true, run: func(g *Game) error { return g.zoom(-1) }},
+		{id: "zoom-reset", title: "原始大小", key: ebiten.Key0, ctrl: true, run: func(g *Game) error { return g.zoom(0) }},
+		{id: "copy-result", title: "复制结果", key: ebiten.KeyC, ctrl: true, playing: true,
+			enabled: func(g *Game) bool { return g.gameOver || g.won },
+			run:     func(g *Game) error { return g.copyResult() }},
+		{id: "export-gif", title: "导出 GIF", key: noKey, playing: true,
+			enabled: func(g *Game) bool { return g.exportAllowed() },
+			run:     func(g *Game) error { return g.exportGIF() }},
+		{id: "export-board", title: "导出棋盘", key: noKey, playing: true,
+			enabled: func(g *Game) bool { return g.minesPlaced && !g.gameOver && !g.won },
+			run:     func(g *Game) error { return g.exportBoardState() }},
+		{id: "export-mbf", title: "导出 MBF", key: noKey, playing: true,
+			enabled: func(g *Game) bool { return g.mbfAllowed() },
+			run:     func(g *Game) error { return g.exportMBF() }},
+		{id: "screenshot", title: "截图", key: ebiten.KeyF12, run: func(g *Game) error { return g.requestScreenshot() }},
+		{id: "bug-report", title: "报告问题", key: noKey, run: func(g *Game) error { return g.reportBug() }},
+		{id: "palette", title: "命令面板", key: ebiten.KeyP, ctrl: true, run: func(g *Game) error {
+			g.openPalette()
+			return nil
+		}},
+	}
+}
+
+var commands []*command
+
+// 命令的动作会间接引用命令表，不能在变量声明时直接初始化
+func init() {
+	commands = commandList()
+}
+
+func findCommand(id string) *command {
+	for _, c := range commands {
+		if c.id == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *command) available(g *Game) bool {
+	if c.playing && g.scene != ScenePlaying {
+		return false
+	}
+	return c.enabled == nil || c.enabled(g)
+}
+
+func (g *Game) runCommand(id string) error {
+	c := findCommand(id)
+	if c == nil || !c.available(g) {
+		return nil
+	}
+	return c.run(g)
+}
+
+// 本帧是否按下了命令的快捷键，可以重新绑定的命令按配置中的绑定，见 input.go
+func (c *command) pressed() bool {
+	if b, ok := actionBinding(c.id); ok {
+		return b.justPressed()
+	}
+	return c.key != noKey && inpututil.IsKeyJustPressed(c.key)
+}
+
+// 执行本帧按下快捷键的命令。playing 为 false 时只处理不限界面的命令
+func (g *Game) runHotkeys(playing bool) (bool, error) {
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
+	for _, c := range commands {
+		if c.playing != playing || c.ctrl != ctrl || !c.pressed() {
+			continue
+		}
+		if !c.available(g) {
+			continue
+		}
+		return true, c.run(g)
+	}
+	return false, nil
+}
+
+// 执行命令的菜单按钮
+func (g *Game) commandButton(id string) *menuButton {
+	c := findCommand(id)
+	return &menuButton{Button: &Button{Text: c.title}, action: func() error {
+		return g.runCommand(id)
+	}}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"minesweeper/storage"
+)
+
+// 用户配置
+type Config struct {
+	Theme             string  `json:"theme"`
+	FlagSkin          string  `json:"flag_skin"`       // 旗帜样式，见 cosmetics.go
+	SoundPack         string  `json:"sound_pack"`      // 音效包，见 cosmetics.go
+	SeasonalThemes    bool    `json:"seasonal_themes"` // 在对应日期自动启用季节主题
+	Volume            float64 `json:"volume"`          // 音效音量 0~1
+	Language          string  `json:"language"`
+	QuestionMarks     bool    `json:"question_marks"`   // 右键在旗帜之后再标记问号
+	SafeFirstClick    bool    `json:"safe_first_click"` // 首次点击及周围不放置地雷
+	SafeStart         string  `json:"safe_start"`       // 首次点击的安全范围，见 safestart.go
+	Animations        bool    `json:"animations"`
+	ProgressiveReveal bool    `json:"progressive_reveal"` // 连锁翻开的格子从点击处逐圈向外显示，仅影响显示，见 wave.go
+	EndlessDensity    float64 `json:"endless_density"`    // 无尽模式的地雷密度
+	GifSpeed          float64 `json:"gif_speed"`          // 导出 GIF 时相对实际用时的倍速，见 gifexport.go
+	Fullscreen        bool    `json:"fullscreen"`
+	Borderless        bool    `json:"borderless"`       // 窗口模式下不显示标题栏和边框
+	CoordLabels       bool    `json:"coord_labels"`     // 棋盘边缘显示列名和行号
+	SwapButtons       bool    `json:"swap_buttons"`     // 左手模式，交换鼠标左右键
+	ConfirmRestart    bool    `json:"confirm_restart"`  // 破纪录进行中按重启键需要再按一次确认
+	Radar             bool    `json:"radar"`            // 按住空格短暂标出可确定安全的格子，见 radar.go
+	SessionRecap      bool    `json:"session_recap"`    // 玩过多局后退出时显示本次小结
+	NoGuess           string  `json:"no_guess"`         // 无猜模式要求的推理深度，空表示关闭
+	Lives             int     `json:"lives"`            // 轻松模式每局可以踩雷的次数，0 表示关闭，见 lives.go
+	NoFlags           bool    `json:"no_flags"`         // 无旗模式（NF），禁止插旗和问号，成绩单独记录
+	Countdown         bool    `json:"countdown"`        // 限时模式，见 countdown.go
+	Ghost             bool    `json:"ghost"`            // 状态栏上标出个人最佳对局的进度，见 splits.go
+	DiscordPresence   bool    `json:"discord_presence"` // 在 Discord 中显示当前对局状态
+	ObserverOutput    bool    `json:"observer_output"`  // 输出不含地雷位置的棋盘状态，供直播叠加层读取
+	ObserverPort      int     `json:"observer_port"`    // 观战接口的本机端口，0 表示默认端口
+
+	LastSeenVersion string   `json:"last_seen_version"` // 已查看过更新内容的版本
+	LastRaceAddr    string   `json:"last_race_addr"`    // 上次加入对战时输入的地址
+	ChallengeServer string   `json:"challenge_server"`  // 排行榜服务器地址，为空时挑战使用离线棋盘
+	SyncServer      string   `json:"sync_server"`       // 存档同步服务器地址，为空时只保存在本机，见 storage.Sync
+	PlayerName      string   `json:"player_name"`       // 排行榜上显示的名字，为空时使用系统用户名
+	Rivals          []string `json:"rivals"`            // 标记为对手的排行榜玩家
+
+	Windows     map[string]windowPreset `json:"windows,omitempty"`     // 按难度或棋盘大小记录的窗口大小，见 windowsize.go
+	Window      *windowGeometry         `json:"window,omitempty"`      // 上次退出时窗口的位置和大小
+	Bindings    map[string]string       `json:"bindings,omitempty"`    // 重新绑定的操作，见 input.go
+	Experiments map[string]bool         `json:"experiments,omitempty"` // 开启的实验性功能，见 flags.go
+
+	store storage.Storage
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Theme:             "default",
+		FlagSkin:          "red",
+		SoundPack:         "classic",
+		Volume:            1,
+		Language:          "zh",
+		SafeFirstClick:    true,
+		SafeStart:         safeStartOpening,
+		Animations:        true,
+		ProgressiveReveal: true,
+		ConfirmRestart:    true,
+		SessionRecap:      true,
+		EndlessDensity:    0.15,
+		GifSpeed:          2,
+	}
+}
+
+// 当前档案名称，为空时使用默认档案
+var profile string
+
+// 配置及数据文件所在目录，每个档案互相独立
+func dataDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if profile != "" {
+		return filepath.Join(dir, "minesweeper", "profiles", profile), nil
+	}
+	return filepath.Join(dir, "minesweeper"), nil
+}
+
+func loadConfig(store storage.Storage) (*Config, error) {
+	cfg := defaultConfig()
+	cfg.store = store
+
+	err := loadFile(store, "config.json", "config", cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		cfg = defaultConfig()
+		cfg.store = store
+		return cfg, fmt.Errorf("读取配置失败: %v", err)
+	}
+	return cfg, nil
+}
+
+// 没有加载档案时使用的默认配置不保存
+func saveConfig(cfg *Config) error {
+	if cfg.store == nil {
+		return nil
+	}
+	return saveFile(cfg.store, "config.json", "config", cfg)
+}
+
+// 修改当前配置，并且只把这部分修改写入档案：重新读取已保存的配置，
+// 应用同样的修改后保存，用于窗口大小等随时自动保存的项目
+func patchConfig(patch func(cfg *Config)) error {
+	patch(globalConfig)
+	if globalConfig.store == nil {
+		return nil
+	}
+	saved, err := loadConfig(globalConfig.store)
+	if err != nil {
+		return err
+	}
+	patch(saved)
+	return saveConfig(saved)
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"minesweeper/board"
+	"minesweeper/netplay"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 拆弹模式中的角色，双方看到的信息互补
+type coopRole int
+
+const (
+	roleNone    coopRole = iota // 普通合作，信息完整
+	roleCounter                 // 能看到数字，看不到探测图
+	roleScanner                 // 能看到带噪声的探测图，翻开的格子不显示数字
+)
+
+// 探测图的噪声：地雷被探测到的概率和安全格子误报的概率
+const (
+	scannerHitRate   = 0.85
+	scannerFalseRate = 0.12
+)
+
+const (
+	pingDuration = 3 * time.Second
+	chatDuration = 8 * time.Second
+	chatMaxLen   = 60
+	chatLines    = 4
+)
+
+// 玩家颜色，按编号循环使用
+var playerColors = []color.RGBA{
+	{0, 160, 255, 255},
+	{255, 140, 0, 255},
+	{40, 190, 80, 255},
+	{190, 70, 220, 255},
+	{230, 50, 90, 255},
+	{0, 190, 190, 255},
+}
+
+func playerColor(id int) color.RGBA {
+	return playerColors[id%len(playerColors)]
+}
+
+// 合作状态。主机是权威方：加入方只发送操作请求，
+// 主机按收到的顺序检查并执行，再广播给所有人，各方按相同顺序应用，棋盘始终一致。
+type coopState struct {
+	conn    *netplay.Conn // 加入方：与主机的连接
+	peers   []coopPeer    // 主机：所有加入方
+	self    int           // 自己的编号，主机为 0
+	role    coopRole
+	signal  [][]bool // 探测图，仅探测员绘制
+	owners  [][]int  // 插旗的玩家编号，-1 表示无人
+	cursors map[int]cellPos
+	hover   cellPos // 上次发送的光标位置
+	pings   []cellPing
+	chat    []chatLine
+	typing  bool
+	input   string
+}
+
+type coopPeer struct {
+	id   int
+	conn *netplay.Conn
+}
+
+type cellPos struct{ x, y int }
+
+type cellPing struct {
+	x, y   int
+	at     time.Time
+	player int
+}
+
+type chatLine struct {
+	text   string
+	at     time.Time
+	player int
+}
+
+func newCoopState(g *Game, mode string, self int) *coopState {
+	c := &coopState{self: self, cursors: map[int]cellPos{}, hover: cellPos{-1, -1}}
+	c.owners = make([][]int, len(g.grid))
+	for y := range g.grid {
+		c.owners[y] = make([]int, len(g.grid[y]))
+		for x := range c.owners[y] {
+			c.owners[y][x] = -1
+		}
+	}
+	// 拆弹模式下主机负责看数字，加入方负责探测
+	if mode == netplay.ModeDefuse {
+		c.role = roleCounter
+		if self != 0 {
+			c.role = roleScanner
+			c.signal = g.scannerSignal()
+		}
+	}
+	return c
+}
+
+func newCoopHost(g *Game, mode string, conns []*netplay.Conn) *coopState {
+	c := newCoopState(g, mode, 0)
+	for i, conn := range conns {
+		c.peers = append(c.peers, coopPeer{id: i + 1, conn: conn})
+	}
+	return c
+}
+
+func newCoopClient(g *Game, mode string, conn *netplay.Conn, self int) *coopState {
+	c := newCoopState(g, mode, self)
+	c.conn = conn
+	return c
+}
+
+// 主机在玩家到齐后开始合作对局，给每个玩家分配编号
+func (g *Game) beginCoopHost() error {
+	peers := g.lobby.peers
+	g.lobby.peers = nil
+	start := g.startMessage(netplay.ModeCoop)
+	for i, conn := range peers {
+		m := start
+		m.Player = i + 1
+		conn.Send(m)
+	}
+	if err := g.startBoard(start); err != nil {
+		for _, conn := range peers {
+			conn.Close()
+		}
+		return err
+	}
+	g.coop = newCoopHost(g, start.Mode, peers)
+	return nil
+}
+
+func (g *Game) endCoop() {
+	c := g.coop
+	if c == nil {
+		return
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	for _, p := range c.peers {
+		p.conn.Close()
+	}
+	g.coop = nil
+}
+
+func (c *coopState) isHost() bool {
+	return c.conn == nil
+}
+
+// 主机向除 except 以外的所有加入方广播
+func (c *coopState) broadcast(m netplay.Message, except int) {
+	for _, p := range c.peers {
+		if p.id != except {
+			p.conn.Send(m)
+		}
+	}
+}
+
+// 发出自己的消息：加入方发给主机，主机直接广播
+func (c *coopState) send(m netplay.Message) {
+	m.Player = c.self
+	if c.isHost() {
+		c.broadcast(m, -1)
+	} else {
+		c.conn.Send(m)
+	}
+}
+
+// 根据种子生成探测图，双方结果一致
+func (g *Game) scannerSignal() [][]bool {
+	rng := board.NewRand(g.seed + 1)
+	signal := make([][]bool, len(g.grid))
+	for y, row := range g.grid {
+		signal[y] = make([]bool, len(row))
+		for x, cell := range row {
+			if cell.hasMine {
+				signal[y][x] = rng.Float64() < scannerHitRate
+			} else {
+				signal[y][x] = rng.Float64() < scannerFalseRate
+			}
+		}
+	}
+	return signal
+}
+
+// 格子当前的标记：0 无，1 旗帜，2 问号
+func markState(cell Cell) int {
+	switch {
+	case cell.flagged:
+		return 1
+	case cell.questioned:
+		return 2
+	}
+	return 0
+}
+
+// 本地玩家的翻开或标记操作。合作中的加入方只发送请求，等主机确认后再执行
+func (g *Game) act(kind moveKind, x, y int) {
+	c := g.coop
+	if c != nil && !c.isHost() {
+		c.send(actionMessage(kind, x, y, markState(g.grid[y][x])))
+		return
+	}
+	g.applyAction(kind, x, y, 0)
+	if c != nil {
+		c.send(actionMessage(kind, x, y, 0))
+	}
+}
+
+func actionMessage(kind moveKind, x, y, mark int) netplay.Message {
+	t := netplay.TypeReveal
+	if kind == moveFlag {
+		t = netplay.TypeMark
+	}
+	return netplay.Message{Type: t, X: x, Y: y, Mark: mark}
+}
+
+func (g *Game) applyAction(kind moveKind, x, y, player int) {
+	if kind == moveReveal {
+		g.revealAt(x, y)
+		return
+	}
+	g.markAt(x, y)
+	if c := g.coop; c != nil {
+		c.owners[y][x] = -1
+		if g.grid[y][x].flagged {
+			c.owners[y][x] = player
+		}
+	}
+}
+
+// 主机检查加入方的请求在当前棋盘上是否仍然有效。
+// 同时点击同一格时先到者生效，后到的请求看到的状态已经过时，直接丢弃。
+func (g *Game) validAction(m netplay.Message) bool {
+	if g.gameOver || g.won {
+		return false
+	}
+	cell := g.grid[m.Y][m.X]
+	switch m.Type {
+	case netplay.TypeReveal:
+		return !cell.revealed && !cell.flagged
+	case netplay.TypeMark:
+		return !cell.revealed && markState(cell) == m.Mark
+	}
+	return false
+}
+
+// 处理收到的消息
+func (g *Game) updateCoop() {
+	c := g.coop
+	if c == nil {
+		return
+	}
+
+	if c.isHost() {
+		alive := c.peers[:0]
+		for _, p := range c.peers {
+			for {
+				m, ok := p.conn.Poll()
+				if !ok {
+					break
+				}
+				m.Player = p.id
+				g.handleCoopMessage(m, true)
+			}
+			if p.conn.Closed() {
+				showToast(fmt.Sprintf(tr("玩家 %d 已离开"), p.id+1))
+				delete(c.cursors, p.id)
+				continue
+			}
+			alive = append(alive, p)
+		}
+		c.peers = alive
+	} else {
+		for {
+			m, ok := c.conn.Poll()
+			if !ok {
+				break
+			}
+			g.handleCoopMessage(m, false)
+		}
+		if c.conn.Closed() {
+			showToast(tr("与主机的连接已断开"))
+			g.coop = nil
+			return
+		}
+	}
+	g.checkWin()
+
+	// 光标移到新的格子时通知其他人
+	pos := cellPos{-1, -1}
+	if x, y, ok := g.cellAt(cursorPosition()); ok {
+		pos = cellPos{x, y}
+	}
+	if pos != c.hover {
+		c.hover = pos
+		c.send(netplay.Message{Type: netplay.TypeCursor, X: pos.x, Y: pos.y})
+	}
+}
+
+func (g *Game) onBoard(x, y int) bool {
+	return y >= 0 && y < len(g.grid) && x >= 0 && x < len(g.grid[y])
+}
+
+// relay 为 true 表示主机收到加入方的消息，需要检查后转发
+func (g *Game) handleCoopMessage(m netplay.Message, relay bool) {
+	c := g.coop
+	switch m.Type {
+	case netplay.TypeReveal, netplay.TypeMark:
+		if !g.onBoard(m.X, m.Y) {
+			return
+		}
+		if relay {
+			if !g.validAction(m) {
+				return
+			}
+			c.broadcast(m, -1)
+		} else if g.gameOver || g.won {
+			return
+		}
+		kind := moveReveal
+		if m.Type == netplay.TypeMark {
+			kind = moveFlag
+		}
+		g.applyAction(kind, m.X, m.Y, m.Player)
+		return
+	case netplay.TypePing:
+		if g.onBoard(m.X, m.Y) {
+			g.addPing(m.X, m.Y, m.Player)
+		}
+	case netplay.TypeChat:
+		c.addChat(m.Text, m.Player)
+	case netplay.TypeCursor:
+		if g.onBoard(m.X, m.Y) {
+			c.cursors[m.Player] = cellPos{m.X, m.Y}
+		} else {
+			delete(c.cursors, m.Player)
+		}
+	default:
+		return
+	}
+	if relay {
+		c.broadcast(m, m.Player)
+	}
+}
+
+// 提示标记只用于交流，与旗帜互不影响。同一玩家的新提示会替换旧的
+func (g *Game) addPing(x, y, player int) {
+	c := g.coop
+	pings := c.pings[:0]
+	for _, p := range c.pings {
+		if p.player != player {
+			pings = append(pings, p)
+		}
+	}
+	c.pings = append(pings, cellPing{x: x, y: y, at: time.Now(), player: player})
+	g.playSound("ping")
+}
+
+func (c *coopState) addChat(msg string, player int) {
+	c.chat = append(c.chat, chatLine{text: msg, at: time.Now(), player: player})
+	if len(c.chat) > chatLines {
+		c.chat = c.chat[len(c.chat)-chatLines:]
+	}
+}
+
+// 处理聊天输入和 Alt+点击提示。返回 true 表示本帧的输入已被消耗，不再处理其它按键和点击
+func (g *Game) updateCoopInput() bool {
+	c := g.coop
+	if c == nil {
+		return false
+	}
+
+	if !c.typing {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			c.typing = true
+			c.input = ""
+			return true
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyAlt) &&
+			(inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)) {
+			if gridX, gridY, ok := g.cellAt(cursorPosition()); ok {
+				g.addPing(gridX, gridY, c.self)
+				c.send(netplay.Message{Type: netplay.TypePing, X: gridX, Y: gridY})
+			}
+			return true
+		}
+		return false
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len([]rune(c.input)) < chatMaxLen {
+			c.input += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && c.input != "" {
+		runes := []rune(c.input)
+		c.input = string(runes[:len(runes)-1])
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		c.typing = false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		c.typing = false
+		if c.input != "" {
+			c.addChat(c.input, c.self)
+			c.send(netplay.Message{Type: netplay.TypeChat, Text: c.input})
+		}
+	}
+	return true
+}
+
+// 探测员看不到数字，数字员看不到探测图
+func (g *Game) hidesNumbers() bool {
+	return g.coop != nil && g.coop.role == roleScanner && !g.gameOver && !g.won
+}
+
+func (g *Game) drawScannerSignal(screen *ebiten.Image) {
+	c := g.coop
+	if c == nil || c.role != roleScanner || g.gameOver || g.won {
+		return
+	}
+	for y, row := range c.signal {
+		for x, detected := range row {
+			cell := g.grid[y][x]
+			if detected && !cell.revealed && !cell.flagged {
+				px, py := g.cellScreenPos(x, y)
+				vector.DrawFilledCircle(screen, px+cellSize/2, py+cellSize/2, cellSize/5, color.RGBA{220, 40, 40, 200}, true)
+			}
+		}
+	}
+}
+
+func (g *Game) drawCoop(screen *ebiten.Image) {
+	c := g.coop
+	if c == nil {
+		return
+	}
+
+	// 旗帜和其他玩家的光标用各自的颜色标出
+	view := g.boardView(screen)
+	for y, row := range c.owners {
+		for x, owner := range row {
+			if owner >= 0 && g.grid[y][x].flagged {
+				px, py := g.cellScreenPos(x, y)
+				vector.StrokeRect(view, px+1, py+1, cellSize-2, cellSize-2, 2, playerColor(owner), false)
+			}
+		}
+	}
+	for id, pos := range c.cursors {
+		clr := playerColor(id)
+		px, py := g.cellScreenPos(pos.x, pos.y)
+		vector.StrokeRect(view, px, py, cellSize, cellSize, 3, clr, false)
+		text.Draw(view, fmt.Sprint(id+1), g.gameFont, int(px)+2, int(py)-2, clr)
+	}
+
+	// 提示标记：格子中央的圆点，外加逐渐扩大并淡出的圆圈
+	now := time.Now()
+	active := c.pings[:0]
+	for _, p := range c.pings {
+		t := float32(now.Sub(p.at)) / float32(pingDuration)
+		if t >= 1 {
+			continue
+		}
+		active = append(active, p)
+		clr := playerColor(p.player)
+		clr.A = uint8(255 * (1 - t*t))
+		px, py := g.cellScreenPos(p.x, p.y)
+		cx, cy := px+cellSize/2, py+cellSize/2
+		vector.DrawFilledCircle(view, cx, cy, cellSize/4, clr, true)
+
+		ring := playerColor(p.player)
+		ring.A = uint8(255 * (1 - t))
+		vector.StrokeCircle(view, cx, cy, cellSize/2*(1+t), 3, ring, true)
+	}
+	c.pings = active
+
+	// 最近的聊天记录显示在棋盘左上角
+	y := 16
+	for _, line := range c.chat {
+		if now.Sub(line.at) > chatDuration {
+			continue
+		}
+		prefix := fmt.Sprintf("%s %d: ", tr("玩家"), line.player+1)
+		if line.player == c.self {
+			prefix = tr("我") + ": "
+		}
+		g.drawChatLine(screen, prefix+line.text, y)
+		y += 20
+	}
+	if c.typing {
+		cursor := ""
+		if now.UnixMilli()/500%2 == 0 {
+			cursor = "_"
+		}
+		g.drawChatLine(screen, "> "+c.input+cursor, g.screenHeight()-100)
+	}
+}
+
+func (g *Game) drawChatLine(screen *ebiten.Image, msg string, y int) {
+	width := text.BoundString(g.gameFont, msg).Dx()
+	vector.DrawFilledRect(screen, 4, float32(y-14), float32(width+12), 20, color.RGBA{0, 0, 0, 160}, false)
+	text.Draw(screen, msg, g.gameFont, 10, y, color.White)
+}
+
+func (g *Game) coopStatus() string {
+	switch g.coop.role {
+	case roleScanner:
+		return tr("角色: 探测")
+	case roleCounter:
+		return tr("角色: 数字")
+	}
+	return fmt.Sprintf("%s %d", tr("玩家"), g.coop.self+1)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+)
+
+// 可解锁的外观：部分主题、旗帜样式和音效包需要先获得对应的成就，见 achievements.go。
+// 未解锁的在外观界面中显示为灰色并注明解锁条件，配置中选了未解锁的项目时使用默认外观
+
+// 解锁 unlock 对应的成就后才能使用，unlock 为空表示默认可用
+func cosmeticUnlocked(unlock string) bool {
+	if unlock == "" {
+		return true
+	}
+	_, ok := globalStats.Achievements[unlock]
+	return ok
+}
+
+func findAchievement(id string) achievement {
+	for _, a := range achievements {
+		if a.id == id {
+			return a
+		}
+	}
+	return achievement{}
+}
+
+// 按名称排列的主题，默认主题在最前
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		if name != "default" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{"default"}, names...)
+}
+
+// 旗帜样式：在原有红旗贴图上旋转色相，旗杆是灰色不受影响
+type flagSkin struct {
+	Name   string
+	Title  string
+	Hue    float64 // 旋转的色相，弧度
+	Unlock string
+}
+
+var flagSkins = []flagSkin{
+	{"red", "红旗", 0, ""},
+	{"green", "绿旗", 2 * math.Pi / 3, "knight"},
+	{"blue", "蓝旗", 4 * math.Pi / 3, "daily"},
+	{"gold", "金旗", math.Pi / 3, "streak-10"},
+}
+
+func activeFlagSkin() flagSkin {
+	for _, s := range flagSkins {
+		if s.Name == globalConfig.FlagSkin && cosmeticUnlocked(s.Unlock) {
+			return s
+		}
+	}
+	return flagSkins[0]
+}
+
+// 按当前旗帜样式绘制旗帜贴图，参数与 drawSprite 相同
+func (g *Game) drawFlagSprite(screen *ebiten.Image, name string, x, y, size float64, alpha float32) {
+	skin := activeFlagSkin()
+	if skin.Hue == 0 {
+		g.drawSprite(screen, name, x, y, size, alpha)
+		return
+	}
+	img := g.images[name].nearest(size)
+	scale := size / float64(img.Bounds().Dx())
+
+	var cm colorm.ColorM
+	cm.RotateHue(skin.Hue)
+	cm.ScaleWithColor(activeTheme.TileTint)
+	cm.Scale(1, 1, 1, float64(alpha))
+	op := &colorm.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	if scale < 1 {
+		op.Filter = ebiten.FilterLinear
+	}
+	colorm.DrawImage(screen, img, cm, op)
+}
+
+// 音效包：在加载时对原有音效的采样做变换，不需要额外的资源文件
+type soundPack struct {
+	Name   string
+	Title  string
+	Unlock string
+	filter func(samples []int16)
+}
+
+var soundPacks = []soundPack{
+	{"classic", "经典", "", nil},
+	{"retro", "复古", "medium-60", retroFilter},
+	{"soft", "柔和", "hard-100", softFilter},
+}
+
+func activeSoundPack() soundPack {
+	for _, p := range soundPacks {
+		if p.Name == globalConfig.SoundPack && cosmeticUnlocked(p.Unlock) {
+			return p
+		}
+	}
+	return soundPacks[0]
+}
+
+// 降低采样精度并每 4 个采样保持一次，听起来像 8 位机的音效
+func retroFilter(samples []int16) {
+	var held int16
+	for i := range samples {
+		// 左右声道交替排列，按帧保持
+		if (i/2)%4 == 0 {
+			held = samples[i] &^ 0x0fff
+		}
+		samples[i] = held
+	}
+}
+
+// 一阶低通滤波并降低音量，声音更闷更轻
+func softFilter(samples []int16) {
+	var prev [2]float64
+	for i := range samples {
+		ch := i % 2
+		prev[ch] += 0.25 * (float64(samples[i]) - prev[ch])
+		samples[i] = int16(prev[ch] * 0.7)
+	}
+}
+
+// 对 16 位小端立体声的 PCM 数据应用音效包
+func (p soundPack) apply(pcm []byte) {
+	if p.filter == nil {
+		return
+	}
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[2*i:]))
+	}
+	p.filter(samples)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(s))
+	}
+}
+
+// 配置的音效包与已加载的不同时重新加载，切换档案或修改设置后在下次播放时生效
+func (g *Game) syncSoundPack() {
+	pack := activeSoundPack()
+	if pack.Name == g.soundPack {
+		return
+	}
+	sounds, err := loadGameSounds(g.audioContext, pack)
+	if err != nil {
+		showToast(tr("无法加载音效"))
+		g.soundPack = pack.Name // 不再重试，继续使用原来的音效
+		return
+	}
+	g.sounds, g.soundPack = sounds, pack.Name
+}
+
+// 获得成就时提示随之解锁的外观
+func announceCosmetics(id string) {
+	var titles []string
+	for _, name := range themeNames() {
+		if themes[name].Unlock == id {
+			titles = append(titles, themes[name].Title)
+		}
+	}
+	for _, s := range flagSkins {
+		if s.Unlock == id {
+			titles = append(titles, s.Title)
+		}
+	}
+	for _, p := range soundPacks {
+		if p.Unlock == id {
+			titles = append(titles, p.Title)
+		}
+	}
+	for _, t := range titles {
+		showToast(tr("解锁外观") + ": " + tr(t))
+	}
+}
+
+// 外观界面：每个项目一个按钮，当前使用的打勾，未解锁的不可点击并注明条件
+func (g *Game) cosmeticsButtons() []*menuButton {
+	var buttons []*menuButton
+	cosmeticConditions = make(map[*Button]string)
+	add := func(kind, title, unlock string, selected bool, choose func()) {
+		label := tr(kind) + ": " + tr(title)
+		if selected {
+			label = "✓ " + label
+		}
+		locked := !cosmeticUnlocked(unlock)
+		if locked {
+			label += " · " + tr(findAchievement(unlock).title)
+		}
+		btn := &menuButton{Button: &Button{Text: label, Disabled: locked}, action: func() error {
+			choose()
+			g.applySettings()
+			return nil
+		}}
+		if locked {
+			cosmeticConditions[btn.Button] = findAchievement(unlock).desc
+		}
+		buttons = append(buttons, btn)
+	}
+
+	for _, name := range themeNames() {
+		name := name
+		t := themes[name]
+		add("主题", t.Title, t.Unlock, activeTheme.Name == name, func() { globalConfig.Theme = name })
+	}
+	skin := activeFlagSkin()
+	for _, s := range flagSkins {
+		s := s
+		add("旗帜", s.Title, s.Unlock, skin.Name == s.Name, func() { globalConfig.FlagSkin = s.Name })
+	}
+	pack := activeSoundPack()
+	for _, p := range soundPacks {
+		p := p
+		add("音效", p.Title, p.Unlock, pack.Name == p.Name, func() {
+			globalConfig.SoundPack = p.Name
+			g.playSound("win")
+		})
+	}
+
+	back := g.backButton()
+	back.action = func() error {
+		g.switchScene(SceneSettings)
+		return nil
+	}
+	return append(buttons, back)
+}
+
+// 未解锁的按钮对应的成就说明
+var cosmeticConditions map[*Button]string
+
+// 鼠标停在未解锁的项目上时在底部显示解锁条件
+func (g *Game) drawCosmetics(screen *ebiten.Image) {
+	x, y := cursorPosition()
+	for _, btn := range g.menuButtons {
+		if desc, ok := cosmeticConditions[btn.Button]; ok && btn.Contains(x, y) {
+			g.drawCentered(screen, tr("解锁条件")+": "+tr(desc), g.screenHeight()-12)
+		}
+	}
+}
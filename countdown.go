@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// 限时模式：每个难度有固定的时间预算，首次点击后开始倒计时，
+// 时间用完即失败，最后 10 秒每秒提示一次。成绩按 countdown 玩法单独记录
+var countdownBudgets = map[Difficulty]time.Duration{
+	Easy:   time.Minute,
+	Medium: 4 * time.Minute,
+	Hard:   10 * time.Minute,
+	Huge:   time.Hour,
+}
+
+const (
+	countdownPerMine = 6 * time.Second // 自定义难度按地雷数计算预算，与标准难度大致相当
+	countdownWarning = 10 * time.Second
+)
+
+// 对战、合作、挑战和街机有自己的规则，不使用倒计时
+func (g *Game) countdownActive() bool {
+	return g.countdown && g.race == nil && g.coop == nil && g.challenge == nil && g.arcade == nil
+}
+
+func (g *Game) timeLimit() time.Duration {
+	if budget, ok := countdownBudgets[g.difficulty]; ok && g.shape == nil && g.puzzle == nil {
+		return budget
+	}
+	return time.Duration(g.mineCount()) * countdownPerMine
+}
+
+func (g *Game) timeLeft() time.Duration {
+	left := g.timeLimit() - g.elapsedTime
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// 每帧检查剩余时间，最后几秒播放提示音，用完时判负
+func (g *Game) updateCountdown() {
+	if !g.countdownActive() || g.firstClick || g.gameOver || g.won {
+		return
+	}
+	left := g.timeLeft()
+	if left <= 0 {
+		g.gameOver, g.timeUp = true, true
+		g.explodedX, g.explodedY = -1, -1
+		g.revealAllMines()
+		g.publish(EventGameLost)
+		return
+	}
+	if left <= countdownWarning {
+		if sec := int((left + time.Second - 1) / time.Second); sec != g.lastTick {
+			g.lastTick = sec
+			g.playSound("ping")
+		}
+	}
+}
+
+// 结果界面的第一行：限时模式显示剩余时间或完成的比例
+func (g *Game) countdownResult() string {
+	if g.won {
+		return fmt.Sprintf("%s: %s / %s", tr("剩余"), formatDuration(g.timeLeft()), formatDuration(g.timeLimit()))
+	}
+	safe := g.cellCount() - g.mineCount()
+	return fmt.Sprintf("%s: %d%%", tr("完成"), g.revealedSafe*100/safe)
+}
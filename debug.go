@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 调试模式：按 ` 键切换，显示帧率、各部分耗时和鼠标所在格子的内部状态，
+// Ctrl+T 录制追踪文件。全局保存，重启后依然有效
+var debugMode bool
+
+// 是否显示格子有没有地雷：对战、挑战和合作中只在对局结束后显示
+func (g *Game) debugShowsMines() bool {
+	return !g.inProgress() || g.probabilityAllowed()
+}
+
+// 对局中打开调试模式能看到地雷，本局成绩单独记录，见 variant
+func (g *Game) updateDebug() {
+	if debugMode && g.inProgress() && g.debugShowsMines() {
+		g.assisted = true
+	}
+}
+
+// 鼠标所在的格子，不在棋盘上时 ok 为 false
+func (g *Game) hoveredCell() (x, y int, ok bool) {
+	return g.cellAt(cursorPosition())
+}
+
+// 可复现的格子描述：种子代码决定地雷布局，再加上格子坐标和已进行的步数
+func (g *Game) cellDescriptor(x, y int) string {
+	layout := "unplaced"
+	if g.minesPlaced {
+		r := g.currentSeed()
+		layout = r.code()
+	}
+	return fmt.Sprintf("%s @%d,%d #%d", layout, x, y, len(g.moves))
+}
+
+// 调试模式下按住 Ctrl 点击格子，复制格子描述到剪贴板，返回 true 表示点击已被处理
+func (g *Game) updateDebugPick() bool {
+	if !debugMode || !ebiten.IsKeyPressed(ebiten.KeyControl) || !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+	x, y, ok := g.hoveredCell()
+	if !ok {
+		return false
+	}
+	desc := g.cellDescriptor(x, y)
+	if err := writeClipboard(desc); err != nil {
+		slog.Warn("复制到剪贴板失败", "err", err)
+		showToast(desc)
+		return true
+	}
+	showToast(tr("已复制") + ": " + desc)
+	return true
+}
+
+func (g *Game) drawDebug(screen *ebiten.Image) {
+	if !debugMode {
+		return
+	}
+	msg := fmt.Sprintf("FPS %.0f  TPS %.0f", ebiten.ActualFPS(), ebiten.ActualTPS()) + prof.summary()
+	if x, y, ok := g.hoveredCell(); ok {
+		cell := g.grid[y][x]
+		// 未翻开格子的数字同样会泄露地雷位置
+		mine, neighbors := "?", "?"
+		if g.debugShowsMines() {
+			mine = fmt.Sprint(cell.hasMine)
+		}
+		if g.debugShowsMines() || cell.revealed {
+			neighbors = fmt.Sprint(cell.neighbors)
+		}
+		msg += fmt.Sprintf("\n(%d, %d) %s neighbors=%s\nmine=%s revealed=%v\nflagged=%v questioned=%v\n%s",
+			x, y, coordName(x, y), neighbors, mine, cell.revealed, cell.flagged, cell.questioned, g.cellDescriptor(x, y))
+	}
+	ebitenutil.DebugPrint(screen, msg)
+}
@@ -0,0 +1,159 @@
+// Package discord 通过本地 IPC 与 Discord 客户端通信，设置“正在玩”的状态
+package discord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// 帧类型
+const (
+	opHandshake = 0
+	opFrame     = 1
+	opClose     = 2
+)
+
+type Activity struct {
+	Details string    // 第一行，例如难度
+	State   string    // 第二行，例如剩余地雷
+	Start   time.Time // 非零时 Discord 显示已用时间
+}
+
+type Client struct {
+	conn  io.ReadWriteCloser
+	nonce atomic.Int64
+}
+
+// Connect 连接本机运行的 Discord 客户端并完成握手
+func Connect(clientID string) (*Client, error) {
+	var lastErr error
+	for i := 0; i < 10; i++ {
+		conn, err := dial(i)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c := &Client{conn: conn}
+		if err := c.handshake(clientID); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("未找到 Discord 客户端: %v", lastErr)
+}
+
+// Windows 使用命名管道，其它系统使用运行时目录下的 Unix socket
+func dial(i int) (io.ReadWriteCloser, error) {
+	name := fmt.Sprintf("discord-ipc-%d", i)
+	if runtime.GOOS == "windows" {
+		return os.OpenFile(`\\.\pipe\`+name, os.O_RDWR, 0)
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	for _, key := range []string{"TMPDIR", "TMP", "TEMP"} {
+		if dir != "" {
+			break
+		}
+		dir = os.Getenv(key)
+	}
+	if dir == "" {
+		dir = "/tmp"
+	}
+	return net.DialTimeout("unix", filepath.Join(dir, name), time.Second)
+}
+
+func (c *Client) handshake(clientID string) error {
+	if err := c.write(opHandshake, map[string]any{"v": 1, "client_id": clientID}); err != nil {
+		return fmt.Errorf("发送握手失败: %v", err)
+	}
+	op, reply, err := c.read()
+	if err != nil {
+		return fmt.Errorf("接收握手失败: %v", err)
+	}
+	if op == opClose {
+		return fmt.Errorf("Discord 拒绝连接: %s", reply)
+	}
+	return nil
+}
+
+// SetActivity 更新状态，传入 nil 清除状态
+func (c *Client) SetActivity(a *Activity) error {
+	var activity map[string]any
+	if a != nil {
+		activity = map[string]any{"details": a.Details, "state": a.State}
+		if !a.Start.IsZero() {
+			activity["timestamps"] = map[string]any{"start": a.Start.Unix()}
+		}
+	}
+	err := c.write(opFrame, map[string]any{
+		"cmd":   "SET_ACTIVITY",
+		"nonce": fmt.Sprint(c.nonce.Add(1)),
+		"args":  map[string]any{"pid": os.Getpid(), "activity": activity},
+	})
+	if err != nil {
+		return err
+	}
+	// 读取并丢弃应答，出错时应答中带有 evt: ERROR
+	op, reply, err := c.read()
+	if err != nil {
+		return err
+	}
+	if op == opClose {
+		return errors.New("Discord 已关闭连接")
+	}
+	var resp struct {
+		Evt  string `json:"evt"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(reply, &resp) == nil && resp.Evt == "ERROR" {
+		return fmt.Errorf("设置状态失败: %s", resp.Data.Message)
+	}
+	return nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// 每帧由 8 字节头（操作码、长度，小端）和 JSON 内容组成
+func (c *Client) write(op uint32, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, op)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	_, err = c.conn.Write(buf.Bytes())
+	return err
+}
+
+func (c *Client) read() (uint32, []byte, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+		return 0, nil, err
+	}
+	op := binary.LittleEndian.Uint32(header[:4])
+	size := binary.LittleEndian.Uint32(header[4:])
+	if size > 1<<20 {
+		return 0, nil, errors.New("应答过大")
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, data); err != nil {
+		return 0, nil, err
+	}
+	return op, data, nil
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/board"
+	"minesweeper/solver"
+	"minesweeper/storage"
+)
+
+// 图案练习：反复生成含有指定经典图案的小棋盘，玩家左键翻开可以确定安全的格子、
+// 右键标出可以确定的地雷，图案涉及的格子全部答对后计时结束并自动换下一题
+const (
+	drillWidth   = 8 // 标准方向下的棋盘大小：上两行未翻开，下两行已翻开
+	drillHeight  = 4
+	drillDensity = 0.25 // 图案以外未翻开格子的地雷密度
+	drillPause   = 700 * time.Millisecond
+	drillTop     = 60
+)
+
+// 一种练习图案。segment 是边界上一段未翻开格子的布置，* 为地雷，
+// 放在第二行，下方翻开后显示出图案的数字。wall 表示图案必须靠着棋盘边缘
+type drillPattern struct {
+	id      string
+	title   string
+	segment string
+	wall    bool
+}
+
+var drillPatterns = []drillPattern{
+	{id: "121", title: "1-2-1", segment: ".*.*."},
+	{id: "1221", title: "1-2-2-1", segment: "..**.."},
+	{id: "11-edge", title: "边上的 1-1", segment: "*..", wall: true},
+	{id: "12-edge", title: "边上的 1-2", segment: "*.*", wall: true},
+}
+
+type drillBoard struct {
+	pattern       drillPattern
+	width, height int
+	mines         [][]bool
+	counts        [][]int
+	revealed      [][]bool
+	marked        [][]bool              // 已经答对的格子
+	answers       map[solver.Point]bool // 可以确定的格子，值为是否是地雷
+	required      []solver.Point        // 图案涉及、必须答对的格子
+	start         time.Time
+	finished      time.Time
+	mistakes      int
+	wrong         solver.Point // 最近一次答错的格子
+	wrongAt       time.Time
+}
+
+// 在标准方向生成棋盘后随机翻转，同一图案每次出现的位置和方向都不同
+func newDrillBoard(p drillPattern, rng *rand.Rand) *drillBoard {
+	mines := make([][]bool, drillHeight)
+	for y := range mines {
+		mines[y] = make([]bool, drillWidth)
+	}
+	offset := 0
+	if !p.wall {
+		offset = rng.IntN(drillWidth - len(p.segment) + 1)
+	}
+	inSegment := func(x, y int) bool {
+		return y == 1 && x >= offset && x < offset+len(p.segment)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < drillWidth; x++ {
+			if inSegment(x, y) {
+				mines[y][x] = p.segment[x-offset] == '*'
+			} else {
+				mines[y][x] = rng.Float64() < drillDensity
+			}
+		}
+	}
+
+	// 坐标变换：水平翻转、垂直翻转
+	flipX, flipY := rng.IntN(2) == 1, rng.IntN(2) == 1
+	transform := func(x, y int) (int, int) {
+		if flipX {
+			x = drillWidth - 1 - x
+		}
+		if flipY {
+			y = drillHeight - 1 - y
+		}
+		return x, y
+	}
+
+	d := &drillBoard{pattern: p, width: drillWidth, height: drillHeight, start: time.Now()}
+	d.mines = make([][]bool, drillHeight)
+	d.revealed = make([][]bool, drillHeight)
+	d.marked = make([][]bool, drillHeight)
+	for y := range d.mines {
+		d.mines[y] = make([]bool, drillWidth)
+		d.revealed[y] = make([]bool, drillWidth)
+		d.marked[y] = make([]bool, drillWidth)
+	}
+	for y := 0; y < drillHeight; y++ {
+		for x := 0; x < drillWidth; x++ {
+			tx, ty := transform(x, y)
+			d.mines[ty][tx] = mines[y][x]
+			d.revealed[ty][tx] = y >= 2
+		}
+	}
+	d.counts = board.CountNeighbors(d.mines)
+	d.answers = d.deduce()
+	for x := offset; x < offset+len(p.segment); x++ {
+		tx, ty := transform(x, 1)
+		if _, ok := d.answers[solver.Point{X: tx, Y: ty}]; ok {
+			d.required = append(d.required, solver.Point{X: tx, Y: ty})
+		}
+	}
+	return d
+}
+
+// 枚举边界格子所有满足数字的地雷组合，在每种组合中状态都相同的格子即可以确定。
+// 练习棋盘的边界只有一行，组合数很少
+func (d *drillBoard) deduce() map[solver.Point]bool {
+	var frontier []solver.Point
+	index := make(map[solver.Point]int)
+	var numbers []solver.Point
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			if !d.revealed[y][x] {
+				continue
+			}
+			numbers = append(numbers, solver.Point{X: x, Y: y})
+			for _, n := range (board.Square8{}).Neighbors(nil, d.width, d.height, x, y) {
+				p := solver.Point{X: n[0], Y: n[1]}
+				if _, ok := index[p]; !ok && !d.revealed[p.Y][p.X] {
+					index[p] = len(frontier)
+					frontier = append(frontier, p)
+				}
+			}
+		}
+	}
+
+	var always, never uint
+	first := true
+	for mask := uint(0); mask < 1<<len(frontier); mask++ {
+		ok := true
+		for _, c := range numbers {
+			n := 0
+			for _, nb := range (board.Square8{}).Neighbors(nil, d.width, d.height, c.X, c.Y) {
+				if i, in := index[solver.Point{X: nb[0], Y: nb[1]}]; in && mask&(1<<i) != 0 {
+					n++
+				}
+			}
+			if n != d.counts[c.Y][c.X] {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if first {
+			always, never, first = mask, mask, false
+		}
+		always &= mask
+		never |= mask
+	}
+
+	answers := make(map[solver.Point]bool)
+	for i, p := range frontier {
+		switch {
+		case always&(1<<i) != 0:
+			answers[p] = true
+		case never&(1<<i) == 0:
+			answers[p] = false
+		}
+	}
+	return answers
+}
+
+// 标记一个格子，mine 表示标为地雷。返回是否答对
+func (d *drillBoard) claim(x, y int, mine bool) bool {
+	p := solver.Point{X: x, Y: y}
+	if answer, ok := d.answers[p]; !ok || answer != mine {
+		d.mistakes++
+		d.wrong, d.wrongAt = p, time.Now()
+		return false
+	}
+	d.marked[y][x] = true
+	if d.solved() {
+		d.finished = time.Now()
+	}
+	return true
+}
+
+func (d *drillBoard) solved() bool {
+	for _, p := range d.required {
+		if !d.marked[p.Y][p.X] {
+			return false
+		}
+	}
+	return true
+}
+
+// 每种图案的练习统计
+type drillStat struct {
+	Rounds int           `json:"rounds"`
+	Clean  int           `json:"clean"` // 没有答错的次数
+	Total  time.Duration `json:"total"`
+	Best   time.Duration `json:"best"`
+}
+
+type DrillStats struct {
+	Patterns map[string]*drillStat `json:"patterns"`
+
+	name  string
+	store storage.Storage
+}
+
+var globalDrills = &DrillStats{Patterns: make(map[string]*drillStat)}
+
+func loadDrillStats(store storage.Storage) (*DrillStats, error) {
+	s := &DrillStats{name: "drills.json", store: store}
+	err := loadFile(s.store, s.name, "drills", s)
+	if s.Patterns == nil {
+		s.Patterns = make(map[string]*drillStat)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("读取练习统计失败: %v", err)
+	}
+	return s, nil
+}
+
+func (s *DrillStats) save() error {
+	if s.name == "" {
+		return nil
+	}
+	return saveFile(s.store, s.name, "drills", s)
+}
+
+func (s *DrillStats) record(d *drillBoard) {
+	st := s.Patterns[d.pattern.id]
+	if st == nil {
+		st = &drillStat{}
+		s.Patterns[d.pattern.id] = st
+	}
+	elapsed := d.finished.Sub(d.start)
+	st.Rounds++
+	st.Total += elapsed
+	if d.mistakes == 0 {
+		st.Clean++
+		if st.Best == 0 || elapsed < st.Best {
+			st.Best = elapsed
+		}
+	}
+	if err := s.save(); err != nil {
+		slog.Warn("保存练习统计失败", "err", err)
+	}
+}
+
+// 练习的状态，离开界面后保留选择的图案
+var drill struct {
+	pattern int
+	board   *drillBoard
+	rng     *rand.Rand
+}
+
+func (g *Game) nextDrill() {
+	if drill.rng == nil {
+		drill.rng = board.NewRand(time.Now().UnixNano())
+	}
+	drill.board = newDrillBoard(drillPatterns[drill.pattern], drill.rng)
+}
+
+func (g *Game) drillButtons() []*menuButton {
+	// 每次进入界面都换一题，计时从看到棋盘开始
+	g.nextDrill()
+	half := (g.screenWidth() - 30) / 2
+	back := g.backButton()
+	back.X, back.Y, back.W, back.H = 20+half, g.screenHeight()-44, half, 34
+	return []*menuButton{
+		{Button: &Button{X: 10, Y: g.screenHeight() - 44, W: half, H: 34, Text: tr("图案") + ": " + tr(drillPatterns[drill.pattern].title)}, action: func() error {
+			drill.pattern = (drill.pattern + 1) % len(drillPatterns)
+			g.switchScene(SceneDrill)
+			return nil
+		}},
+		back,
+	}
+}
+
+// 练习棋盘左上角的屏幕坐标，水平居中
+func (g *Game) drillOrigin() (int, int) {
+	return (g.screenWidth() - drill.board.width*cellSize) / 2, drillTop
+}
+
+func (g *Game) updateDrill() {
+	d := drill.board
+	if !d.finished.IsZero() {
+		if time.Since(d.finished) >= drillPause {
+			g.nextDrill()
+		}
+		return
+	}
+
+	left := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	right := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+	if !left && !right {
+		return
+	}
+	ox, oy := g.drillOrigin()
+	mapper := gridMapper{originX: ox, originY: oy, width: d.width, height: d.height}
+	x, y, ok := mapper.cell(cursorPosition())
+	if !ok || d.revealed[y][x] || d.marked[y][x] {
+		return
+	}
+	switch {
+	case !d.claim(x, y, right):
+		g.playSound("explosion")
+	case right:
+		g.playSound("flag")
+	default:
+		g.playSound("click")
+	}
+	if !d.finished.IsZero() {
+		globalDrills.record(d)
+	}
+}
+
+func (g *Game) drawDrill(screen *ebiten.Image) {
+	d := drill.board
+	ox, oy := g.drillOrigin()
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			cell := Cell{revealed: d.revealed[y][x], neighbors: d.counts[y][x]}
+			if d.marked[y][x] {
+				cell.revealed = !d.mines[y][x]
+				cell.flagged = d.mines[y][x]
+			}
+			g.drawLookSized(screen, cellLook{cell: cell}, float64(ox+x*cellSize), float64(oy+y*cellSize), cellSize, 1)
+		}
+	}
+	if time.Since(d.wrongAt) < 400*time.Millisecond {
+		px, py := float32(ox+d.wrong.X*cellSize), float32(oy+d.wrong.Y*cellSize)
+		vector.StrokeRect(screen, px+1, py+1, cellSize-2, cellSize-2, 2, color.RGBA{220, 40, 40, 255}, false)
+	}
+
+	elapsed := time.Since(d.start)
+	if !d.finished.IsZero() {
+		elapsed = d.finished.Sub(d.start)
+	}
+	g.drawCentered(screen, fmt.Sprintf("%.1fs", elapsed.Seconds()), drillTop-16)
+
+	y := oy + d.height*cellSize + 24
+	if st := globalDrills.Patterns[d.pattern.id]; st != nil && st.Rounds > 0 {
+		lines := []string{
+			fmt.Sprintf("%s: %d  %s: %d%%", tr("练习"), st.Rounds, tr("正确率"), st.Clean*100/st.Rounds),
+			fmt.Sprintf("%s: %.1fs  %s: %s", tr("平均"), (st.Total / time.Duration(st.Rounds)).Seconds(), tr("最佳"), drillTime(st.Best)),
+		}
+		for _, line := range lines {
+			text.Draw(screen, line, g.gameFont, 20, y, activeTheme.Text)
+			y += 22
+		}
+	}
+}
+
+func drillTime(d time.Duration) string {
+	if d == 0 {
+		return "--"
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', 2, 64) + "s"
+}
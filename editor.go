@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/board"
+	"minesweeper/solver"
+)
+
+// 谜题编辑器：左键放置或移除地雷，右键切换格子是否存在，Shift+左键设置起点。
+// 棋盘大小和拓扑取自当前对局，超出窗口的部分不可编辑
+type editor struct {
+	width, height  int
+	topology       board.Topology
+	mines, missing [][]bool
+	startX, startY int
+	shape          int    // 下一个要套用的内置形状
+	status         string // 最近一次试解的结果
+}
+
+// 编辑中的谜题全局保存，试玩后回到编辑器可以继续修改
+var puzzleEditor *editor
+
+func newEditor(width, height int, t board.Topology) *editor {
+	e := &editor{width: width, height: height, topology: t, startX: width / 2, startY: height / 2}
+	e.clear()
+	return e
+}
+
+func (e *editor) clear() {
+	e.mines = make([][]bool, e.height)
+	e.missing = make([][]bool, e.height)
+	for y := range e.mines {
+		e.mines[y] = make([]bool, e.width)
+		e.missing[y] = make([]bool, e.width)
+	}
+	e.status = ""
+}
+
+// 依次套用内置形状，最后回到完整的矩形
+func (e *editor) nextShape() {
+	var mask *board.Mask
+	if e.shape < len(board.Shapes) {
+		mask, _ = board.ShapeMask(board.Shapes[e.shape], e.width, e.height)
+	}
+	e.shape = (e.shape + 1) % (len(board.Shapes) + 1)
+	for y := range e.missing {
+		for x := range e.missing[y] {
+			e.missing[y][x] = mask != nil && !mask.Contains(x, y)
+			e.mines[y][x] = e.mines[y][x] && !e.missing[y][x]
+		}
+	}
+	e.status = ""
+}
+
+func (e *editor) adjacency() board.Topology {
+	for _, row := range e.missing {
+		for _, m := range row {
+			if m {
+				return board.Shaped{Base: e.topology, Mask: board.NewMask("puzzle", e.width, e.height, func(x, y int) bool {
+					return !e.missing[y][x]
+				})}
+			}
+		}
+	}
+	return e.topology
+}
+
+// 按当前编辑的内容生成谜题，起点无效或没有地雷时返回错误
+func (e *editor) puzzle() (*puzzle, error) {
+	p := &puzzle{StartX: e.startX, StartY: e.startY}
+	if e.topology != (board.Square8{}) {
+		p.Topology = e.topology.Name()
+	}
+	for y := range e.mines {
+		var b strings.Builder
+		for x := range e.mines[y] {
+			switch {
+			case e.missing[y][x]:
+				b.WriteByte(puzzleMissing)
+			case e.mines[y][x]:
+				b.WriteByte(puzzleMine)
+			default:
+				b.WriteByte(puzzleSafe)
+			}
+		}
+		p.Rows = append(p.Rows, b.String())
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// 用求解器从起点开始只靠推理求解，报告是否需要猜测
+func (e *editor) testSolve() {
+	t := e.adjacency()
+	outcome := solver.PlayOn(t, e.mines, solver.Point{X: e.startX, Y: e.startY})
+	switch {
+	case !outcome.Solved:
+		e.status = tr("需要猜测")
+	case outcome.MaxLevel == solver.LevelTrivial:
+		e.status = tr("无需猜测") + " · " + tr("简单推理")
+	default:
+		e.status = fmt.Sprintf("%s · %s ×%d", tr("无需猜测"), tr("子集推理"), outcome.SubsetSteps)
+	}
+}
+
+// 打开编辑器，棋盘大小与当前对局不同时重新开始编辑
+func (g *Game) openEditor() error {
+	w, h := clamp(g.gridWidth, 0, maxViewCols), clamp(g.gridHeight, 0, maxViewRows)
+	if e := puzzleEditor; e == nil || e.width != w || e.height != h || e.topology != g.topology {
+		puzzleEditor = newEditor(w, h, g.topology)
+	}
+	g.switchScene(SceneEditor)
+	return nil
+}
+
+func (g *Game) editorButtons() []*menuButton {
+	e := puzzleEditor
+	fail := func(err error) error {
+		slog.Warn("谜题无效", "err", err)
+		e.status = tr("谜题无效")
+		return nil
+	}
+	buttons := []*menuButton{
+		{Button: &Button{Text: "形状"}, action: func() error {
+			e.nextShape()
+			return nil
+		}},
+		{Button: &Button{Text: "清空"}, action: func() error {
+			e.clear()
+			return nil
+		}},
+		{Button: &Button{Text: "试解"}, action: func() error {
+			if _, err := e.puzzle(); err != nil {
+				return fail(err)
+			}
+			e.testSolve()
+			return nil
+		}},
+		{Button: &Button{Text: "试玩"}, action: func() error {
+			p, err := e.puzzle()
+			if err != nil {
+				return fail(err)
+			}
+			return g.beginPuzzle(p)
+		}},
+		{Button: &Button{Text: "保存"}, action: func() error {
+			p, err := e.puzzle()
+			if err != nil {
+				return fail(err)
+			}
+			name, err := savePuzzle(g.store, p)
+			if err != nil {
+				slog.Warn("保存谜题失败", "err", err)
+				showToast(tr("保存谜题失败"))
+				return nil
+			}
+			slog.Info("已保存谜题", "path", name)
+			showToast(tr("已保存谜题") + ": " + filepath.Base(name))
+			return nil
+		}},
+		{Button: &Button{Text: "导出 MBF"}, action: func() error {
+			p, err := e.puzzle()
+			if err != nil {
+				return fail(err)
+			}
+			if p.Topology != "" || p.mask() != nil {
+				e.status = tr("MBF 只支持经典棋盘")
+				return nil
+			}
+			name, err := saveMBF(g.store, p.mines())
+			if err != nil {
+				slog.Warn("导出 MBF 失败", "err", err)
+				showToast(tr("导出 MBF 失败"))
+				return nil
+			}
+			showToast(tr("已导出 MBF") + ": " + filepath.Base(name))
+			return nil
+		}},
+		g.backButton(),
+	}
+	// 状态栏区域排成两行，每行四个
+	w := (g.screenWidth() - 50) / 4
+	for i, btn := range buttons {
+		btn.X = 10 + i%4*(w+10)
+		btn.Y = g.viewHeight() + 24 + i/4*28
+		btn.W, btn.H = w, 24
+	}
+	return buttons
+}
+
+func (g *Game) updateEditor() {
+	e := puzzleEditor
+	x, y, ok := gridMapper{width: e.width, height: e.height}.cell(cursorPosition())
+	if !ok {
+		return
+	}
+	switch {
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && ebiten.IsKeyPressed(ebiten.KeyShift):
+		e.startX, e.startY = x, y
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		if !e.missing[y][x] {
+			e.mines[y][x] = !e.mines[y][x]
+		}
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight):
+		e.missing[y][x] = !e.missing[y][x]
+		e.mines[y][x] = false
+	default:
+		return
+	}
+	e.status = ""
+}
+
+// 地雷和数字全部显示，起点用方框标出，不存在的格子只画出轮廓
+func (g *Game) drawEditor(screen *ebiten.Image) {
+	e := puzzleEditor
+	counts := board.CountNeighborsOn(e.adjacency(), e.mines)
+	for y := 0; y < e.height; y++ {
+		for x := 0; x < e.width; x++ {
+			px, py := float32(x*cellSize), float32(y*cellSize)
+			if e.missing[y][x] {
+				vector.StrokeRect(screen, px+1, py+1, cellSize-2, cellSize-2, 1, dim(activeTheme.Text), false)
+				continue
+			}
+			look := cellLook{cell: Cell{revealed: true, hasMine: e.mines[y][x], neighbors: counts[y][x]}}
+			g.drawLookSized(screen, look, float64(px), float64(py), cellSize, 1)
+		}
+	}
+	sx, sy := float32(e.startX*cellSize), float32(e.startY*cellSize)
+	vector.StrokeRect(screen, sx+2, sy+2, cellSize-4, cellSize-4, 2, color.RGBA{0, 200, 80, 255}, false)
+
+	mines := 0
+	for _, row := range e.mines {
+		for _, m := range row {
+			if m {
+				mines++
+			}
+		}
+	}
+	status := fmt.Sprintf("%s: %d", tr("地雷"), mines)
+	if e.status != "" {
+		status += "  " + e.status
+	}
+	g.drawCentered(screen, status, g.viewHeight()+18)
+}
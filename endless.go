@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"log/slog"
+	"time"
+
+	"minesweeper/board"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 无尽模式：没有边界的棋盘，按 32x32 的区块在镜头经过时才生成。
+// 每个区块的地雷只由种子和区块坐标决定，与生成顺序无关
+const (
+	chunkSize         = 32
+	endlessSafeRadius = 2   // 起点周围不放地雷，保证第一次点击翻开一片空白
+	endlessFloodLimit = 512 // 单次连锁翻开最远到达的距离
+	endlessStatusBar  = 40
+)
+
+// 可选的地雷密度。密度太低时空白区域会连成无限大的一片，因此最低为 12%
+var endlessDensities = []float64{0.12, 0.15, 0.2, 0.25}
+
+type chunkKey struct{ x, y int }
+
+type chunk struct {
+	cells [chunkSize][chunkSize]Cell
+}
+
+type endlessBoard struct {
+	seed       int64
+	density    float64
+	chunks     map[chunkKey]*chunk
+	camX, camY int // 镜头左上角的世界像素坐标，可以为负数
+	score      int // 踩雷前安全翻开的格子数
+	startTime  time.Time
+	elapsed    time.Duration
+	started    bool
+	over       bool
+	explodedX  int
+	explodedY  int
+}
+
+func newEndlessBoard(density float64, viewW, viewH int) *endlessBoard {
+	return &endlessBoard{
+		seed:    time.Now().UnixNano(),
+		density: density,
+		chunks:  make(map[chunkKey]*chunk),
+		// 起点位于窗口中央
+		camX: cellSize/2 - viewW/2,
+		camY: cellSize/2 - viewH/2,
+	}
+}
+
+// 向下取整的除法，负坐标也能落到正确的区块
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func (e *endlessBoard) chunkSeed(k chunkKey) int64 {
+	h := fnv.New64a()
+	var buf [24]byte
+	binary.LittleEndian.PutUint64(buf[0:], uint64(e.seed))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(int64(k.x)))
+	binary.LittleEndian.PutUint64(buf[16:], uint64(int64(k.y)))
+	h.Write(buf[:])
+	return int64(h.Sum64())
+}
+
+// 返回区块，不存在时生成
+func (e *endlessBoard) chunk(k chunkKey) *chunk {
+	if c, ok := e.chunks[k]; ok {
+		return c
+	}
+	c := &chunk{}
+	rng := board.NewRand(e.chunkSeed(k))
+	for y := 0; y < chunkSize; y++ {
+		for x := 0; x < chunkSize; x++ {
+			wx, wy := k.x*chunkSize+x, k.y*chunkSize+y
+			mine := rng.Float64() < e.density
+			if chebyshev(wx, wy) <= endlessSafeRadius {
+				mine = false
+			}
+			c.cells[y][x].hasMine = mine
+		}
+	}
+	e.chunks[k] = c
+	return c
+}
+
+func (e *endlessBoard) cell(x, y int) *Cell {
+	k := chunkKey{floorDiv(x, chunkSize), floorDiv(y, chunkSize)}
+	return &e.chunk(k).cells[y-k.y*chunkSize][x-k.x*chunkSize]
+}
+
+func (e *endlessBoard) countNeighbors(x, y int) int {
+	n := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if (dx != 0 || dy != 0) && e.cell(x+dx, y+dy).hasMine {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// 翻开 (x, y)，返回是否踩雷。连锁翻开限制在以 (x, y) 为中心的有限范围内
+func (e *endlessBoard) reveal(x, y int) bool {
+	if !e.started {
+		e.started = true
+		e.startTime = time.Now()
+	}
+	if e.cell(x, y).hasMine {
+		e.over = true
+		e.explodedX, e.explodedY = x, y
+		e.elapsed = time.Since(e.startTime)
+		e.revealMines()
+		return true
+	}
+
+	r := endlessFloodLimit
+	board.FloodFill(2*r+1, 2*r+1, r, r, func(lx, ly int) bool {
+		cell := e.cell(x+lx-r, y+ly-r)
+		if cell.revealed || cell.flagged {
+			return false
+		}
+		cell.revealed = true
+		cell.neighbors = e.countNeighbors(x+lx-r, y+ly-r)
+		e.score++
+		return cell.neighbors == 0
+	})
+	return false
+}
+
+// 踩雷后显示已生成区块中的地雷
+func (e *endlessBoard) revealMines() {
+	for _, c := range e.chunks {
+		for y := range c.cells {
+			for x := range c.cells[y] {
+				if cell := &c.cells[y][x]; cell.hasMine && !cell.flagged {
+					cell.revealed = true
+				}
+			}
+		}
+	}
+}
+
+func (e *endlessBoard) toggleFlag(x, y int) {
+	cell := e.cell(x, y)
+	cell.flagged = !cell.flagged
+}
+
+func (g *Game) endlessViewHeight() int {
+	return g.screenHeight() - endlessStatusBar
+}
+
+func (g *Game) startEndless() {
+	g.endless = newEndlessBoard(globalConfig.EndlessDensity, g.screenWidth(), g.endlessViewHeight())
+}
+
+func (g *Game) updateEndless() error {
+	e := g.endless
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.switchScene(SceneMainMenu)
+		return nil
+	}
+	if actionJustPressed("restart") {
+		g.startEndless()
+		g.playSound("click")
+		return nil
+	}
+
+	dx, dy := cameraInput()
+	e.camX += dx
+	e.camY += dy
+	if e.over {
+		return nil
+	}
+	if e.started {
+		e.elapsed = time.Since(e.startTime)
+	}
+
+	if g.updateEndlessMinimap() {
+		return nil
+	}
+	mapper := gridMapper{scrollX: e.camX, scrollY: e.camY, viewW: g.screenWidth(), viewH: g.endlessViewHeight()}
+	x, y, ok := mapper.cell(cursorPosition())
+	if !ok {
+		return nil
+	}
+
+	if actionJustPressed("reveal") && !e.cell(x, y).flagged {
+		if e.reveal(x, y) {
+			g.playSound("explosion")
+			g.recordEndless()
+		} else {
+			g.playSound("click")
+		}
+	}
+	if actionJustPressed("flag") && !e.cell(x, y).revealed {
+		g.playSound("flag")
+		e.toggleFlag(x, y)
+	}
+	return nil
+}
+
+// 刷新无尽模式的最高分并保存
+func (g *Game) recordEndless() {
+	if g.endless.score <= globalStats.EndlessBest {
+		return
+	}
+	globalStats.EndlessBest = g.endless.score
+	if err := globalStats.save(); err != nil {
+		slog.Warn("保存统计数据失败", "err", err)
+	}
+}
+
+func (g *Game) drawEndless(screen *ebiten.Image) {
+	e := g.endless
+	viewH := g.endlessViewHeight()
+
+	// 只绘制镜头内的格子，需要时才生成对应的区块
+	x0, y0 := floorDiv(e.camX, cellSize), floorDiv(e.camY, cellSize)
+	x1, y1 := floorDiv(e.camX+g.screenWidth()-1, cellSize), floorDiv(e.camY+viewH-1, cellSize)
+	view := screen.SubImage(image.Rect(0, 0, g.screenWidth(), viewH)).(*ebiten.Image)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			look := cellLook{
+				cell:     *e.cell(x, y),
+				gameOver: e.over,
+				exploded: e.over && x == e.explodedX && y == e.explodedY,
+			}
+			g.drawLook(view, look, float64(x*cellSize-e.camX), float64(y*cellSize-e.camY), 1)
+		}
+	}
+	if !e.started {
+		px, py := float32(-e.camX), float32(-e.camY)
+		vector.StrokeRect(view, px+1, py+1, cellSize-2, cellSize-2, 2, color.RGBA{0, 200, 0, 255}, false)
+	}
+	g.drawEndlessMinimap(screen)
+	if e.over {
+		vector.DrawFilledRect(view, 0, 0, float32(g.screenWidth()), float32(viewH), activeTheme.Overlay, false)
+	}
+
+	vector.DrawFilledRect(screen, 0, float32(viewH), float32(g.screenWidth()), endlessStatusBar, activeTheme.Background, false)
+	status := fmt.Sprintf("%s: %d  %s: %d  %s: %s", tr("得分"), e.score, tr("最佳"), globalStats.EndlessBest,
+		tr("时间"), formatDuration(e.elapsed))
+	text.Draw(screen, status, g.gameFont, 10, viewH+26, activeTheme.Text)
+
+	if e.over {
+		g.drawCentered(screen, tr("游戏结束")+"  "+fmt.Sprintf(tr("按 %s 重新开始"), bindingTitle("restart")), viewH/2)
+	}
+}
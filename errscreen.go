@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"image/color"
+	"log/slog"
+	"os/exec"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	errorScreenWidth  = 560
+	errorScreenHeight = 320
+)
+
+// 启动失败的原因和给用户的处理建议
+type startupError struct {
+	title string
+	err   error
+	hints []string
+}
+
+func (e *startupError) Error() string {
+	return e.title + ": " + e.err.Error()
+}
+
+func (e *startupError) Unwrap() error {
+	return e.err
+}
+
+// 用系统默认程序打开文件
+func openFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
+
+// 启动失败时代替游戏运行的界面，显示错误原因、处理建议和打开日志的按钮
+type errorScreen struct {
+	ui      *Game // 只用于复用按钮的绘制
+	err     *startupError
+	openBtn *Button
+	quitBtn *Button
+}
+
+// 显示启动错误直到用户关闭窗口。连窗口也无法创建时只能退出
+func showStartupError(err error) {
+	slog.Error("启动失败", "err", err)
+	var se *startupError
+	if !errors.As(err, &se) {
+		se = &startupError{title: "启动失败", err: err}
+	}
+
+	// 字体本身可能就是出错的原因，找不到中文字体时只能显示英文
+	face, ferr := loadGameFont()
+	if ferr != nil {
+		face = basicfont.Face7x13
+	}
+	if face == basicfont.Face7x13 {
+		langOverride = "en"
+	}
+
+	s := &errorScreen{
+		ui:      &Game{AssetManager: &AssetManager{gameFont: face}},
+		err:     se,
+		openBtn: &Button{Text: "打开日志", X: 20, Y: errorScreenHeight - 50, W: 140, H: 30},
+		quitBtn: &Button{Text: "退出", X: errorScreenWidth - 160, Y: errorScreenHeight - 50, W: 140, H: 30},
+	}
+	ebiten.SetWindowTitle(tr("扫雷游戏") + " - " + tr(se.title))
+	ebiten.SetWindowSize(errorScreenWidth, errorScreenHeight)
+	if err := ebiten.RunGame(s); err != nil && err != ebiten.Termination {
+		fatal("无法显示错误界面", se)
+	}
+}
+
+func (s *errorScreen) Update() error {
+	x, y := ebiten.CursorPosition()
+	s.openBtn.Hover = s.openBtn.Contains(x, y)
+	s.quitBtn.Hover = s.quitBtn.Contains(x, y)
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return nil
+	}
+	switch {
+	case s.openBtn.Hover:
+		path, err := logPath()
+		if err == nil {
+			err = openFile(path)
+		}
+		if err != nil {
+			slog.Warn("打开日志失败", "err", err)
+		}
+	case s.quitBtn.Hover:
+		return ebiten.Termination
+	}
+	return nil
+}
+
+func (s *errorScreen) Draw(screen *ebiten.Image) {
+	screen.Fill(activeTheme.Background)
+	face := s.ui.gameFont
+	line := face.Metrics().Height.Ceil() + 4
+	y := 40
+	text.Draw(screen, tr(s.err.title), face, 20, y, color.RGBA{230, 80, 80, 255})
+	y += line * 3 / 2
+
+	// 原始错误信息不翻译，按窗口宽度折行
+	for _, l := range wrapText(face, s.err.err.Error(), errorScreenWidth-40) {
+		text.Draw(screen, l, face, 20, y, activeTheme.Text)
+		y += line
+	}
+	y += line / 2
+	for _, hint := range s.err.hints {
+		for _, l := range wrapText(face, "· "+tr(hint), errorScreenWidth-40) {
+			text.Draw(screen, l, face, 20, y, activeTheme.Text)
+			y += line
+		}
+	}
+
+	if path, err := logPath(); err == nil {
+		text.Draw(screen, path, face, 20, errorScreenHeight-62, color.RGBA{150, 150, 150, 255})
+	}
+	s.ui.drawButton(screen, s.openBtn)
+	s.ui.drawButton(screen, s.quitBtn)
+}
+
+func (s *errorScreen) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return errorScreenWidth, errorScreenHeight
+}
@@ -0,0 +1,62 @@
+package main
+
+import "time"
+
+// 游戏事件类型
+type EventType int
+
+const (
+	EventGameWon EventType = iota
+	EventGameLost
+	EventGameAbandoned // 对局进行中确认放弃，见 abandon.go
+	EventGameStarted   // 首次点击，地雷已经放好
+	EventCellRevealed  // 翻开格子，包括踩中地雷
+	EventFlagToggled   // 切换格子的标记
+)
+
+type Event struct {
+	Type           EventType
+	Difficulty     Difficulty
+	Elapsed        time.Duration
+	Seed           int64
+	StartX, StartY int             // 首次点击位置，与种子共同决定地雷布局
+	Moves          []move          // 本局的操作记录
+	Challenge      *challenge      // 所属的每日/每周挑战，普通对局为 nil
+	Variant        string          // 非默认拓扑的名称，不计入普通对局的成绩
+	Hash           string          // 地雷布局的哈希
+	BBBV           int             // 棋盘的 3BV
+	Progress       []time.Duration // 翻开比例达到每个百分点的时间，见 splits.go
+	SafeCell       bool            // 安全区只有首次点击的格子，重玩时按同样的方式布雷
+	X, Y           int             // 操作的格子，用于开局、翻开和标记事件
+	Opened         []cellPos       // 本次连锁翻开的格子，踩中地雷时为空
+}
+
+// 简单的同步事件总线，订阅者在发布时依次被调用
+type EventBus struct {
+	handlers map[EventType][]func(Event)
+}
+
+func (b *EventBus) Subscribe(t EventType, handler func(Event)) {
+	if b.handlers == nil {
+		b.handlers = make(map[EventType][]func(Event))
+	}
+	b.handlers[t] = append(b.handlers[t], handler)
+}
+
+func (b *EventBus) Publish(e Event) {
+	for _, handler := range b.handlers[e.Type] {
+		handler(e)
+	}
+}
+
+// 全局事件总线，重启游戏后订阅依然有效
+var events = &EventBus{}
+
+// 对局内的功能订阅单局事件，不再直接写在翻开和标记的流程中。
+// newRound 通过 Reset 原地重置对局，Game 的指针不变，订阅一直有效
+func (g *Game) subscribe(bus *EventBus) {
+	g.subscribeSounds(bus)
+	g.subscribeRecorder(bus)
+	g.subscribeAnimations(bus)
+	g.subscribeRace(bus)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 踩中地雷时的爆炸效果：碎片从地雷处向四周飞散，棋盘短暂震动。
+// 效果播放期间锁定输入，结束后才显示游戏结束的遮罩。关闭“动画”时直接显示结果
+const (
+	explosionDuration  = 700 * time.Millisecond
+	explosionParticles = 40
+	explosionGravity   = 600 // 碎片下落的加速度，像素每平方秒
+	shakeDuration      = 300 * time.Millisecond
+	shakeAmplitude     = 6 // 震动开始时的最大偏移，像素
+)
+
+// 碎片的颜色，使用非预乘的 NRGBA 以便单独调整透明度
+var explosionColors = []color.NRGBA{
+	{255, 200, 60, 255},
+	{250, 120, 40, 255},
+	{220, 50, 40, 255},
+	{90, 90, 90, 255},
+}
+
+// 一个碎片，位置相对爆炸中心，速度单位为像素每秒
+type particle struct {
+	vx, vy float64
+	size   float32
+	life   time.Duration
+	clr    color.NRGBA
+}
+
+type explosion struct {
+	x, y      int // 踩中的格子
+	start     time.Time
+	elapsed   time.Duration
+	particles []particle
+}
+
+// 在 (x, y) 的格子开始爆炸效果
+func (g *Game) explode(x, y int) {
+	if !globalConfig.Animations {
+		return
+	}
+	e := &explosion{x: x, y: y, start: time.Now()}
+	for i := 0; i < explosionParticles; i++ {
+		angle := rand.Float64() * 2 * math.Pi
+		speed := 80 + rand.Float64()*220
+		e.particles = append(e.particles, particle{
+			vx:   math.Cos(angle) * speed,
+			vy:   math.Sin(angle)*speed - 120, // 略微向上抛出
+			size: 2 + 3*rand.Float32(),
+			life: explosionDuration/2 + time.Duration(rand.Int64N(int64(explosionDuration/2))),
+			clr:  explosionColors[rand.IntN(len(explosionColors))],
+		})
+	}
+	g.animations = append(g.animations, e)
+}
+
+func (e *explosion) tick(now time.Time) bool {
+	e.elapsed = now.Sub(e.start)
+	return e.elapsed < explosionDuration
+}
+
+// 进行中的爆炸效果，没有时返回 nil
+func (g *Game) exploding() *explosion {
+	for _, a := range g.animations {
+		if e, ok := a.(*explosion); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// 棋盘当前的震动偏移，幅度随时间线性衰减
+func (g *Game) shakeOffset() (float64, float64) {
+	e := g.exploding()
+	if e == nil || e.elapsed >= shakeDuration {
+		return 0, 0
+	}
+	f := e.elapsed.Seconds()
+	amp := shakeAmplitude * (1 - float64(e.elapsed)/float64(shakeDuration))
+	return math.Round(amp * math.Sin(f*90)), math.Round(amp * math.Cos(f*70))
+}
+
+// 在棋盘上画出飞散的碎片，寿命将尽时逐渐变淡
+func (g *Game) drawExplosion(view *ebiten.Image) {
+	e := g.exploding()
+	if e == nil {
+		return
+	}
+	px, py := g.cellScreenPos(e.x, e.y)
+	dx, dy := g.shakeOffset()
+	cx, cy := float64(px)+cellSize/2+dx, float64(py)+cellSize/2+dy
+	t := e.elapsed.Seconds()
+	for _, p := range e.particles {
+		if e.elapsed >= p.life {
+			continue
+		}
+		x := cx + p.vx*t
+		y := cy + p.vy*t + explosionGravity*t*t/2
+		clr := p.clr
+		clr.A = uint8(255 * (1 - float64(e.elapsed)/float64(p.life)))
+		vector.DrawFilledRect(view, float32(x)-p.size/2, float32(y)-p.size/2, p.size, p.size, clr, false)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// 实验性功能：默认关闭，在配置文件的 experiments 中开启，例如
+//
+//	"experiments": {"defuse_mode": true}
+//
+// 功能稳定后删除对应的开关，直接启用
+const (
+	expDefuseMode  = "defuse_mode"   // 对战大厅中的拆弹模式
+	expDeepNoGuess = "deep_no_guess" // 无猜模式的深度推理难度，生成较慢
+)
+
+var experiments = map[string]string{
+	expDefuseMode:  "局域网拆弹模式：两人分别只能看到数字和探测图",
+	expDeepNoGuess: "无猜模式的深度推理难度",
+}
+
+func experimentEnabled(name string) bool {
+	return globalConfig.Experiments[name]
+}
+
+// 配置中出现未知的开关时提示，通常是拼写错误或功能已经转正
+func checkExperiments(cfg *Config) {
+	var unknown []string
+	for name := range cfg.Experiments {
+		if _, ok := experiments[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		slog.Warn("未知的实验功能", "name", name)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const focusGuardFrames = 2 // 窗口恢复后忽略输入的帧数，获得焦点和点击事件可能相差一帧
+
+// 窗口最小化和失去焦点的状态。全局保存，跨对局有效
+var windowState struct {
+	minimized   bool
+	focused     bool
+	since       time.Time // 最小化的时间
+	guardFrames int
+}
+
+func init() {
+	windowState.focused = true
+}
+
+// 跟踪窗口状态，返回 true 表示本帧应忽略输入。失去焦点但仍可见时照常更新，
+// 最小化期间暂停计时；窗口恢复或重新获得焦点时，激活窗口的那次点击不会落到格子上
+func (g *Game) updateWindowState() bool {
+	minimized := ebiten.IsWindowMinimized()
+	focused := ebiten.IsFocused() && !minimized
+
+	if minimized && !windowState.minimized {
+		windowState.since = time.Now()
+	}
+	if !minimized && windowState.minimized {
+		g.resumeTimer(time.Since(windowState.since))
+	}
+	if focused && !windowState.focused {
+		windowState.guardFrames = focusGuardFrames
+	}
+	windowState.minimized, windowState.focused = minimized, focused
+
+	if minimized {
+		return true
+	}
+	if windowState.guardFrames > 0 {
+		windowState.guardFrames--
+		return true
+	}
+	return false
+}
+
+// 把最小化的时间从计时中扣除。联机对局的对手不会暂停，计时照常进行
+func (g *Game) resumeTimer(paused time.Duration) {
+	if !g.inProgress() || g.race != nil || g.coop != nil {
+		return
+	}
+	g.startTime = g.startTime.Add(paused)
+	g.elapsedTime = time.Since(g.startTime)
+}
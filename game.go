@@ -1,658 +1,1175 @@
-package main
-
-import (
-	"bytes"
-	"fmt"
-	"image"
-	"image/color"
-	_ "image/png"
-	"math/rand"
-	"os"
-	"time"
-
-	"minesweeper/assets"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/audio/wav"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/text"
-	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/font/opentype"
-)
-
-type Cell struct {
-	hasMine   bool
-	revealed  bool
-	flagged   bool
-	neighbors int
-}
-
-// 难度级别
-type Difficulty int
-
-const (
-	Easy Difficulty = iota
-	Medium
-	Hard
-)
-
-// 难度配置
-type DifficultyConfig struct {
-	GridWidth  int
-	GridHeight int
-	MineCount  int
-}
-
-var difficultySettings = map[Difficulty]DifficultyConfig{
-	Easy:   {9, 9, 10},
-	Medium: {16, 16, 40},
-	Hard:   {30, 16, 99},
-}
-
-type Game struct {
-	grid                  [][]Cell
-	gameOver              bool
-	won                   bool
-	difficulty            Difficulty
-	firstClick            bool
-	startTime             time.Time
-	elapsedTime           time.Duration
-	images                map[string]*ebiten.Image
-	currentScore          int
-	audioContext          *audio.Context
-	sounds                map[string]*audio.Player
-	restartBtn            *Button
-	difficultyBtn         *Button
-	gameFont              font.Face
-	difficultyButtons     []*Button
-	showingDifficultyMenu bool
-	gridWidth             int
-	gridHeight            int
-}
-
-// 添加按钮结构体
-type Button struct {
-	X, Y, W, H int
-	Text       string
-	Hover      bool
-	Difficulty Difficulty
-}
-
-// 添加按钮点击检测方法
-func (b *Button) Contains(x, y int) bool {
-	return x >= b.X && x < b.X+b.W && y >= b.Y && y < b.Y+b.H
-}
-
-// 添加全局音频上下文
-var globalAudioContext *audio.Context
-
-func loadGameAssets() (map[string]*ebiten.Image, error) {
-	images := make(map[string]*ebiten.Image)
-	imageFiles := []string{"tile.png", "mine.png", "flag.png", "revealed.png"}
-
-	for _, filename := range imageFiles {
-		data, err := assets.GetImage(filename)
-		if err != nil {
-			return nil, fmt.Errorf("加载图片失败 %s: %v", filename, err)
-		}
-
-		img, _, err := image.Decode(bytes.NewReader(data))
-		if err != nil {
-			return nil, fmt.Errorf("解码图片失败 %s: %v", filename, err)
-		}
-
-		images[filename[:len(filename)-4]] = ebiten.NewImageFromImage(img)
-	}
-	return images, nil
-}
-
-func loadGameSounds(audioContext *audio.Context) (map[string]*audio.Player, error) {
-	sounds := make(map[string]*audio.Player)
-	soundFiles := []string{"click.wav", "explosion.wav", "win.wav", "flag.wav"}
-
-	for _, filename := range soundFiles {
-		data, err := assets.GetSound(filename)
-		if err != nil {
-			return nil, fmt.Errorf("加载音效失败 %s: %v", filename, err)
-		}
-
-		d, err := wav.DecodeWithSampleRate(audioContext.SampleRate(), bytes.NewReader(data))
-		if err != nil {
-			return nil, fmt.Errorf("解码音效失败 %s: %v", filename, err)
-		}
-
-		p, err := audioContext.NewPlayer(d)
-		if err != nil {
-			return nil, fmt.Errorf("创建播放器失败 %s: %v", filename, err)
-		}
-
-		sounds[filename[:len(filename)-4]] = p
-	}
-	return sounds, nil
-}
-
-func loadGameFont() (font.Face, error) {
-	// Windows 中文字体路径列表
-	fontPaths := []string{
-		"C:\\Windows\\Fonts\\simhei.ttf",                            // 黑体
-		"C:\\Windows\\Fonts\\simkai.ttf",                            // 楷体
-		"C:\\Windows\\Fonts\\simsun.ttc",                            // 宋体
-		"C:\\Windows\\Fonts\\msyh.ttc",                              // 微软雅黑
-		"C:\\Windows\\Fonts\\msyhbd.ttc",                            // 微软雅黑粗体
-		"C:\\Windows\\Fonts\\simfang.ttf",                           // 仿宋
-		"/System/Library/Fonts/PingFang.ttc",                        // macOS
-		"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf", // Linux
-	}
-
-	var fontData []byte
-	var err error
-
-	// 尝试读取系统字体
-	for _, path := range fontPaths {
-		fontData, err = os.ReadFile(path)
-		if err == nil {
-			break
-		}
-	}
-
-	if err != nil {
-		// 如果找不到系统字体，直接返回基础字体
-		return basicfont.Face7x13, nil
-	}
-
-	// 解析字体文件
-	tt, err := opentype.Parse(fontData)
-	if err != nil {
-		return nil, fmt.Errorf("解析字体失败: %v", err)
-	}
-
-	const dpi = 72
-	face, err := opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    16, // 增大字体大小
-		DPI:     dpi,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("创建字体失败: %v", err)
-	}
-
-	return face, nil
-}
-
-func NewGame(difficulty Difficulty) (*Game, error) {
-	config := difficultySettings[difficulty]
-	images, err := loadGameAssets()
-	if err != nil {
-		return nil, err
-	}
-
-	// 只在第一次创建音频上下文
-	if globalAudioContext == nil {
-		globalAudioContext = audio.NewContext(44100)
-	}
-
-	sounds, err := loadGameSounds(globalAudioContext)
-	if err != nil {
-		return nil, err
-	}
-
-	gameFont, err := loadGameFont()
-	if err != nil {
-		return nil, err
-	}
-
-	g := &Game{
-		grid:         make([][]Cell, config.GridHeight),
-		difficulty:   difficulty,
-		firstClick:   true,
-		images:       images,
-		audioContext: globalAudioContext,
-		sounds:       sounds,
-		gameFont:     gameFont,
-		restartBtn: &Button{
-			Text: "重启", // 简化按钮文字
-			W:    120,
-			H:    30,
-		},
-		difficultyBtn: &Button{
-			Text: "难度", // 简化按钮文字
-			W:    120,
-			H:    30,
-		},
-		gridWidth:             config.GridWidth,
-		gridHeight:            config.GridHeight,
-		showingDifficultyMenu: false,
-	}
-
-	for i := range g.grid {
-		g.grid[i] = make([]Cell, config.GridWidth)
-	}
-
-	// 初始化难度选择按钮
-	g.initDifficultyButtons()
-
-	return g, nil
-}
-
-func (g *Game) initDifficultyButtons() {
-	btnWidth := 150
-	btnHeight := 40
-	spacing := 20
-
-	// 计算起始Y坐标
-	startY := (g.gridHeight*cellSize)/2 - (3*btnHeight+2*spacing)/2
-	centerX := (g.gridWidth*cellSize - btnWidth) / 2
-
-	g.difficultyButtons = []*Button{
-		{
-			X:          centerX,
-			Y:          startY,
-			W:          btnWidth,
-			H:          btnHeight,
-			Text:       "简单模式",
-			Difficulty: Easy,
-		},
-		{
-			X:          centerX,
-			Y:          startY + btnHeight + spacing,
-			W:          btnWidth,
-			H:          btnHeight,
-			Text:       "中等模式",
-			Difficulty: Medium,
-		},
-		{
-			X:          centerX,
-			Y:          startY + 2*btnHeight + 2*spacing,
-			W:          btnWidth,
-			H:          btnHeight,
-			Text:       "困难模式",
-			Difficulty: Hard,
-		},
-	}
-}
-
-func (g *Game) placeMines() {
-	config := difficultySettings[g.difficulty]
-	rand.Seed(time.Now().UnixNano())
-	minesPlaced := 0
-
-	for minesPlaced < config.MineCount {
-		x := rand.Intn(config.GridWidth)
-		y := rand.Intn(config.GridHeight)
-
-		if !g.grid[y][x].hasMine {
-			g.grid[y][x].hasMine = true
-			minesPlaced++
-		}
-	}
-}
-
-func (g *Game) calculateNeighbors() {
-	config := difficultySettings[g.difficulty]
-	for y := 0; y < config.GridHeight; y++ {
-		for x := 0; x < config.GridWidth; x++ {
-			if !g.grid[y][x].hasMine {
-				count := 0
-				// 检查周围8个方向
-				for dy := -1; dy <= 1; dy++ {
-					for dx := -1; dx <= 1; dx++ {
-						newY := y + dy
-						newX := x + dx
-						if newY >= 0 && newY < config.GridHeight && newX >= 0 && newX < config.GridWidth {
-							if g.grid[newY][newX].hasMine {
-								count++
-							}
-						}
-					}
-				}
-				g.grid[y][x].neighbors = count
-			}
-		}
-	}
-}
-
-func (g *Game) Update() error {
-	x, y := ebiten.CursorPosition()
-
-	if g.showingDifficultyMenu {
-		// 处理难度选择
-		for _, btn := range g.difficultyButtons {
-			btn.Hover = btn.Contains(x, y)
-			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && btn.Contains(x, y) {
-				// 创建新游戏实例
-				newGame, err := NewGame(btn.Difficulty)
-				if err != nil {
-					return err
-				}
-
-				// 保留音频上下文
-				newGame.audioContext = g.audioContext
-				newGame.sounds = g.sounds
-
-				// 更新窗口尺寸
-				config := difficultySettings[btn.Difficulty]
-				windowWidth := config.GridWidth * cellSize
-				windowHeight := config.GridHeight*cellSize + 80
-				ebiten.SetWindowSize(windowWidth, windowHeight)
-
-				*g = *newGame
-				g.startTime = time.Now()
-				g.showingDifficultyMenu = false
-				g.firstClick = false
-				g.playSound("click")
-				// 完全重置地雷布局
-				for y := range g.grid {
-					for x := range g.grid[y] {
-						g.grid[y][x] = Cell{}
-					}
-				}
-				g.initializeGridSafely(-1, -1)
-				return nil
-			}
-		}
-		return nil
-	}
-
-	// 更新按钮悬停状态
-	g.restartBtn.Hover = g.restartBtn.Contains(x, y)
-	g.difficultyBtn.Hover = g.difficultyBtn.Contains(x, y)
-
-	if g.gameOver || g.won {
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			if g.restartBtn.Contains(x, y) {
-				// 重新开始当前难度
-				newGame, err := NewGame(g.difficulty)
-				if err != nil {
-					return err
-				}
-				// 保留原有的音频上下文
-				oldContext := g.audioContext
-				oldSounds := g.sounds
-				*g = *newGame
-				g.audioContext = oldContext
-				g.sounds = oldSounds
-				// 重置关键游戏状态
-				g.startTime = time.Now()
-				g.elapsedTime = 0
-				g.gameOver = false
-				g.won = false
-				g.initializeGridSafely(-1, -1) // 重新生成地雷
-				g.playSound("click")
-			} else if g.difficultyBtn.Contains(x, y) {
-				g.showingDifficultyMenu = true
-				g.playSound("click")
-			}
-		}
-		return nil
-	}
-
-	// 更新计时器
-	if !g.firstClick && !g.gameOver && !g.won {
-		g.elapsedTime = time.Since(g.startTime)
-	}
-
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		x, y := ebiten.CursorPosition()
-		gridX := x / cellSize
-		gridY := y / cellSize
-
-		config := difficultySettings[g.difficulty]
-		if gridX >= 0 && gridX < config.GridWidth && gridY >= 0 && gridY < config.GridHeight {
-			if !g.grid[gridY][gridX].flagged {
-				if g.firstClick {
-					g.playSound("click")
-					g.firstClick = false
-					g.startTime = time.Now()
-					g.initializeGridSafely(gridX, gridY)
-				}
-
-				if g.grid[gridY][gridX].hasMine {
-					g.playSound("explosion")
-					g.gameOver = true
-					g.revealAllMines()
-				} else {
-					g.playSound("click")
-					g.revealCell(gridX, gridY)
-				}
-			}
-		}
-	}
-
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
-		x, y := ebiten.CursorPosition()
-		gridX := x / cellSize
-		gridY := y / cellSize
-
-		if gridX >= 0 && gridX < gridWidth && gridY >= 0 && gridY < gridHeight {
-			if !g.grid[gridY][gridX].revealed {
-				g.playSound("flag")
-				g.grid[gridY][gridX].flagged = !g.grid[gridY][gridX].flagged
-			}
-		}
-	}
-
-	g.checkWin()
-
-	// 修改后的菜单显示条件
-	if g.firstClick && !g.showingDifficultyMenu && !g.gameOver && !g.won {
-		g.showingDifficultyMenu = true
-	}
-
-	return nil
-}
-
-func (g *Game) revealCell(x, y int) {
-	config := difficultySettings[g.difficulty]
-	if x < 0 || x >= config.GridWidth || y < 0 || y >= config.GridHeight {
-		return
-	}
-
-	cell := &g.grid[y][x]
-	if cell.revealed || cell.flagged {
-		return
-	}
-
-	cell.revealed = true
-
-	if cell.neighbors == 0 {
-		// 如果是空白格子，递归显示周围的格子
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				g.revealCell(x+dx, y+dy)
-			}
-		}
-	}
-}
-
-func (g *Game) Draw(screen *ebiten.Image) {
-	config := difficultySettings[g.difficulty]
-
-	for y := 0; y < config.GridHeight; y++ {
-		for x := 0; x < config.GridWidth; x++ {
-			cell := g.grid[y][x]
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(x*cellSize), float64(y*cellSize))
-
-			if cell.revealed {
-				if cell.hasMine {
-					screen.DrawImage(g.images["mine"], op)
-				} else {
-					screen.DrawImage(g.images["revealed"], op)
-					if cell.neighbors > 0 {
-						text := fmt.Sprintf("%d", cell.neighbors)
-						ebitenutil.DebugPrintAt(screen, text, x*cellSize+cellSize/3, y*cellSize+cellSize/3)
-					}
-				}
-			} else {
-				screen.DrawImage(g.images["tile"], op)
-				if cell.flagged {
-					screen.DrawImage(g.images["flag"], op)
-				}
-			}
-		}
-	}
-
-	// 更新按钮位置（在网格下方）
-	g.restartBtn.X = 10
-	g.restartBtn.Y = config.GridHeight*cellSize + 20
-	g.difficultyBtn.X = 140
-	g.difficultyBtn.Y = config.GridHeight*cellSize + 20
-
-	// 显示计时器
-	timeStr := fmt.Sprintf("时间: %02d:%02d",
-		int(g.elapsedTime.Seconds())/60,
-		int(g.elapsedTime.Seconds())%60)
-	text.Draw(screen, timeStr, g.gameFont, 10, config.GridHeight*cellSize+15,
-		color.White)
-
-	if g.gameOver || g.won {
-		// 绘制半透明遮罩
-		overlay := ebiten.NewImage(config.GridWidth*cellSize, config.GridHeight*cellSize)
-		overlay.Fill(color.RGBA{0, 0, 0, 180})
-		screen.DrawImage(overlay, nil)
-
-		// 显示游戏结果
-		msg := "游戏结束"
-		if g.won {
-			msg = "胜利" // 简化文字
-		}
-
-		// 使用更大的字体绘制消息
-		bounds, _ := font.BoundString(g.gameFont, msg)
-		width := (bounds.Max.X - bounds.Min.X).Ceil()
-		height := (bounds.Max.Y - bounds.Min.Y).Ceil()
-		msgX := (config.GridWidth*cellSize - width) / 2
-		msgY := config.GridHeight*cellSize/2 - height/2
-		text.Draw(screen, msg, g.gameFont, msgX, msgY, color.White)
-
-		// 绘制按钮
-		g.drawButton(screen, g.restartBtn)
-		g.drawButton(screen, g.difficultyBtn)
-	}
-
-	if g.showingDifficultyMenu {
-		// 绘制半透明背景
-		overlay := ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
-		overlay.Fill(color.RGBA{0, 0, 0, 200})
-		screen.DrawImage(overlay, nil)
-
-		// 绘制难度选择按钮
-		for _, btn := range g.difficultyButtons {
-			g.drawButton(screen, btn)
-		}
-	}
-}
-
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	config := difficultySettings[g.difficulty]
-	return config.GridWidth * cellSize, config.GridHeight*cellSize + 80
-}
-
-func (g *Game) checkWin() {
-	if g.firstClick {
-		return // 首次点击前不检查胜利条件
-	}
-
-	config := difficultySettings[g.difficulty]
-	won := true
-	for y := 0; y < config.GridHeight; y++ {
-		for x := 0; x < config.GridWidth; x++ {
-			cell := g.grid[y][x]
-			if (!cell.hasMine && !cell.revealed) || (cell.hasMine && !cell.flagged && !cell.revealed) {
-				won = false
-				break
-			}
-		}
-	}
-	g.won = won
-}
-
-func (g *Game) initializeGridSafely(firstX, firstY int) {
-	config := difficultySettings[g.difficulty]
-
-	// 清除首次点击位置周围的地雷
-	safeZone := make(map[string]bool)
-	for dy := -1; dy <= 1; dy++ {
-		for dx := -1; dx <= 1; dx++ {
-			newY := firstY + dy
-			newX := firstX + dx
-			if newY >= 0 && newY < config.GridHeight && newX >= 0 && newX < config.GridWidth {
-				safeZone[fmt.Sprintf("%d,%d", newX, newY)] = true
-			}
-		}
-	}
-
-	// 放置地雷，避开安全区域
-	minesPlaced := 0
-	for minesPlaced < config.MineCount {
-		x := rand.Intn(config.GridWidth)
-		y := rand.Intn(config.GridHeight)
-		pos := fmt.Sprintf("%d,%d", x, y)
-
-		if !g.grid[y][x].hasMine && !safeZone[pos] {
-			g.grid[y][x].hasMine = true
-			minesPlaced++
-		}
-	}
-
-	g.calculateNeighbors()
-}
-
-func (g *Game) revealAllMines() {
-	config := difficultySettings[g.difficulty]
-	for y := 0; y < config.GridHeight; y++ {
-		for x := 0; x < config.GridWidth; x++ {
-			if g.grid[y][x].hasMine {
-				g.grid[y][x].revealed = true
-			}
-		}
-	}
-}
-
-func (g *Game) playSound(name string) {
-	if player, ok := g.sounds[name]; ok {
-		player.Rewind()
-		player.Play()
-	}
-}
-
-// 添加按钮绘制方法
-func (g *Game) drawButton(screen *ebiten.Image, btn *Button) {
-	// 绘制按钮背景
-	bgColor := color.RGBA{60, 60, 60, 255}
-	if btn.Hover {
-		bgColor = color.RGBA{80, 80, 80, 255}
-	}
-
-	// 绘制按钮边框
-	borderColor := color.RGBA{120, 120, 120, 255}
-
-	vector.DrawFilledRect(
-		screen,
-		float32(btn.X), float32(btn.Y),
-		float32(btn.W), float32(btn.H),
-		bgColor,
-		false, // 关闭抗锯齿
-	)
-
-	vector.StrokeRect(
-		screen,
-		float32(btn.X), float32(btn.Y),
-		float32(btn.W), float32(btn.H),
-		1, // 边框线宽
-		borderColor,
-		false, // 关闭抗锯齿
-	)
-
-	// 绘制按钮文字
-	bounds, _ := font.BoundString(g.gameFont, btn.Text)
-	textWidth := (bounds.Max.X - bounds.Min.X).Ceil()
-	textHeight := (bounds.Max.Y - bounds.Min.Y).Ceil()
-	textX := btn.X + (btn.W-textWidth)/2
-	textY := btn.Y + (btn.H+textHeight)/2
-	text.Draw(screen, btn.Text, g.gameFont, textX, textY, color.White)
-}
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"minesweeper/assets"
+	"minesweeper/board"
+	"minesweeper/storage"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+type Cell struct {
+	hasMine    bool
+	revealed   bool
+	flagged    bool
+	questioned bool
+	neighbors  int
+}
+
+// 难度级别
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Medium
+	Hard
+	Huge   // 超出窗口的大棋盘，通过镜头滚动查看
+	Custom // 由命令行参数指定尺寸，未指定时不可用
+)
+
+var difficultyNames = map[Difficulty]string{Easy: "简单", Medium: "中等", Hard: "困难", Huge: "巨大", Custom: "自定义"}
+
+// 难度配置
+type DifficultyConfig struct {
+	GridWidth  int
+	GridHeight int
+	MineCount  int
+}
+
+var difficultySettings = map[Difficulty]DifficultyConfig{
+	Easy:   {9, 9, 10},
+	Medium: {16, 16, 40},
+	Hard:   {30, 16, 99},
+	Huge:   {100, 100, 1000},
+}
+
+// Game 的字段分为两部分：这里是跨局保留的资源、按钮和界面状态，
+// 每局重新开始的状态在 round 中，由 Reset 整体替换
+type Game struct {
+	*AssetManager
+	restartBtn         *Button
+	difficultyBtn      *Button
+	difficultyButtons  []*Button
+	variantButtons     []*menuButton
+	scene              Scene
+	menuButtons        []*menuButton
+	reviewBtn          *Button
+	gifBtn             *Button // 结果界面中导出 GIF 的按钮，见 gifexport.go
+	smileyBtn, hintBtn *Button // 状态栏中的笑脸和提示按钮，见 statusbar.go
+	news               []changelogEntry
+	rng                *rand.Rand // 生成新的种子，跨局保留，见 NewGameFrom
+	seedScroll         int
+	boardCache         *boardCache
+	minimap            minimap
+	restore            *checkpoint // 启动时发现的自动存档，等待玩家决定是否恢复
+	palette            *commandPalette
+	store              storage.Storage // 当前档案的存储，见 loadProfile
+	round
+}
+
+// 一局的状态，开始新的一局时全部重置，见 Game.Reset
+type round struct {
+	grid                  [][]Cell
+	gameOver              bool
+	won                   bool
+	difficulty            Difficulty
+	firstClick            bool
+	startTime             time.Time
+	elapsedTime           time.Duration
+	currentScore          int
+	showingDifficultyMenu bool
+	gridWidth             int
+	gridHeight            int
+	size                  DifficultyConfig // 本局的棋盘大小和地雷数，谜题等自定义大小的对局不改写 difficultySettings
+	transition            *boardTransition
+	explodedX, explodedY  int // 踩中的地雷位置，仅在 gameOver 时有效
+	moves                 []move
+	review                *gameAnalysis
+	showingReview         bool
+	abandon               *abandonPrompt
+	bbbv                  int       // 本局棋盘的 3BV，放置地雷后计算
+	boardHash             string    // 地雷布局的哈希，放置地雷后计算，见 board.Hash
+	restartConfirmUntil   time.Time // 在此之前再次按重启键才会重启
+	seed                  int64     // 地雷布局的随机种子
+	minesPlaced           bool
+	startX, startY        int // 地雷布局的安全区中心，-1 表示没有安全区
+	lobby                 *raceLobby
+	race                  *raceState
+	coop                  *coopState
+	challenge             *challenge // 当前对局所属的每日/每周挑战
+	rivals                *rivalsView
+	revealedSafe          int         // 已翻开的安全格子数
+	flaggedMines          int         // 插对旗的地雷数
+	camX, camY            int         // 镜头左上角在棋盘上的像素坐标，棋盘比窗口大时才会移动
+	wave                  *revealWave // 连锁翻开的波纹，见 wave.go
+	animations            []animation // 其他进行中的动画，见 animation.go
+	ticks                 int         // 本局经过的 tick 数，推进多帧贴图
+	lostTick              int         // 踩中地雷时的 tick 数
+	endless               *endlessBoard
+	hex                   *hexBoard
+	topology              board.Topology  // 格子的相邻关系，见 topology.go
+	shape                 *board.Mask     // 不规则形状棋盘的轮廓，nil 表示完整的矩形，见 shape.go
+	puzzle                *puzzle         // 正在玩的谜题，见 puzzle.go
+	bot                   *botState       // 机器人演示，见 bot.go
+	noGuess               *noGuessSearch  // 进行中的无猜布局搜索，见 noguess.go
+	challengeFetch        chan challenge  // 获取中的挑战，开始新的一局时丢弃
+	lives                 int             // 轻松模式的生命数，见 lives.go
+	relocations           []relocation    // 踩中后移走的地雷
+	noFlags               bool            // 无旗模式，对局开始时按设置确定
+	countdown             bool            // 限时模式，对局开始时按设置确定，见 countdown.go
+	timeUp                bool            // 限时模式中时间用完
+	lastTick              int             // 最近一次倒计时提示音时剩余的秒数
+	arcade                *arcadeRun      // 街机模式的当前一轮，见 arcade.go
+	splits                []time.Duration // 翻开比例首次达到每个百分点的时间，见 splits.go
+	lastSplit             *splitResult    // 最近一个分段与个人最佳的差值
+	retry                 bool            // 重试同一个棋盘，见 retryBoard
+	assisted              bool            // 对局中看到过地雷位置或推理结果，见 variant
+	safeCell              bool            // 安全区只有首次点击的格子，见 safestart.go
+	paused                bool            // 暂停中，棋盘隐藏，见 input.go
+	chordHeld             bool            // 翻开和插旗的按键同时按住，见 updateChordGesture
+	chordFired            bool            // 本次同时按下已经快速翻开过
+	press                 *cellPress      // 按住未松开的翻开或快速翻开，松开时执行
+	pausedAt              time.Time
+}
+
+// 添加按钮结构体
+type Button struct {
+	X, Y, W, H int
+	Text       string
+	Hover      bool
+	Disabled   bool
+	Difficulty Difficulty
+}
+
+// 添加按钮点击检测方法
+func (b *Button) Contains(x, y int) bool {
+	return x >= b.X && x < b.X+b.W && y >= b.Y && y < b.Y+b.H
+}
+
+// 同一贴图的多个分辨率版本，键为边长像素
+type mipmap map[int]*ebiten.Image
+
+// 选择最接近 size 的版本，距离相同时取较大的
+func (m mipmap) nearest(size float64) *ebiten.Image {
+	var best *ebiten.Image
+	bestDiff := math.MaxFloat64
+	for s, img := range m {
+		diff := math.Abs(float64(s) - size)
+		if diff < bestDiff || (diff == bestDiff && img.Bounds().Dx() > best.Bounds().Dx()) {
+			best, bestDiff = img, diff
+		}
+	}
+	return best
+}
+
+func loadGameAssets() (map[string]mipmap, error) {
+	images := make(map[string]mipmap)
+	imageNames := []string{"tile", "mine", "flag", "revealed", "misflag", "exploded",
+		"hextile", "hexmine", "hexflag", "hexrevealed", "hexmisflag", "hexexploded"}
+
+	filenames, err := assets.ListImages()
+	if err != nil {
+		return nil, fmt.Errorf("读取图片列表失败: %v", err)
+	}
+
+	// 文件名格式为 名称_尺寸.png
+	for _, filename := range filenames {
+		base := strings.TrimSuffix(filename, ".png")
+		sep := strings.LastIndex(base, "_")
+		if sep < 0 {
+			continue
+		}
+		size, err := strconv.Atoi(base[sep+1:])
+		if err != nil {
+			continue
+		}
+
+		data, err := assets.GetImage(filename)
+		if err != nil {
+			return nil, fmt.Errorf("加载图片失败 %s: %v", filename, err)
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解码图片失败 %s: %v", filename, err)
+		}
+
+		// 多帧贴图的各帧横向排列，按高度切分，每帧单独作为一张贴图
+		name := base[:sep]
+		strip := ebiten.NewImageFromImage(img)
+		bounds := img.Bounds()
+		for f := 0; f*bounds.Dy() < bounds.Dx(); f++ {
+			frame := name
+			if bounds.Dx() > bounds.Dy() {
+				frame = frameName(name, f)
+			}
+			if images[frame] == nil {
+				images[frame] = make(mipmap)
+			}
+			rect := image.Rect(f*bounds.Dy(), 0, (f+1)*bounds.Dy(), bounds.Dy())
+			images[frame][size] = strip.SubImage(rect).(*ebiten.Image)
+		}
+	}
+
+	for _, name := range imageNames {
+		if len(images[name]) == 0 {
+			return nil, fmt.Errorf("缺少图片 %s", name)
+		}
+	}
+	for _, a := range spriteAnimations {
+		for f := 0; f < a.frames; f++ {
+			if len(images[frameName(a.strip, f)]) == 0 {
+				return nil, fmt.Errorf("缺少图片 %s", frameName(a.strip, f))
+			}
+		}
+	}
+	return images, nil
+}
+
+func loadGameSounds(audioContext *audio.Context, pack soundPack) (map[string]*audio.Player, error) {
+	sounds := make(map[string]*audio.Player)
+	soundFiles := []string{"click.wav", "explosion.wav", "win.wav", "flag.wav", "ping.wav", "cascade.wav"}
+
+	for _, filename := range soundFiles {
+		data, err := assets.GetSound(filename)
+		if err != nil {
+			return nil, fmt.Errorf("加载音效失败 %s: %v", filename, err)
+		}
+
+		d, err := wav.DecodeWithSampleRate(audioContext.SampleRate(), bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解码音效失败 %s: %v", filename, err)
+		}
+
+		pcm, err := io.ReadAll(d)
+		if err != nil {
+			return nil, fmt.Errorf("解码音效失败 %s: %v", filename, err)
+		}
+		pack.apply(pcm)
+		p := audioContext.NewPlayerFromBytes(pcm)
+
+		sounds[filename[:len(filename)-4]] = p
+	}
+	return sounds, nil
+}
+
+func loadGameFont() (font.Face, error) {
+	// Windows 中文字体路径列表
+	fontPaths := []string{
+		"C:\\Windows\\Fonts\\simhei.ttf",                            // 黑体
+		"C:\\Windows\\Fonts\\simkai.ttf",                            // 楷体
+		"C:\\Windows\\Fonts\\simsun.ttc",                            // 宋体
+		"C:\\Windows\\Fonts\\msyh.ttc",                              // 微软雅黑
+		"C:\\Windows\\Fonts\\msyhbd.ttc",                            // 微软雅黑粗体
+		"C:\\Windows\\Fonts\\simfang.ttf",                           // 仿宋
+		"/System/Library/Fonts/PingFang.ttc",                        // macOS
+		"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf", // Linux
+	}
+
+	var fontData []byte
+	var err error
+
+	// 尝试读取系统字体
+	for _, path := range fontPaths {
+		fontData, err = os.ReadFile(path)
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		// 如果找不到系统字体，直接返回基础字体
+		return basicfont.Face7x13, nil
+	}
+
+	// 解析字体文件
+	tt, err := opentype.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("解析字体失败: %v", err)
+	}
+
+	const dpi = 72
+	face, err := opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    16, // 增大字体大小
+		DPI:     dpi,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建字体失败: %v", err)
+	}
+
+	return face, nil
+}
+
+// 程序启动时加载一次的资源，所有对局共用，重启和切换难度时无需重新解码
+type AssetManager struct {
+	images       map[string]mipmap
+	sounds       map[string]*audio.Player
+	soundPack    string // 已加载的音效包，见 syncSoundPack
+	gameFont     font.Face
+	audioContext *audio.Context
+	soundQueue   map[string]int // 本帧请求的音效及次数，见 flushSounds
+}
+
+func NewAssetManager() (*AssetManager, error) {
+	images, err := loadGameAssets()
+	if err != nil {
+		return nil, &startupError{title: "图片资源损坏", err: err, hints: []string{"重新下载或安装游戏"}}
+	}
+
+	// 音频上下文只能创建一次
+	audioContext := audio.NewContext(44100)
+	sounds, err := loadGameSounds(audioContext, soundPacks[0])
+	if err != nil {
+		return nil, &startupError{title: "无法加载音效", err: err, hints: []string{
+			"检查音频设备是否已连接并启用", "检查声卡驱动是否正常", "重新下载或安装游戏"}}
+	}
+
+	gameFont, err := loadGameFont()
+	if err != nil {
+		return nil, &startupError{title: "无法加载字体", err: err, hints: []string{"检查系统中文字体是否完整"}}
+	}
+
+	return &AssetManager{
+		images:       images,
+		sounds:       sounds,
+		soundPack:    soundPacks[0].Name,
+		gameFont:     gameFont,
+		audioContext: audioContext,
+		soundQueue:   make(map[string]int),
+	}, nil
+}
+
+// NewGame 以当前时间为随机来源创建一局
+func NewGame(am *AssetManager, difficulty Difficulty) *Game {
+	return NewGameFrom(am, difficulty, board.NewSource(time.Now().UnixNano()))
+}
+
+// NewGameFrom 从 src 生成本局及之后每局的种子，来源固定时布局的序列可以重现
+func NewGameFrom(am *AssetManager, difficulty Difficulty, src rand.Source) *Game {
+	g := &Game{
+		AssetManager: am,
+		rng:          rand.New(src),
+		store:        storage.NewMemory(),
+		restartBtn: &Button{
+			Text: "重启", // 简化按钮文字
+			W:    120,
+			H:    30,
+		},
+		difficultyBtn: &Button{
+			Text: "难度", // 简化按钮文字
+			W:    120,
+			H:    30,
+		},
+		reviewBtn: &Button{
+			Text: "复盘",
+			W:    120,
+			H:    30,
+		},
+		gifBtn: &Button{
+			Text: "GIF",
+			W:    120,
+			H:    30,
+		},
+		smileyBtn: &Button{},
+		hintBtn:   &Button{},
+	}
+	g.Reset(difficulty)
+	return g
+}
+
+// Reset 原地开始新的一局：round 中的状态全部重置，资源、按钮、随机来源和棋盘缓存保留，
+// 并生成新的种子。地雷在首次点击时才放置
+func (g *Game) Reset(difficulty Difficulty) {
+	g.resetSized(difficulty, g.sizeFor(difficulty))
+}
+
+// 难度对应的棋盘大小。在自定义大小的对局中重新开始或切换玩法时沿用本局的大小
+func (g *Game) sizeFor(difficulty Difficulty) DifficultyConfig {
+	if difficulty == Custom && g.difficulty == Custom && g.size.GridWidth > 0 {
+		return g.size
+	}
+	return difficultySettings[difficulty]
+}
+
+// 与 Reset 相同，棋盘大小由 config 指定
+func (g *Game) resetSized(difficulty Difficulty, config DifficultyConfig) {
+	g.round = round{
+		grid:       make([][]Cell, config.GridHeight),
+		difficulty: difficulty,
+		firstClick: true,
+		lives:      globalConfig.Lives,
+		noFlags:    globalConfig.NoFlags,
+		countdown:  globalConfig.Countdown,
+		gridWidth:  config.GridWidth,
+		gridHeight: config.GridHeight,
+		size:       config,
+		seed:       g.rng.Int64(),
+		topology:   board.Square8{},
+	}
+	for i := range g.grid {
+		g.grid[i] = make([]Cell, config.GridWidth)
+	}
+
+	// 难度选择按钮的位置随棋盘大小变化
+	g.initDifficultyButtons()
+}
+
+func (g *Game) initDifficultyButtons() {
+	btnWidth := 250 // 按钮上同时显示最佳用时和胜率
+	btnHeight := 40
+	spacing := 20
+
+	// 计算起始Y坐标
+	startY := g.viewHeight()/2 - (5*btnHeight+4*spacing)/2
+	centerX := (g.viewWidth() - btnWidth) / 2
+
+	g.difficultyButtons = []*Button{
+		{
+			X:          centerX,
+			Y:          startY,
+			W:          btnWidth,
+			H:          btnHeight,
+			Text:       "简单模式",
+			Difficulty: Easy,
+		},
+		{
+			X:          centerX,
+			Y:          startY + btnHeight + spacing,
+			W:          btnWidth,
+			H:          btnHeight,
+			Text:       "中等模式",
+			Difficulty: Medium,
+		},
+		{
+			X:          centerX,
+			Y:          startY + 2*btnHeight + 2*spacing,
+			W:          btnWidth,
+			H:          btnHeight,
+			Text:       "困难模式",
+			Difficulty: Hard,
+		},
+		{
+			X:          centerX,
+			Y:          startY + 3*btnHeight + 3*spacing,
+			W:          btnWidth,
+			H:          btnHeight,
+			Text:       "巨大模式",
+			Difficulty: Huge,
+		},
+	}
+
+	// 难度之后是其他玩法，按当前难度决定棋盘大小
+	g.variantButtons = []*menuButton{
+		{Button: &Button{Text: "六边形"}, action: func() error {
+			g.showingDifficultyMenu = false
+			return g.runCommand("hex")
+		}},
+		{Button: &Button{Text: "环面"}, action: func() error { return g.runCommand("torus") }},
+		{Button: &Button{Text: "马步"}, action: func() error { return g.runCommand("knight") }},
+		{Button: &Button{Text: "形状"}, action: func() error { return g.nextShape() }},
+	}
+	// 横向排成一行，与难度按钮同宽
+	rowWidth := btnWidth
+	w := (rowWidth - 10*(len(g.variantButtons)-1)) / len(g.variantButtons)
+	for i, btn := range g.variantButtons {
+		btn.X = (g.viewWidth()-rowWidth)/2 + i*(w+10)
+		btn.Y = startY + 4*btnHeight + 4*spacing
+		btn.W, btn.H = w, btnHeight
+	}
+}
+
+// 难度按钮上的文字：难度之后是个人最佳用时和胜率，还没玩过时只显示难度
+func difficultyLabel(btn *Button) string {
+	ds, ok := globalStats.Difficulties[statsKey(btn.Difficulty, "")]
+	if !ok || ds.Played == 0 {
+		return tr(btn.Text)
+	}
+	best := "--:--"
+	if ds.BestTime > 0 {
+		best = formatDuration(ds.BestTime)
+	}
+	return fmt.Sprintf("%s  %s · %d%%", tr(btn.Text), best, ds.Won*100/ds.Played)
+}
+
+func (g *Game) Update() error {
+	end := prof.span("input")
+	err := g.update()
+	end()
+
+	// 本帧请求的音效统一在最后播放
+	end = prof.span("audio")
+	g.flushSounds()
+	end()
+	return err
+}
+
+func (g *Game) update() error {
+	// 询问是否放弃时再次关闭窗口视为确认
+	if ebiten.IsWindowBeingClosed() {
+		if g.abandon != nil {
+			g.publish(EventGameAbandoned)
+			return g.quit()
+		}
+		return g.confirmAbandon(g.quit)
+	}
+	if g.abandon != nil {
+		return g.updateAbandon()
+	}
+	if globalInstance != nil {
+		globalInstance.update()
+	}
+
+	g.updateRace()
+	g.updateCoop()
+	g.updatePresence()
+	g.updateRivals()
+	g.updateObserver()
+	g.updateAutosave()
+	g.updateWindowSize()
+	g.updateWindowTitle()
+	g.updateSavedFiles()
+	if err := g.openDroppedPuzzle(); err != nil {
+		return err
+	}
+
+	end := prof.span("animation")
+	g.updateAnimations()
+	animating := g.transition != nil
+	if animating {
+		g.updateTransition()
+	}
+	end()
+
+	// 过渡动画、爆炸效果期间和窗口刚恢复时锁定输入
+	if g.updateWindowState() || animating || g.exploding() != nil {
+		return nil
+	}
+
+	if handled, err := g.updatePalette(); handled {
+		return err
+	}
+	if g.updateRebind() {
+		return nil
+	}
+	if handled, err := g.runHotkeys(false); handled {
+		return err
+	}
+
+	if g.scene != ScenePlaying {
+		return g.updateMenuScene()
+	}
+
+	x, y := cursorPosition()
+
+	if g.showingDifficultyMenu {
+		// 处理难度选择
+		for _, btn := range g.difficultyButtons {
+			btn.Hover = btn.Contains(x, y)
+			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && btn.Contains(x, y) {
+				d := btn.Difficulty
+				return g.confirmAbandon(func() error { return g.newRound(d) })
+			}
+		}
+		for _, btn := range g.variantButtons {
+			btn.Hover = btn.Contains(x, y)
+			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && btn.Hover {
+				g.playSound("click")
+				return g.confirmAbandon(btn.action)
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			g.showingDifficultyMenu = false
+			g.switchScene(SceneMainMenu)
+		}
+		return nil
+	}
+
+	if g.updateCoopInput() || g.updateDebugPick() || g.updateMinimap() {
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.switchScene(SceneMainMenu)
+		return nil
+	}
+
+	if handled, err := g.runHotkeys(true); handled {
+		return err
+	}
+	if g.paused {
+		return nil
+	}
+
+	g.updateCamera()
+	g.updateDebug()
+	g.updateRadar()
+	g.updateBot()
+	g.updateNoGuess()
+	g.updateProbability()
+
+	// 更新按钮悬停状态
+	g.restartBtn.Hover = g.restartBtn.Contains(x, y)
+	g.difficultyBtn.Hover = g.difficultyBtn.Contains(x, y)
+	g.reviewBtn.Hover = g.reviewBtn.Contains(x, y)
+	g.gifBtn.Hover = g.gifBtn.Contains(x, y)
+	if handled, err := g.updateStatusBar(x, y); handled {
+		return err
+	}
+
+	if g.gameOver || g.won || g.raceDecided() {
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			if g.restartBtn.Contains(x, y) {
+				// 重新开始当前难度
+				return g.restartRound()
+			} else if g.difficultyBtn.Contains(x, y) {
+				g.showingDifficultyMenu = true
+				g.playSound("click")
+			} else if g.reviewBtn.Contains(x, y) {
+				g.toggleReview()
+				g.playSound("click")
+			} else if g.gifBtn.Contains(x, y) && !g.gifBtn.Disabled {
+				g.playSound("click")
+				return g.runCommand("export-gif")
+			}
+		}
+		return nil
+	}
+
+	// 更新计时器
+	if !g.firstClick && !g.gameOver && !g.won {
+		g.elapsedTime = time.Since(g.startTime)
+	}
+	g.updateCountdown()
+
+	g.updateCellInput(x, y)
+
+	g.checkWin()
+
+	return nil
+}
+
+// 插了旗的格子不能翻开，已翻开的格子不能插旗，无旗模式中插旗的按键不做任何事
+func (g *Game) canAct(kind moveKind, x, y int) bool {
+	cell := g.grid[y][x]
+	if kind == moveReveal {
+		return !cell.flagged
+	}
+	return !g.noFlags && !cell.revealed
+}
+
+// 翻开 (x, y)，首次点击时放置地雷并开始计时。音效、操作记录和动画由事件的订阅者处理，见 Game.subscribe
+func (g *Game) revealAt(x, y int) {
+	if g.firstClick {
+		// 无猜布局在后台搜索，找到后由 updateNoGuess 再次翻开
+		if g.noGuess != nil {
+			return
+		}
+		if !g.minesPlaced && g.wantsNoGuess(x, y) {
+			g.startNoGuessSearch(x, y, 0)
+			return
+		}
+		g.firstClick = false
+		g.startTime = time.Now()
+		// 重玩种子时地雷已按原布局放置
+		if !g.minesPlaced {
+			g.placeFirstMines(x, y)
+		}
+		g.publishCell(EventGameStarted, x, y, nil)
+	}
+
+	if g.grid[y][x].hasMine && !g.spareLife(x, y) {
+		g.gameOver = true
+		g.explodedX, g.explodedY = x, y
+		g.lostTick = g.ticks
+		g.revealAllMines()
+		g.publishCell(EventCellRevealed, x, y, nil)
+		g.publish(EventGameLost)
+		return
+	}
+	g.publishCell(EventCellRevealed, x, y, g.revealCell(x, y))
+}
+
+func (g *Game) markAt(x, y int) {
+	g.toggleMark(&g.grid[y][x])
+	g.publishCell(EventFlagToggled, x, y, nil)
+}
+
+// 进行中的对局用时是否仍快于该难度的最佳记录
+func (g *Game) onRecordPace() bool {
+	if !globalConfig.ConfirmRestart || !g.inProgress() {
+		return false
+	}
+	best := globalStats.forVariant(g.difficulty, g.variant()).BestTime
+	return best > 0 && g.elapsedTime < best
+}
+
+// 以指定难度开始新的一局，地雷在首次点击时才放置
+func (g *Game) newRound(difficulty Difficulty) error {
+	return g.newRoundSized(difficulty, g.sizeFor(difficulty))
+}
+
+// 与 newRound 相同，棋盘大小由 config 指定
+func (g *Game) newRoundSized(difficulty Difficulty, config DifficultyConfig) error {
+	oldBoard := g.snapshotBoard()
+
+	g.endRace()
+	g.endCoop()
+	// 放弃进行中的对局，不再需要恢复
+	g.clearCheckpoint()
+
+	g.resetSized(difficulty, config)
+	g.scene = ScenePlaying
+	// 恢复这个难度上次使用的窗口大小，大棋盘只显示镜头内的部分
+	g.applyWindowPreset()
+	g.playSound("click")
+	g.startTransition(oldBoard)
+	return nil
+}
+
+// 右键循环切换标记：无 -> 旗帜 -> 问号（开启时）-> 无
+func (g *Game) toggleMark(cell *Cell) {
+	switch {
+	case cell.flagged:
+		cell.flagged = false
+		cell.questioned = globalConfig.QuestionMarks
+		if cell.hasMine {
+			g.flaggedMines--
+		}
+	case cell.questioned:
+		cell.questioned = false
+	default:
+		cell.flagged = true
+		if cell.hasMine {
+			g.flaggedMines++
+		}
+	}
+}
+
+// 返回本次翻开的格子
+func (g *Game) revealCell(x, y int) []cellPos {
+	config := g.size
+	var opened []cellPos
+	// 空白格子连锁翻开周围的格子
+	board.FloodFillOn(g.adjacency(), config.GridWidth, config.GridHeight, x, y, func(x, y int) bool {
+		cell := &g.grid[y][x]
+		if cell.revealed || cell.flagged {
+			return false
+		}
+		cell.revealed = true
+		opened = append(opened, cellPos{x, y})
+		if !cell.hasMine {
+			g.revealedSafe++
+		}
+		return cell.neighbors == 0
+	})
+	return opened
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	defer prof.span("render")()
+	img := g.drawCanvas()
+	screen.Fill(activeTheme.Background)
+	g.presentBoard(screen)
+	presentCanvas(screen, img)
+	saveScreenshotIfRequested(g.store, screen)
+}
+
+// 按 32 像素的格子绘制整个界面，由 Draw 缩放到窗口大小
+func (g *Game) drawScreen(screen *ebiten.Image) {
+	screen.Fill(activeTheme.Background)
+
+	if g.scene != ScenePlaying {
+		g.drawMenuScene(screen)
+		g.drawPalette(screen)
+		g.drawAbandon(screen)
+		return
+	}
+
+	if g.transition != nil {
+		g.drawTransition(screen)
+	} else {
+		g.drawBoard(screen)
+	}
+
+	// 更新按钮位置（在网格下方）
+	btnWidth := (g.viewWidth() - 50) / 4
+	// 街机模式通关后重启按钮进入下一关
+	g.restartBtn.Text = "重启"
+	if g.arcade != nil && g.won {
+		g.restartBtn.Text = "下一关"
+	}
+	g.gifBtn.Disabled = !g.exportAllowed() || gifExport != nil
+	for i, btn := range []*Button{g.restartBtn, g.difficultyBtn, g.reviewBtn, g.gifBtn} {
+		btn.X = 10 + i*(btnWidth+10)
+		btn.Y = g.viewHeight() + statusInfoTop
+		btn.W = btnWidth
+	}
+
+	g.drawStatusBar(screen)
+	// 第二行的位置在结果界面中留给按钮
+	if !g.showingReview && !g.gameOver && !g.won && !g.raceDecided() {
+		if info, clr := g.statusInfo(); info != "" {
+			text.Draw(screen, info, g.gameFont, 10, g.viewHeight()+statusInfoTop+18, clr)
+		}
+		g.drawLives(screen)
+	}
+	g.drawGhost(screen)
+
+	if time.Now().Before(g.restartConfirmUntil) {
+		g.drawCentered(screen, fmt.Sprintf(tr("破纪录中，再按 %s 重启"), bindingTitle("restart")), g.viewHeight()/2)
+	}
+
+	if g.noGuess != nil {
+		g.drawCentered(screen, tr("正在生成无猜布局..."), g.viewHeight()/2)
+	}
+
+	if g.paused {
+		g.drawPause(screen)
+	} else if g.showingReview {
+		g.drawReview(screen)
+		g.drawButton(screen, g.restartBtn)
+		g.drawButton(screen, g.difficultyBtn)
+		g.drawButton(screen, g.reviewBtn)
+		g.drawButton(screen, g.gifBtn)
+	} else if g.exploding() == nil && (g.gameOver || g.won || g.raceDecided()) {
+		// 绘制半透明遮罩，直接画矩形，避免每帧创建新的纹理
+		vector.DrawFilledRect(screen, 0, 0, float32(g.viewWidth()), float32(g.viewHeight()),
+			activeTheme.Overlay, false)
+
+		// 显示游戏结果
+		msg := tr("游戏结束")
+		if g.won {
+			msg = tr("胜利") // 简化文字
+		}
+		if g.raceDecided() {
+			msg = g.race.outcome
+		}
+		if g.timeUp {
+			msg = tr("时间到")
+		}
+
+		// 使用更大的字体绘制消息
+		bounds, _ := font.BoundString(g.gameFont, msg)
+		width := (bounds.Max.X - bounds.Min.X).Ceil()
+		height := (bounds.Max.Y - bounds.Min.Y).Ceil()
+		msgX := (g.viewWidth() - width) / 2
+		msgY := g.viewHeight()/2 - height/2
+		text.Draw(screen, msg, g.gameFont, msgX, msgY, activeTheme.Text)
+
+		lines := g.efficiencyLines()
+		if g.countdownActive() {
+			lines = append([]string{g.countdownResult()}, lines...)
+		}
+		if g.arcade != nil {
+			lines = append([]string{g.arcadeResult()}, lines...)
+		}
+		for i, line := range lines {
+			bounds, _ := font.BoundString(g.gameFont, line)
+			width := (bounds.Max.X - bounds.Min.X).Ceil()
+			text.Draw(screen, line, g.gameFont, (g.viewWidth()-width)/2, msgY+28+i*22, activeTheme.Text)
+		}
+
+		// 绘制按钮
+		g.drawButton(screen, g.restartBtn)
+		g.drawButton(screen, g.difficultyBtn)
+		g.drawButton(screen, g.reviewBtn)
+		g.drawButton(screen, g.gifBtn)
+	}
+
+	g.drawCoop(screen)
+	g.drawDebug(screen)
+	drawToast(screen, g.gameFont)
+	g.drawPalette(screen)
+
+	if g.showingDifficultyMenu {
+		// 绘制半透明背景
+		vector.DrawFilledRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+			activeTheme.MenuOverlay, false)
+
+		// 绘制难度选择按钮
+		for _, btn := range g.difficultyButtons {
+			label := *btn
+			label.Text = difficultyLabel(btn)
+			g.drawButton(screen, &label)
+			drawThumbnail(screen, btn)
+		}
+		for _, btn := range g.variantButtons {
+			g.drawButton(screen, btn.Button)
+		}
+	}
+	g.drawAbandon(screen)
+}
+
+func (g *Game) drawBoard(screen *ebiten.Image) {
+	defer prof.span("board")()
+	view := g.boardView(screen)
+	if !g.deferBoard(screen, view) {
+		img := g.boardImage(1)
+		dx, dy := g.shakeOffset()
+		for _, o := range g.boardTiles() {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(o.X-g.camX)+dx, float64(o.Y-g.camY)+dy)
+			view.DrawImage(img, op)
+		}
+	}
+
+	g.drawScannerSignal(view)
+	g.drawRadar(view)
+	g.drawProbability(view)
+	g.drawBot(view)
+	g.drawPressed(view)
+	g.drawHover(view)
+	g.drawNeighborHint(view)
+	g.drawExplosion(view)
+	g.drawMinimap(view)
+
+	// 重玩种子时标出原来的起始格子
+	if g.firstClick && g.minesPlaced && g.startX >= 0 {
+		px, py := g.cellScreenPos(g.startX, g.startY)
+		vector.StrokeRect(view, px+1, py+1, cellSize-2, cellSize-2, 2, color.RGBA{0, 200, 0, 255}, false)
+	}
+}
+
+// 绘制单个格子，offsetY 和 alpha 用于过渡动画
+func (g *Game) drawCell(screen *ebiten.Image, x, y int, offsetY float64, alpha float32) {
+	g.drawLook(screen, g.cellLook(x, y), float64(x*cellSize), float64(y*cellSize)+offsetY, alpha)
+}
+
+// 在 (px, py) 按外观状态绘制格子，不依赖格子在哪个棋盘上
+func (g *Game) drawLook(screen *ebiten.Image, look cellLook, px, py float64, alpha float32) {
+	g.drawLookSized(screen, look, px, py, cellSize, alpha)
+}
+
+// 以 size 像素边长绘制格子，用于高分辨率的棋盘缓存
+func (g *Game) drawLookSized(screen *ebiten.Image, look cellLook, px, py, size float64, alpha float32) {
+	if look.missing {
+		return
+	}
+	cell := look.cell
+	sprite := func(name string) {
+		g.drawSprite(screen, name, px, py, size, alpha)
+	}
+
+	if cell.revealed {
+		if cell.hasMine && look.exploded {
+			sprite(animatedSprite("exploded", look.frame))
+		} else if cell.hasMine {
+			sprite(animatedSprite("mine", look.frame))
+		} else {
+			sprite("revealed")
+			if cell.neighbors > 0 && !look.hideNumbers {
+				drawCellText(screen, strconv.Itoa(cell.neighbors), px, py, size)
+			}
+		}
+	} else if cell.flagged && !cell.hasMine && look.gameOver {
+		sprite("misflag")
+	} else {
+		sprite("tile")
+		if cell.flagged {
+			g.drawFlagSprite(screen, animatedSprite("flag", look.frame), px, py, size, alpha)
+		} else if cell.questioned {
+			drawCellText(screen, "?", px, py, size)
+		}
+	}
+}
+
+// 以 size 像素边长在 (x, y) 绘制贴图，选用最接近的分辨率版本
+func (g *Game) drawSprite(screen *ebiten.Image, name string, x, y, size float64, alpha float32) {
+	img := g.images[name].nearest(size)
+	scale := size / float64(img.Bounds().Dx())
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(activeTheme.TileTint)
+	op.ColorScale.ScaleAlpha(alpha)
+	if scale < 1 {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(img, op)
+}
+
+// 胜利条件：所有安全格子已翻开且所有地雷都已插旗。
+// 计数器在每次操作时更新，无需每帧扫描整个棋盘
+func (g *Game) checkWin() {
+	if g.firstClick || g.gameOver {
+		return // 首次点击前及踩雷后不检查胜利条件
+	}
+
+	g.trackProgress()
+
+	// 无旗模式无法插旗，翻开所有安全格子即获胜
+	safe := g.cellCount() - g.mineCount()
+	if board.Won(safe, g.revealedSafe, g.mineCount(), g.flaggedMines, !g.noFlags) && !g.won {
+		g.won = true
+		g.publish(EventGameWon)
+	}
+}
+
+func (g *Game) publish(t EventType) {
+	events.Publish(g.event(t))
+}
+
+// 发布与格子有关的事件，opened 为本次翻开的格子
+func (g *Game) publishCell(t EventType, x, y int, opened []cellPos) {
+	e := g.event(t)
+	e.X, e.Y, e.Opened = x, y, opened
+	events.Publish(e)
+}
+
+func (g *Game) event(t EventType) Event {
+	return Event{
+		Type:       t,
+		Difficulty: g.difficulty,
+		Elapsed:    g.elapsedTime,
+		Seed:       g.seed,
+		StartX:     g.startX,
+		StartY:     g.startY,
+		Moves:      g.moves,
+		Challenge:  g.challenge,
+		Variant:    g.variant(),
+		Hash:       g.boardHash,
+		BBBV:       g.bbbv,
+		Progress:   g.splits,
+		SafeCell:   g.safeCell,
+	}
+}
+
+func (g *Game) initializeGridSafely(firstX, firstY int) {
+	for i := 0; ; i++ {
+		g.layoutMines(firstX, firstY)
+		for tries := 0; tries < maxCascadeRerolls && g.wantsCascade(firstX, firstY); tries++ {
+			g.seed = g.rng.Int64()
+			g.layoutMines(firstX, firstY)
+		}
+
+		// 最近玩过相同的布局时换一个种子重新布雷
+		if i == maxRerolls || !globalSeeds.playedRecently(g.boardHash) {
+			return
+		}
+		slog.Debug("布局最近玩过，重新生成", "hash", g.boardHash)
+		g.seed = g.rng.Int64()
+	}
+}
+
+// 按 g.seed 放置地雷，(firstX, firstY) 周围为安全区，相同参数总是得到相同布局。
+// g.safeCell 为 true 时安全区只有 (firstX, firstY) 一个格子
+func (g *Game) layoutMines(firstX, firstY int) {
+	config := g.size
+
+	// 放置地雷，避开首次点击位置周围的安全区域
+	var mines [][]bool
+	if g.safeCell && g.shape != nil {
+		mines = board.PlaceMinesCell(g.shape, g.shape.Width, g.shape.Height, g.mineCount(), g.seed, firstX, firstY)
+	} else if g.safeCell {
+		mines = board.PlaceMinesCell(nil, config.GridWidth, config.GridHeight, config.MineCount, g.seed, firstX, firstY)
+	} else if g.shape != nil {
+		mines = board.PlaceMinesShaped(g.topology, g.shape, g.mineCount(), g.seed, firstX, firstY)
+	} else {
+		mines = board.PlaceMinesOn(g.topology, config.GridWidth, config.GridHeight, config.MineCount, g.seed, firstX, firstY)
+	}
+	g.setMines(mines, firstX, firstY)
+}
+
+// 按给定的布局放置地雷，计算数字、3BV 和布局哈希，(startX, startY) 为安全区中心
+func (g *Game) setMines(mines [][]bool, startX, startY int) {
+	counts := board.CountNeighborsOn(g.adjacency(), mines)
+	for y := range g.grid {
+		for x := range g.grid[y] {
+			g.grid[y][x].hasMine = mines[y][x]
+			g.grid[y][x].neighbors = counts[y][x]
+		}
+	}
+
+	g.bbbv, _ = g.compute3BV()
+	g.boardHash = board.Hash(mines)
+	g.minesPlaced = true
+
+	// 放置地雷前插的旗帜需要重新统计
+	g.flaggedMines = 0
+	for _, row := range g.grid {
+		for _, cell := range row {
+			if cell.flagged && cell.hasMine {
+				g.flaggedMines++
+			}
+		}
+	}
+	g.startX, g.startY = startX, startY
+}
+
+func (g *Game) revealAllMines() {
+	config := g.size
+	for y := 0; y < config.GridHeight; y++ {
+		for x := 0; x < config.GridWidth; x++ {
+			// 插对旗的地雷保持旗帜
+			if g.grid[y][x].hasMine && !g.grid[y][x].flagged {
+				g.grid[y][x].revealed = true
+			}
+		}
+	}
+}
+
+// 添加按钮绘制方法
+func (g *Game) drawButton(screen *ebiten.Image, btn *Button) {
+	// 绘制按钮背景
+	bgColor := activeTheme.ButtonBg
+	if btn.Hover && !btn.Disabled {
+		bgColor = activeTheme.ButtonHover
+	}
+
+	// 绘制按钮边框
+	borderColor := activeTheme.ButtonBorder
+
+	vector.DrawFilledRect(
+		screen,
+		float32(btn.X), float32(btn.Y),
+		float32(btn.W), float32(btn.H),
+		bgColor,
+		false, // 关闭抗锯齿
+	)
+
+	vector.StrokeRect(
+		screen,
+		float32(btn.X), float32(btn.Y),
+		float32(btn.W), float32(btn.H),
+		1, // 边框线宽
+		borderColor,
+		false, // 关闭抗锯齿
+	)
+
+	// 绘制按钮文字
+	label := tr(btn.Text)
+	bounds, _ := font.BoundString(g.gameFont, label)
+	textWidth := (bounds.Max.X - bounds.Min.X).Ceil()
+	textHeight := (bounds.Max.Y - bounds.Min.Y).Ceil()
+	textX := btn.X + (btn.W-textWidth)/2
+	textY := btn.Y + (btn.H+textHeight)/2
+	textColor := activeTheme.Text
+	if btn.Disabled {
+		textColor = dim(textColor)
+	}
+	text.Draw(screen, label, g.gameFont, textX, textY, textColor)
+}
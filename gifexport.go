@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"strconv"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"minesweeper/assets"
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+// 把刚结束的对局导出为 GIF 动画，便于分享：按操作记录从空白棋盘开始逐步重现，
+// 每步之间的间隔按设置的倍速缩短，过长的思考时间截短，最后一帧是结束时的棋盘。
+// 每一帧只包含这一步发生变化的区域，大棋盘的文件也不会太大。
+// 标准库只能编码 GIF，暂不支持 WebP。六边形等非方格的棋盘不能导出
+var gifSpeeds = []float64{1, 2, 4, 8}
+
+const (
+	gifCellSize   = 16  // 格子的像素，棋盘较大时缩小
+	gifMaxSide    = 960 // 画面长边的像素上限
+	gifFirstDelay = 50  // 空白棋盘停留的时间，单位为百分之一秒
+	gifMaxDelay   = 300 // 单步最长的停留时间
+	gifEndDelay   = 400 // 最后一帧停留的时间
+)
+
+// 数字的颜色，沿用经典扫雷的配色，缩小后的格子不画数字只填充颜色
+var gifNumberColors = []color.RGBA{
+	{}, {0, 0, 255, 255}, {0, 128, 0, 255}, {255, 0, 0, 255}, {0, 0, 128, 255},
+	{128, 0, 0, 255}, {0, 128, 128, 255}, {0, 0, 0, 255}, {128, 128, 128, 255},
+}
+
+// 重现过程中格子的显示状态
+type gifCell struct {
+	revealed bool
+	mark     int // 0 无标记，1 旗帜，2 问号
+}
+
+// 导出时复制的对局，在后台渲染期间开始新的一局也不受影响
+type gifRenderer struct {
+	size    int
+	sprites map[string]image.Image
+	cells   [][]gifCell
+
+	grid                 [][]Cell
+	moves                []move
+	width, height        int
+	adjacency            board.Topology
+	shape                *board.Mask
+	lost                 bool
+	explodedX, explodedY int
+	questionMarks        bool
+	background           color.Color
+}
+
+// 后台导出中的 GIF，完成后由 updateSavedFiles 提示
+var gifExport chan savedFile
+
+func (g *Game) exportAllowed() bool {
+	_, hex := g.topology.(board.Hex)
+	return (g.gameOver || g.won) && len(g.moves) > 0 && !hex
+}
+
+func (g *Game) exportGIF() error {
+	if gifExport != nil {
+		return nil
+	}
+	r := g.newGIFRenderer()
+	store, speed := g.store, globalConfig.GifSpeed
+	result := make(chan savedFile, 1)
+	gifExport = result
+	go func() {
+		name, err := r.write(store, speed)
+		result <- savedFile{name: name, err: err, done: "已导出 GIF", failed: "导出 GIF 失败"}
+	}()
+	return nil
+}
+
+func (r *gifRenderer) write(store storage.Storage, speed float64) (string, error) {
+	anim, err := r.render(speed)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return "", fmt.Errorf("编码 GIF 失败: %v", err)
+	}
+	name := "screenshots/minesweeper-" + time.Now().Format("20060102-150405") + ".gif"
+	if err := store.Write(name, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("保存 GIF 失败: %v", err)
+	}
+	return name, nil
+}
+
+// 复制导出需要的对局状态
+func (g *Game) newGIFRenderer() *gifRenderer {
+	size := gifCellSize
+	if s := gifMaxSide / g.gridWidth; s < size {
+		size = s
+	}
+	if s := gifMaxSide / g.gridHeight; s < size {
+		size = s
+	}
+	r := &gifRenderer{
+		size:          clamp(size, 1, gifCellSize),
+		cells:         make([][]gifCell, g.gridHeight),
+		grid:          make([][]Cell, len(g.grid)),
+		moves:         append([]move(nil), g.moves...),
+		width:         g.gridWidth,
+		height:        g.gridHeight,
+		adjacency:     g.adjacency(),
+		shape:         g.shape,
+		lost:          g.gameOver,
+		explodedX:     g.explodedX,
+		explodedY:     g.explodedY,
+		questionMarks: globalConfig.QuestionMarks,
+		background:    activeTheme.Background,
+	}
+	for y := range r.cells {
+		r.cells[y] = make([]gifCell, g.gridWidth)
+		r.grid[y] = append([]Cell(nil), g.grid[y]...)
+	}
+	return r
+}
+
+// 按操作记录生成动画，speed 为相对实际用时的倍速
+func (r *gifRenderer) render(speed float64) (*gif.GIF, error) {
+	if speed <= 0 {
+		speed = 1
+	}
+	if err := r.loadSprites(); err != nil {
+		return nil, err
+	}
+
+	anim := &gif.GIF{Config: image.Config{
+		ColorModel: color.Palette(palette.Plan9), Width: r.width * r.size, Height: r.height * r.size,
+	}}
+	whole := image.Rect(0, 0, r.width, r.height)
+	add := func(cells image.Rectangle, over bool, delay int) {
+		anim.Image = append(anim.Image, r.frame(cells, over))
+		anim.Delay = append(anim.Delay, delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+	add(whole, false, gifFirstDelay)
+
+	for i, m := range r.moves {
+		delay := gifEndDelay
+		if i+1 < len(r.moves) {
+			delay = int(float64(r.moves[i+1].at-m.at) / speed / float64(10*time.Millisecond))
+			delay = clamp(delay, 2, gifMaxDelay)
+		}
+		changed, ok := r.apply(m)
+		if !ok {
+			// 没有变化的操作只延长上一帧
+			anim.Delay[len(anim.Delay)-1] += delay
+			continue
+		}
+		add(changed, false, delay)
+	}
+
+	// 最后一帧按结束时的棋盘绘制，显示所有地雷和插错的旗
+	for y, row := range r.grid {
+		for x, cell := range row {
+			mark := 0
+			if cell.flagged {
+				mark = 1
+			} else if cell.questioned {
+				mark = 2
+			}
+			r.cells[y][x] = gifCell{revealed: cell.revealed, mark: mark}
+		}
+	}
+	add(whole, true, gifEndDelay)
+	return anim, nil
+}
+
+// 重现一步操作，返回发生变化的格子范围
+func (r *gifRenderer) apply(m move) (image.Rectangle, bool) {
+	var changed image.Rectangle
+	touch := func(x, y int) {
+		changed = changed.Union(image.Rect(x, y, x+1, y+1))
+	}
+	c := &r.cells[m.y][m.x]
+	switch {
+	case c.revealed:
+	case m.kind == moveFlag:
+		marks := 2
+		if r.questionMarks {
+			marks = 3
+		}
+		c.mark = (c.mark + 1) % marks
+		touch(m.x, m.y)
+	case c.mark != 1:
+		// 与 revealCell 相同，空白格子连锁翻开，旗帜挡住连锁
+		board.FloodFillOn(r.adjacency, r.width, r.height, m.x, m.y, func(x, y int) bool {
+			c := &r.cells[y][x]
+			if c.revealed || c.mark == 1 {
+				return false
+			}
+			c.revealed = true
+			touch(x, y)
+			cell := r.grid[y][x]
+			return !cell.hasMine && cell.neighbors == 0
+		})
+	}
+	return changed, !changed.Empty()
+}
+
+// 以 16 像素的贴图为基础，缩放到格子大小
+func (r *gifRenderer) loadSprites() error {
+	r.sprites = make(map[string]image.Image)
+	for _, name := range []string{"tile", "revealed", "mine", "flag", "misflag", "exploded"} {
+		data, err := assets.GetImage(fmt.Sprintf("%s_%d.png", name, gifCellSize))
+		if err != nil {
+			return fmt.Errorf("加载图片失败 %s: %v", name, err)
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("解码图片失败 %s: %v", name, err)
+		}
+		if r.size != gifCellSize {
+			scaled := image.NewRGBA(image.Rect(0, 0, r.size, r.size))
+			xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+			img = scaled
+		}
+		r.sprites[name] = img
+	}
+	return nil
+}
+
+// 绘制 cells 范围内的格子，over 为 true 时按对局结束后的样子显示地雷和插错的旗
+func (r *gifRenderer) frame(cells image.Rectangle, over bool) *image.Paletted {
+	rgba := image.NewRGBA(image.Rect(cells.Min.X*r.size, cells.Min.Y*r.size, cells.Max.X*r.size, cells.Max.Y*r.size))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(r.background), image.Point{}, draw.Src)
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			if r.shape == nil || r.shape.Contains(x, y) {
+				r.drawCell(rgba, x, y, over)
+			}
+		}
+	}
+	img := image.NewPaletted(rgba.Bounds(), palette.Plan9)
+	draw.Draw(img, img.Bounds(), rgba, rgba.Bounds().Min, draw.Src)
+	return img
+}
+
+func (r *gifRenderer) drawCell(dst *image.RGBA, x, y int, over bool) {
+	cell, shown := r.grid[y][x], r.cells[y][x]
+	rect := image.Rect(x*r.size, y*r.size, (x+1)*r.size, (y+1)*r.size)
+	sprite := func(name string) {
+		draw.Draw(dst, rect, r.sprites[name], image.Point{}, draw.Over)
+	}
+	switch {
+	case shown.revealed && cell.hasMine && over && r.lost && x == r.explodedX && y == r.explodedY:
+		sprite("exploded")
+	case shown.revealed && cell.hasMine:
+		sprite("mine")
+	case shown.revealed:
+		sprite("revealed")
+		if cell.neighbors > 0 {
+			r.drawNumber(dst, rect, cell.neighbors)
+		}
+	case over && shown.mark == 1 && !cell.hasMine:
+		sprite("misflag")
+	case shown.mark == 1:
+		sprite("flag")
+	default:
+		sprite("tile")
+		if shown.mark == 2 {
+			r.drawText(dst, rect, "?", color.RGBA{0, 0, 0, 255})
+		}
+	}
+}
+
+func (r *gifRenderer) drawNumber(dst *image.RGBA, rect image.Rectangle, n int) {
+	clr := gifNumberColors[n%len(gifNumberColors)]
+	if r.size < 12 {
+		// 格子太小放不下数字，在中间画一个色块
+		inset := r.size / 4
+		draw.Draw(dst, rect.Inset(inset), image.NewUniform(clr), image.Point{}, draw.Src)
+		return
+	}
+	r.drawText(dst, rect, strconv.Itoa(n), clr)
+}
+
+// 在格子中央用 7x13 的点阵字体写字
+func (r *gifRenderer) drawText(dst *image.RGBA, rect image.Rectangle, s string, clr color.Color) {
+	if r.size < 12 {
+		return
+	}
+	face := basicfont.Face7x13
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(clr), Face: face}
+	w := d.MeasureString(s).Ceil()
+	x := rect.Min.X + (r.size-w)/2
+	y := rect.Min.Y + (r.size+face.Ascent-face.Descent)/2
+	d.Dot = fixed.P(x, y)
+	d.DrawString(s)
+}
@@ -1,6 +1,6 @@
 module minesweeper
 
-go 1.20
+go 1.22
 
 require (
 	github.com/ebitengine/hideconsole v1.0.0
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"minesweeper/board"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 六边形模式：尖顶六边形组成的六边形棋盘，每个格子有 6 个相邻格子。
+// 格子使用轴向坐标 (q, r)，满足 |q|、|r|、|q+r| 都不超过半径
+const hexStatusBar = 40
+
+type hexConfig struct {
+	radius int
+	mines  int
+}
+
+// 按当前难度选择棋盘大小，地雷密度与方格棋盘相近
+var hexSettings = map[Difficulty]hexConfig{
+	Easy:   {4, 10},
+	Medium: {7, 32},
+	Hard:   {9, 58},
+}
+
+type hexPos struct{ q, r int }
+
+type hexBoard struct {
+	radius       int
+	mines        int
+	cells        [][]Cell // cells[r+radius][q+radius]，与 board.Hex 的存放方式相同
+	seed         int64
+	started      bool
+	over         bool
+	won          bool
+	startTime    time.Time
+	elapsed      time.Duration
+	revealedSafe int
+	flags        int
+	exploded     hexPos
+}
+
+func newHexBoard(c hexConfig) *hexBoard {
+	n := 2*c.radius + 1
+	cells := make([][]Cell, n)
+	for i := range cells {
+		cells[i] = make([]Cell, n)
+	}
+	return &hexBoard{radius: c.radius, mines: c.mines, cells: cells, seed: time.Now().UnixNano()}
+}
+
+// 棋盘数组的边长
+func (h *hexBoard) size() int {
+	return 2*h.radius + 1
+}
+
+func (h *hexBoard) contains(p hexPos) bool {
+	x, y := p.q+h.radius, p.r+h.radius
+	return x >= 0 && x < h.size() && y >= 0 && y < h.size() && board.HexContains(h.size(), x, y)
+}
+
+func (h *hexBoard) cell(p hexPos) *Cell {
+	return &h.cells[p.r+h.radius][p.q+h.radius]
+}
+
+// 棋盘上的所有格子，按行排列
+func (h *hexBoard) positions() []hexPos {
+	var ps []hexPos
+	for r := -h.radius; r <= h.radius; r++ {
+		for q := -h.radius; q <= h.radius; q++ {
+			if p := (hexPos{q, r}); h.contains(p) {
+				ps = append(ps, p)
+			}
+		}
+	}
+	return ps
+}
+
+func (h *hexBoard) neighbors(p hexPos) []hexPos {
+	var ns []hexPos
+	for _, n := range (board.Hex{}).Neighbors(nil, h.size(), h.size(), p.q+h.radius, p.r+h.radius) {
+		ns = append(ns, hexPos{n[0] - h.radius, n[1] - h.radius})
+	}
+	return ns
+}
+
+// 第一次点击后布雷，点击的格子及相邻格子不放地雷
+func (h *hexBoard) placeMines(safe hexPos) {
+	excluded := map[hexPos]bool{safe: true}
+	for _, n := range h.neighbors(safe) {
+		excluded[n] = true
+	}
+	var candidates []hexPos
+	for _, p := range h.positions() {
+		if !excluded[p] {
+			candidates = append(candidates, p)
+		}
+	}
+	rng := board.NewRand(h.seed)
+	rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	for _, p := range candidates[:h.mines] {
+		h.cell(p).hasMine = true
+	}
+	for _, p := range h.positions() {
+		c := h.cell(p)
+		for _, n := range h.neighbors(p) {
+			if h.cell(n).hasMine {
+				c.neighbors++
+			}
+		}
+	}
+}
+
+// 翻开 p，空白格子连锁翻开相邻格子，返回是否踩雷
+func (h *hexBoard) reveal(p hexPos) bool {
+	if !h.started {
+		h.started = true
+		h.startTime = time.Now()
+		h.placeMines(p)
+	}
+	if h.cell(p).hasMine {
+		h.over = true
+		h.exploded = p
+		h.elapsed = time.Since(h.startTime)
+		for _, q := range h.positions() {
+			if c := h.cell(q); c.hasMine && !c.flagged {
+				c.revealed = true
+			}
+		}
+		return true
+	}
+
+	board.FloodFillOn(board.Hex{}, h.size(), h.size(), p.q+h.radius, p.r+h.radius, func(x, y int) bool {
+		c := &h.cells[y][x]
+		if c.revealed || c.flagged || c.hasMine {
+			return false
+		}
+		c.revealed = true
+		h.revealedSafe++
+		return c.neighbors == 0
+	})
+
+	if h.revealedSafe == len(h.positions())-h.mines {
+		h.won = true
+		h.elapsed = time.Since(h.startTime)
+	}
+	return false
+}
+
+func (h *hexBoard) toggleFlag(p hexPos) {
+	c := h.cell(p)
+	c.flagged = !c.flagged
+	if c.flagged {
+		h.flags++
+	} else {
+		h.flags--
+	}
+}
+
+// 六边形的外接圆半径，按窗口大小让整个棋盘可见
+func (g *Game) hexSize() float64 {
+	n := float64(2*g.hex.radius + 1)
+	viewH := float64(g.screenHeight() - hexStatusBar)
+	return math.Min(float64(g.screenWidth())/(math.Sqrt(3)*n), viewH/(1.5*n+0.5))
+}
+
+// 格子中心的屏幕坐标，棋盘中心位于棋盘区域的中央
+func (g *Game) hexCenter(p hexPos) (float64, float64) {
+	s := g.hexSize()
+	cx := float64(g.screenWidth()) / 2
+	cy := float64(g.screenHeight()-hexStatusBar) / 2
+	return cx + s*math.Sqrt(3)*(float64(p.q)+float64(p.r)/2), cy + s*1.5*float64(p.r)
+}
+
+// 屏幕坐标所在的格子：先换算为小数轴向坐标，再按立方坐标取整
+func (g *Game) hexAt(px, py int) (hexPos, bool) {
+	mapper := gridMapper{originX: g.screenWidth() / 2, originY: (g.screenHeight() - hexStatusBar) / 2, hexSize: g.hexSize()}
+	q, r, _ := mapper.cell(px, py)
+	p := hexPos{q, r}
+	return p, g.hex.contains(p)
+}
+
+func (g *Game) startHex() {
+	c, ok := hexSettings[g.difficulty]
+	if !ok {
+		c = hexSettings[Hard]
+	}
+	g.hex = newHexBoard(c)
+}
+
+func (g *Game) updateHex() error {
+	h := g.hex
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.switchScene(SceneMainMenu)
+		return nil
+	}
+	if actionJustPressed("restart") {
+		g.startHex()
+		g.playSound("click")
+		return nil
+	}
+	if h.over || h.won {
+		return nil
+	}
+	if h.started {
+		h.elapsed = time.Since(h.startTime)
+	}
+
+	p, ok := g.hexAt(cursorPosition())
+	if !ok {
+		return nil
+	}
+	if actionJustPressed("reveal") && !h.cell(p).flagged && !h.cell(p).revealed {
+		switch {
+		case h.reveal(p):
+			g.playSound("explosion")
+		case h.won:
+			g.playSound("win")
+		default:
+			g.playSound("click")
+		}
+	}
+	if actionJustPressed("flag") && !h.cell(p).revealed && h.started {
+		g.playSound("flag")
+		h.toggleFlag(p)
+	}
+	return nil
+}
+
+func (g *Game) drawHex(screen *ebiten.Image) {
+	h := g.hex
+	s := g.hexSize()
+	for _, p := range h.positions() {
+		c := h.cell(p)
+		cx, cy := g.hexCenter(p)
+		x, y := cx-s, cy-s
+		sprite := func(name string) {
+			g.drawSprite(screen, name, x, y, 2*s, 1)
+		}
+		switch {
+		case c.revealed && c.hasMine && h.over && p == h.exploded:
+			sprite("hexexploded")
+		case c.revealed && c.hasMine:
+			sprite("hexmine")
+		case c.revealed:
+			sprite("hexrevealed")
+			if c.neighbors > 0 {
+				drawCellText(screen, strconv.Itoa(c.neighbors), x, y, 2*s)
+			}
+		case c.flagged && !c.hasMine && h.over:
+			sprite("hexmisflag")
+		case c.flagged:
+			g.drawFlagSprite(screen, "hexflag", x, y, 2*s, 1)
+		default:
+			sprite("hextile")
+		}
+	}
+
+	viewH := g.screenHeight() - hexStatusBar
+	if h.over || h.won {
+		vector.DrawFilledRect(screen, 0, 0, float32(g.screenWidth()), float32(viewH), activeTheme.Overlay, false)
+	}
+	status := fmt.Sprintf("%s: %d/%d  %s: %s", tr("地雷"), h.flags, h.mines, tr("时间"), formatDuration(h.elapsed))
+	text.Draw(screen, status, g.gameFont, 10, viewH+26, activeTheme.Text)
+
+	switch {
+	case h.won:
+		g.drawCentered(screen, tr("胜利")+"  "+fmt.Sprintf(tr("按 %s 重新开始"), bindingTitle("restart")), viewH/2)
+	case h.over:
+		g.drawCentered(screen, tr("游戏结束")+"  "+fmt.Sprintf(tr("按 %s 重新开始"), bindingTitle("restart")), viewH/2)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	maxBoardScale     = 4    // 棋盘缓存最多放大的倍数，与贴图的最大尺寸对应
+	maxBoardImageSize = 4096 // 棋盘缓存的最大边长，超出时不再放大
+
+	// 自定义棋盘的最大边长，不放大的棋盘缓存也不能超过 maxBoardImageSize
+	maxGridSize = maxBoardImageSize / cellSize
+)
+
+// 高分辨率屏幕上棋盘按窗口的实际像素绘制：界面其余部分画在画布上再缩放，
+// 棋盘区域在画布上留空，显示时用放大后的棋盘缓存直接填充
+var boardLayer struct {
+	target *ebiten.Image // 本帧绘制界面的画布，只有画到它上面的棋盘才改为直接绘制
+	img    *ebiten.Image // 本帧要直接绘制的棋盘缓存，nil 表示没有
+	scale  int
+	camX   int
+	camY   int
+}
+
+// 棋盘缓存相对于 32 像素格子的放大倍数，按画布缩放比例向上取整
+func (g *Game) boardScale() int {
+	k := clamp(int(math.Ceil(canvasScale-0.01)), 1, maxBoardScale)
+	for k > 1 && (g.gridWidth*cellSize*k > maxBoardImageSize || g.gridHeight*cellSize*k > maxBoardImageSize) {
+		k--
+	}
+	return k
+}
+
+// 画到界面画布上时改为在显示时直接绘制，返回 true 表示已处理，调用方不必再绘制棋盘
+func (g *Game) deferBoard(screen, view *ebiten.Image) bool {
+	k := g.boardScale()
+	if screen != boardLayer.target || k == 1 {
+		return false
+	}
+	view.Clear()
+	boardLayer.img = g.boardImage(k)
+	boardLayer.scale = k
+	boardLayer.camX, boardLayer.camY = g.camX, g.camY
+	return true
+}
+
+// 在窗口上按实际像素绘制棋盘，位置与画布上留空的棋盘区域重合
+func (g *Game) presentBoard(screen *ebiten.Image) {
+	if boardLayer.img == nil {
+		return
+	}
+	x0 := canvasX + float64(canvasInset)*canvasScale
+	y0 := canvasY + float64(canvasInset)*canvasScale
+	rect := image.Rect(int(x0), int(y0),
+		int(math.Ceil(x0+float64(g.viewWidth())*canvasScale)), int(math.Ceil(y0+float64(g.viewHeight())*canvasScale)))
+	k := float64(boardLayer.scale)
+	dx, dy := g.shakeOffset()
+	for _, o := range g.boardTiles() {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate((float64(o.X-boardLayer.camX)+dx)*k, (float64(o.Y-boardLayer.camY)+dy)*k)
+		op.GeoM.Scale(canvasScale/k, canvasScale/k)
+		op.GeoM.Translate(x0, y0)
+		if canvasScale != k {
+			op.Filter = ebiten.FilterLinear
+		}
+		screen.SubImage(rect).(*ebiten.Image).DrawImage(boardLayer.img, op)
+	}
+}
+
+// 调试字体的字符图像，放大绘制格子中的数字时使用
+var glyphs = make(map[string]*ebiten.Image)
+
+// 在格子中绘制数字或问号，size 不是 32 时按比例放大调试字体，保持像素清晰
+func drawCellText(screen *ebiten.Image, s string, px, py, size float64) {
+	if size == cellSize {
+		ebitenutil.DebugPrintAt(screen, s, int(px)+cellSize/3, int(py)+cellSize/3)
+		return
+	}
+	img, ok := glyphs[s]
+	if !ok {
+		img = ebiten.NewImage(debugCharSize*len(s), 16)
+		ebitenutil.DebugPrint(img, s)
+		glyphs[s] = img
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(size/cellSize, size/cellSize)
+	op.GeoM.Translate(math.Floor(px+size/3), math.Floor(py+size/3))
+	screen.DrawImage(img, op)
+}
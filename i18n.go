@@ -0,0 +1,346 @@
+package main
+
+// 界面文字以中文为键，其他语言按键查表，缺失时回退为中文
+var translations = map[string]map[string]string{
+	"en": {
+		"扫雷":                "Minesweeper",
+		"扫雷游戏":              "Minesweeper",
+		"新游戏":               "New Game",
+		"继续":                "Continue",
+		"统计":                "Statistics",
+		"设置":                "Settings",
+		"退出":                "Quit",
+		"返回":                "Back",
+		"重启":                "Restart",
+		"难度":                "Difficulty",
+		"简单":                "Easy",
+		"中等":                "Medium",
+		"困难":                "Hard",
+		"简单模式":              "Easy",
+		"中等模式":              "Medium",
+		"困难模式":              "Hard",
+		"时间":                "Time",
+		"游戏结束":              "Game Over",
+		"胜利":                "You Win",
+		"胜/局":               "Won/Played",
+		"最佳":                "Best",
+		"连胜":                "Streak",
+		"主题":                "Theme",
+		"默认":                "Default",
+		"春季":                "Spring",
+		"冬季":                "Winter",
+		"季节主题":              "Seasonal",
+		"音量":                "Volume",
+		"语言":                "Language",
+		"问号标记":              "Marks (?)",
+		"首次点击安全":            "Safe start",
+		"动画":                "Animations",
+		"交换左右键":             "Swap buttons",
+		"游戏已在运行":            "Minesweeper is already running",
+		"同时运行会互相覆盖存档":       "Running twice overwrites saves",
+		"切换到已运行的窗口":         "Switch to it",
+		"使用独立档案":            "Use separate profile",
+		"恢复对局":              "Resume game",
+		"放弃":                "Discard",
+		"上次的对局意外中断":         "The last game was interrupted",
+		"%s 以 %s 超过了你的%s纪录": "%s beat your %[3]s record with %[2]s",
+		"Discord 状态":        "Discord status",
+		"与主机的连接已断开":         "Disconnected from host",
+		"你先完成了！":            "You finished first!",
+		"你踩雷了，对手获胜":         "You hit a mine, opponent wins",
+		"创建房间":              "Host game",
+		"剩余地雷 %d":           "%d mines left",
+		"剪贴板中没有有效的种子":       "No valid seed in clipboard",
+		"加入房间":              "Join game",
+		"历史":                "History",
+		"在排行榜中点击 ☆ 添加对手":    "Click ☆ on the leaderboard to add rivals",
+		"在菜单中":              "In menus",
+		"复制":                "Copy",
+		"复盘":                "Review",
+		"失误":                "Mistakes",
+		"对战":                "Multiplayer",
+		"对手":                "Rivals",
+		"对手先完成 (%s)":        "Opponent finished first (%s)",
+		"对手已断开":             "Opponent disconnected",
+		"对手踩雷，你赢了":          "Opponent hit a mine, you win",
+		"对方地址":              "Host address",
+		"巨大":                "Huge",
+		"巨大模式":              "Huge",
+		"已复制":               "Copied",
+		"已复制种子":             "Seed copied",
+		"已有 %d 名玩家加入":       "%d players joined",
+		"已连接，等待主机开始":        "Connected, waiting for host",
+		"平均每步":              "Avg per move",
+		"得分":                "Score",
+		"我":                 "Me",
+		"按 %s 重新开始":         "Press %s to restart",
+		"挑战":                "Challenges",
+		"效率":                "Efficiency",
+		"新功能":               "What's new",
+		"无尽":                "Endless",
+		"无尽密度":              "Endless density",
+		"无法连接服务器，使用离线棋盘": "Server unavailable, using offline board",
+		"无猜模式":          "No-guess",
+		"简单推理":          "Basic",
+		"子集推理":          "Subset",
+		"深度推理":          "Deep",
+		"最长思考":          "Longest think",
+		"未配置排行榜服务器":     "No leaderboard server configured",
+		"模式":            "Mode",
+		"竞速":            "Race",
+		"合作":            "Co-op",
+		"拆弹":            "Defuse",
+		"每日挑战":          "Daily challenge",
+		"每周挑战":          "Weekly challenge",
+		"正在获取挑战...":     "Fetching challenge...",
+		"正在获取排行榜...":    "Fetching leaderboard...",
+		"正在连接...":       "Connecting...",
+		"步数":            "Moves",
+		"猜测":            "Guesses",
+		"玩家":            "Player",
+		"玩家 %d 已离开":     "Player %d left",
+		"知道了":           "Got it",
+		"破纪录中，再按 %s 重启": "On record pace, press %s again to restart",
+		"等待对手加入":        "Waiting for opponent",
+		"粘贴种子":          "Paste seed",
+		"胜":             "Won",
+		"负":             "Lost",
+		"胜利 %s":         "Won in %s",
+		"观战输出":          "Observer output",
+		"角色: 探测":        "Role: scanner",
+		"角色: 数字":        "Role: numbers",
+		"踩雷了":           "Hit a mine",
+		"进度":            "Progress",
+		"连接已断开":         "Connection lost",
+		"重启确认":          "Confirm restart",
+		"已保存":           "Saved",
+		"切换主题":          "Toggle theme",
+		"复制种子":          "Copy seed",
+		"主菜单":           "Main menu",
+		"调试模式":          "Debug mode",
+		"录制追踪":          "Record trace",
+		"命令面板":          "Command palette",
+		"自定义":           "Custom",
+		"全屏":            "Fullscreen",
+		"无边框窗口":         "Borderless window",
+		"图片资源损坏":        "Image assets are damaged",
+		"重新下载或安装游戏":     "Download or reinstall the game",
+		"无法加载音效":        "Could not load sounds",
+		"检查音频设备是否已连接并启用":    "Check that an audio device is connected and enabled",
+		"检查声卡驱动是否正常":        "Check that the sound driver works",
+		"无法加载字体":            "Could not load font",
+		"检查系统中文字体是否完整":      "Check that Chinese system fonts are installed",
+		"启动参数无效":            "Invalid command line",
+		"使用 -h 参数查看可用的启动参数": "Run with -h to list the options",
+		"启动失败":              "Startup failed",
+		"打开日志":              "Open log",
+		"坐标标签":              "Coordinates",
+		"六边形":               "Hexagons",
+		"地雷":                "Mines",
+		"计分板":               "Scoreboard",
+		"显示计分板":             "Show scoreboard",
+		"下一位":               "Next player",
+		"展示模式":              "Presenting",
+		"轮流上场":              "Rotate",
+		"移除选手":              "Remove player",
+		"开始":                "Start",
+		"上场":                "Up now",
+		"输入名字，可在后面加成绩":      "Type a name, optionally followed by a time",
+		"雷达辅助":              "Radar assist",
+		"雷达冷却中":             "Radar cooling down",
+		"没有可确定的安全格子":        "No certain safe cells",
+		"环面":                "Torus",
+		"布局":                "Layout",
+		"四邻格":               "Four neighbors",
+		"经典":                "Classic",
+		"马步":                "Knight",
+		"放大":                "Zoom in",
+		"缩小":                "Zoom out",
+		"原始大小":              "Actual size",
+		"不再显示":              "Don't show again",
+		"对局":                "Games",
+		"时长":                "Played",
+		"分钟":                "min",
+		"今日最佳":              "Best today",
+		"本次小结":              "Session recap",
+		"退出小结":              "Recap on quit",
+		"报告问题":              "Report a bug",
+		"生成问题报告失败":          "Failed to create bug report",
+		"已生成问题报告":           "Bug report saved",
+		"无法加载形状":            "Failed to load shape",
+		"爱心":                "Heart",
+		"菱形":                "Diamond",
+		"圆环":                "Ring",
+		"自定义形状":             "Custom shape",
+		"形状":                "Shape",
+		"谜题":                "Puzzle",
+		"无法打开谜题":            "Failed to open puzzle",
+		"谜题编辑器":             "Puzzle editor",
+		"清空":                "Clear",
+		"试解":                "Test solve",
+		"试玩":                "Play test",
+		"保存":                "Save",
+		"需要猜测":              "Guessing required",
+		"无需猜测":              "No guessing needed",
+		"谜题无效":              "Invalid puzzle",
+		"保存谜题失败":            "Failed to save puzzle",
+		"已保存谜题":             "Puzzle saved",
+		"上一组":               "Previous",
+		"下一组":               "Next",
+		"没有谜题":              "No puzzles",
+		"入门":                "Basics",
+		"进阶":                "Advanced",
+		"编辑器":               "Editor",
+		"图案":                "Pattern",
+		"图案练习":              "Pattern drills",
+		"边上的 1-1":           "1-1 at the edge",
+		"边上的 1-2":           "1-2 at the edge",
+		"练习":                "Rounds",
+		"正确率":               "Accuracy",
+		"平均":                "Average",
+		"地雷概率":              "Mine probabilities",
+		"对战和挑战中不可用":         "Not available in races and challenges",
+		"机器人":               "Bot",
+		"机器人已暂停":            "Bot paused",
+		"机器人速度":             "Bot speed",
+		"开局":                "Opening",
+		"单格计数":              "Single-cell count",
+		"观看机器人":             "Watch the bot",
+		"机器人减速":             "Bot slower",
+		"机器人加速":             "Bot faster",
+		"机器人出错":             "Bot error",
+		"轻松模式":              "Second chance",
+		"条命":                "lives",
+		"轻松":                "Casual",
+		"失去一条生命，剩余":         "Life lost, remaining",
+		"无旗模式":              "No-flag mode",
+		"无旗":                "NF",
+		"剩余":                "Left",
+		"完成":                "Completed",
+		"时间到":               "Time up",
+		"限时模式":              "Countdown mode",
+		"限时":                "Countdown",
+		"关卡":                "Level",
+		"总分":                "Total",
+		"还没有记录":             "No records yet",
+		"下一关":               "Next level",
+		"街机模式":              "Arcade",
+		"街机":                "Arcade",
+		"街机新纪录":             "New arcade record",
+		"初次胜利":              "First win",
+		"赢下第一局":             "Win your first game",
+		"游刃有余":              "Smooth operator",
+		"60 秒内赢下中等难度":       "Win Medium in under 60s",
+		"百秒困难":              "Hard in 100",
+		"100 秒内赢下困难难度":      "Win Hard in under 100s",
+		"巨大胜利":              "Huge win",
+		"赢下巨大难度":            "Win a Huge game",
+		"十连胜":               "Ten in a row",
+		"同一难度连续赢下 10 局":     "Win 10 games in a row on one difficulty",
+		"片旗不留":              "Flagless",
+		"在无旗模式中赢下中等或更难的一局": "Win Medium or harder in no-flags mode",
+		"每日一练":          "Daily player",
+		"完成一次每日挑战":      "Complete a daily challenge",
+		"马步通关":          "Knight rider",
+		"赢下一局马步玩法":      "Win a knight-move game",
+		"解谜者":           "Puzzler",
+		"完成一个谜题":        "Solve a puzzle",
+		"百战老兵":          "Veteran",
+		"累计玩满 100 局":    "Play 100 games",
+		"成就":            "Achievements",
+		"成就解锁":          "Achievement unlocked",
+		"新成就":           "New achievements",
+		"深夜":            "Midnight",
+		"鎏金":            "Gilded",
+		"红旗":            "Red flag",
+		"绿旗":            "Green flag",
+		"蓝旗":            "Blue flag",
+		"金旗":            "Gold flag",
+		"复古":            "Retro",
+		"柔和":            "Soft",
+		"旗帜":            "Flag",
+		"音效":            "Sounds",
+		"外观":            "Appearance",
+		"解锁外观":          "Unlocked",
+		"解锁条件":          "Unlock by",
+		"PB 幽灵":         "PB ghost",
+		"重试此局":          "Retry this board",
+		"重试":            "Retry",
+		"安全格子":          "Safe cell",
+		"安全开局":          "Safe opening",
+		"大开局":           "Big opening",
+		"快速翻开":          "Chord",
+		"已暂停":           "Paused",
+		"按 %s 继续":       "Press %s to resume",
+		"操作":            "Controls",
+		"恢复默认":          "Restore defaults",
+		"按下新的按键，Esc 取消": "Press a new key or button, Esc to cancel",
+		"左键":            "Left button",
+		"右键":            "Right button",
+		"中键":            "Middle button",
+		"侧键 1":          "Side button 1",
+		"侧键 2":          "Side button 2",
+		"暂停":            "Pause",
+		"插旗":            "Flag",
+		"翻开":            "Reveal",
+		"放弃当前游戏？":       "Abandon current game?",
+		"是":             "Yes",
+		"否":             "No",
+		"放弃局数":          "Abandoned",
+		"截图":            "Screenshot",
+		"保存截图失败":        "Failed to save screenshot",
+		"已保存截图":         "Screenshot saved",
+		"GIF 速度":        "GIF speed",
+		"导出 GIF":        "Export GIF",
+		"导出 GIF 失败":     "Failed to export GIF",
+		"已导出 GIF":       "GIF exported",
+		"复制结果":          "Copy result",
+		"复制失败":          "Copy failed",
+		"已复制结果":         "Result copied",
+		"棋盘文件":          "Board file",
+		"导出棋盘":          "Export board",
+		"导出棋盘失败":        "Failed to export board",
+		"已导出棋盘":         "Board exported",
+		"无法打开棋盘":        "Cannot open board",
+		"MBF 只支持经典棋盘":   "MBF only supports classic boards",
+		"导出 MBF":        "Export MBF",
+		"导出 MBF 失败":     "Failed to export MBF",
+		"已导出 MBF":       "MBF exported",
+		"正在生成无猜布局...":   "Generating no-guess board...",
+		"雷达":            "Radar",
+		"街机模式中不可用":      "Not available in arcade mode",
+		"逐步翻开":          "Progressive reveal",
+		"这个种子来自旧版本，棋盘与原来不同": "This seed is from an older version; the board is different",
+		"辅助": "Assisted",
+		"开":  "On",
+		"关":  "Off",
+	},
+}
+
+// 可选语言及其显示名称
+var languages = []struct {
+	Code string
+	Name string
+}{
+	{"zh", "中文"},
+	{"en", "English"},
+}
+
+// 本次运行使用的语言，只由 --lang 设置，不写入配置
+var langOverride string
+
+// 当前界面语言，启动参数优先于配置
+func currentLanguage() string {
+	if langOverride != "" {
+		return langOverride
+	}
+	return globalConfig.Language
+}
+
+// 返回当前语言下的文字
+func tr(key string) string {
+	if text, ok := translations[currentLanguage()][key]; ok {
+		return text
+	}
+	return key
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/board"
+)
+
+// 可以重新绑定的操作。格子操作作用于鼠标所在的格子，雷达需要按住，见 radar.go，其余操作是同名的命令，见 commands.go。
+// 绑定保存在配置中，格式为 mouse:编号 或 key:按键名，未修改的操作使用默认绑定
+type bindableAction struct {
+	id    string
+	title string
+	def   inputBinding
+}
+
+var bindableActions = []bindableAction{
+	{"reveal", "翻开", inputBinding{mouse: true, button: ebiten.MouseButtonLeft}},
+	{"flag", "插旗", inputBinding{mouse: true, button: ebiten.MouseButtonRight}},
+	{"chord", "快速翻开", inputBinding{mouse: true, button: ebiten.MouseButtonMiddle}},
+	{"restart", "重启", inputBinding{key: ebiten.KeyR}},
+	{"pause", "暂停", inputBinding{key: ebiten.KeyP}},
+	{"radar", "雷达", inputBinding{key: ebiten.KeySpace}},
+}
+
+// 一个鼠标按键或键盘按键
+type inputBinding struct {
+	mouse  bool
+	button ebiten.MouseButton
+	key    ebiten.Key
+}
+
+var mouseButtonTitles = map[ebiten.MouseButton]string{
+	ebiten.MouseButtonLeft:   "左键",
+	ebiten.MouseButtonMiddle: "中键",
+	ebiten.MouseButtonRight:  "右键",
+	ebiten.MouseButton3:      "侧键 1",
+	ebiten.MouseButton4:      "侧键 2",
+}
+
+func (b inputBinding) String() string {
+	if b.mouse {
+		return fmt.Sprintf("mouse:%d", b.button)
+	}
+	return "key:" + b.key.String()
+}
+
+func parseBinding(s string) (inputBinding, bool) {
+	kind, name, ok := strings.Cut(s, ":")
+	if !ok {
+		return inputBinding{}, false
+	}
+	switch kind {
+	case "mouse":
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 0 || n > int(ebiten.MouseButtonMax) {
+			return inputBinding{}, false
+		}
+		return inputBinding{mouse: true, button: ebiten.MouseButton(n)}, true
+	case "key":
+		var k ebiten.Key
+		if err := k.UnmarshalText([]byte(name)); err != nil {
+			return inputBinding{}, false
+		}
+		return inputBinding{key: k}, true
+	}
+	return inputBinding{}, false
+}
+
+// 显示名称，左手模式中左右键按交换后的实际按键显示
+func (b inputBinding) title() string {
+	if b.mouse {
+		return tr(mouseButtonTitles[b.swapped().button])
+	}
+	return b.key.String()
+}
+
+// 左手模式交换鼠标左右键
+func (b inputBinding) swapped() inputBinding {
+	if b.mouse && globalConfig.SwapButtons {
+		switch b.button {
+		case ebiten.MouseButtonLeft:
+			b.button = ebiten.MouseButtonRight
+		case ebiten.MouseButtonRight:
+			b.button = ebiten.MouseButtonLeft
+		}
+	}
+	return b
+}
+
+func (b inputBinding) pressed() bool {
+	b = b.swapped()
+	if b.mouse {
+		return ebiten.IsMouseButtonPressed(b.button)
+	}
+	return ebiten.IsKeyPressed(b.key)
+}
+
+func (b inputBinding) justPressed() bool {
+	b = b.swapped()
+	if b.mouse {
+		return inpututil.IsMouseButtonJustPressed(b.button)
+	}
+	return inpututil.IsKeyJustPressed(b.key)
+}
+
+func (b inputBinding) justReleased() bool {
+	b = b.swapped()
+	if b.mouse {
+		return inpututil.IsMouseButtonJustReleased(b.button)
+	}
+	return inpututil.IsKeyJustReleased(b.key)
+}
+
+func findBindable(id string) (bindableAction, bool) {
+	for _, a := range bindableActions {
+		if a.id == id {
+			return a, true
+		}
+	}
+	return bindableAction{}, false
+}
+
+// 操作当前的绑定，配置中的绑定无效时使用默认绑定
+func actionBinding(id string) (inputBinding, bool) {
+	a, ok := findBindable(id)
+	if !ok {
+		return inputBinding{}, false
+	}
+	if b, ok := parseBinding(globalConfig.Bindings[id]); ok {
+		return b, true
+	}
+	return a.def, true
+}
+
+// 操作绑定的按键名称，用于界面上的提示
+func bindingTitle(id string) string {
+	b, _ := actionBinding(id)
+	return b.title()
+}
+
+func actionJustPressed(id string) bool {
+	b, ok := actionBinding(id)
+	return ok && b.justPressed()
+}
+
+// 把操作绑定到 b，b 原来属于其他操作时两者互换
+func rebind(id string, b inputBinding) {
+	old, _ := actionBinding(id)
+	if globalConfig.Bindings == nil {
+		globalConfig.Bindings = make(map[string]string)
+	}
+	for _, a := range bindableActions {
+		if other, _ := actionBinding(a.id); a.id != id && other == b {
+			globalConfig.Bindings[a.id] = old.String()
+		}
+	}
+	globalConfig.Bindings[id] = b.String()
+}
+
+// 格子操作，按顺序检查，同一帧可以触发多个
+var cellActions = []struct {
+	id        string
+	onRelease bool // 按下时格子只显示为按下状态，在同一个格子上松开才执行，移开后松开即取消
+	run       func(g *Game, x, y int)
+}{
+	{"reveal", true, func(g *Game, x, y int) {
+		if g.canAct(moveReveal, x, y) {
+			g.act(moveReveal, x, y)
+		}
+	}},
+	{"flag", false, func(g *Game, x, y int) {
+		if g.canAct(moveFlag, x, y) {
+			g.act(moveFlag, x, y)
+		}
+	}},
+	{"chord", true, (*Game).chord},
+}
+
+// 按住未松开的格子操作
+type cellPress struct {
+	action string
+	x, y   int
+}
+
+// 对局中的格子输入，(x, y) 为鼠标位置
+func (g *Game) updateCellInput(x, y int) {
+	if g.updateChordGesture(x, y) {
+		return
+	}
+	gridX, gridY, ok := g.cellAt(x, y)
+	for _, a := range cellActions {
+		b, _ := actionBinding(a.id)
+		switch {
+		case a.onRelease && b.justPressed():
+			g.press = nil
+			if ok {
+				g.press = &cellPress{a.id, gridX, gridY}
+			}
+		case a.onRelease && b.justReleased():
+			if p := g.press; p != nil && p.action == a.id {
+				g.press = nil
+				if ok && p.x == gridX && p.y == gridY {
+					a.run(g, gridX, gridY)
+				}
+			}
+		case !a.onRelease && b.justPressed() && ok:
+			a.run(g, gridX, gridY)
+		}
+	}
+}
+
+// 经典的左右键同时按下手势：两个键都按住时按下周围的格子，松开任意一个时快速翻开，
+// 两个键都松开之前不再响应单键操作。返回 true 表示本帧的格子输入已处理
+func (g *Game) updateChordGesture(x, y int) bool {
+	reveal, _ := actionBinding("reveal")
+	flag, _ := actionBinding("flag")
+	if reveal.pressed() && flag.pressed() {
+		g.chordHeld, g.press = true, nil
+		return true
+	}
+	if !g.chordHeld {
+		return false
+	}
+	if !g.chordFired {
+		g.chordFired = true
+		if gridX, gridY, ok := g.cellAt(x, y); ok {
+			g.chord(gridX, gridY)
+		}
+	}
+	if !reveal.pressed() && !flag.pressed() {
+		g.chordHeld, g.chordFired = false, false
+	}
+	return true
+}
+
+// 处于按下状态的格子：按住翻开键时为鼠标所在的格子，按住快速翻开键或同时按住两个键时
+// 还包括周围的格子，只算未翻开、未插旗的。鼠标移出按下时的格子后不显示
+func (g *Game) pressedCells() []cellPos {
+	if g.gameOver || g.won {
+		return nil
+	}
+	x, y, ok := g.cellAt(cursorPosition())
+	if !ok {
+		return nil
+	}
+	var candidates [][2]int
+	p := g.press
+	switch {
+	case g.chordHeld && !g.chordFired, p != nil && p.action == "chord" && p.x == x && p.y == y:
+		candidates = append(g.adjacency().Neighbors(nil, g.gridWidth, g.gridHeight, x, y), [2]int{x, y})
+	case p != nil && p.x == x && p.y == y:
+		candidates = [][2]int{{x, y}}
+	}
+	var cells []cellPos
+	for _, p := range candidates {
+		if c := g.grid[p[1]][p[0]]; !c.revealed && !c.flagged {
+			cells = append(cells, cellPos{p[0], p[1]})
+		}
+	}
+	return cells
+}
+
+// 按下的格子画成翻开后的空白底色
+func (g *Game) drawPressed(view *ebiten.Image) {
+	for _, p := range g.pressedCells() {
+		px, py := g.cellScreenPos(p.x, p.y)
+		g.drawSprite(view, "revealed", float64(px), float64(py), cellSize, 1)
+	}
+}
+
+// 鼠标所在的未翻开格子，或鼠标停在数字上时快速翻开会影响的格子，淡淡地提亮以示点击的目标。
+// 有格子处于按下状态时由按下效果代替
+func (g *Game) hoverCells() []cellPos {
+	if g.gameOver || g.won || g.paused || len(g.pressedCells()) > 0 {
+		return nil
+	}
+	x, y, ok := g.cellAt(cursorPosition())
+	if !ok || !g.exists(x, y) {
+		return nil
+	}
+	cell := g.grid[y][x]
+	if !cell.revealed {
+		return []cellPos{{x, y}}
+	}
+	if cell.neighbors == 0 {
+		return nil
+	}
+	var cells []cellPos
+	for _, p := range g.adjacency().Neighbors(nil, g.gridWidth, g.gridHeight, x, y) {
+		if c := g.grid[p[1]][p[0]]; !c.revealed && !c.flagged && g.exists(p[0], p[1]) {
+			cells = append(cells, cellPos{p[0], p[1]})
+		}
+	}
+	return cells
+}
+
+func (g *Game) drawHover(view *ebiten.Image) {
+	clr := color.RGBA{40, 40, 40, 40} // 预乘透明度的白色
+	for _, p := range g.hoverCells() {
+		px, py := g.cellScreenPos(p.x, p.y)
+		vector.DrawFilledRect(view, px, py, cellSize, cellSize, clr, false)
+	}
+}
+
+// 快速翻开：数字周围插的旗数等于数字时，翻开周围其余没有插旗的格子
+func (g *Game) chord(x, y int) {
+	cell := g.grid[y][x]
+	if !cell.revealed || cell.hasMine || cell.neighbors == 0 {
+		return
+	}
+	targets := board.ChordTargets(g.adjacency(), g.gridWidth, g.gridHeight, x, y, cell.neighbors,
+		func(x, y int) bool { return g.grid[y][x].revealed },
+		func(x, y int) bool { return g.grid[y][x].flagged })
+	for _, p := range targets {
+		// 之前的格子可能已经连锁翻开或踩雷结束了对局
+		if c := g.grid[p[1]][p[0]]; !c.revealed && !g.gameOver {
+			g.act(moveReveal, p[0], p[1])
+		}
+	}
+}
+
+// 暂停时隐藏棋盘并停止计时。联机对局的对手不会暂停，不可用
+func (g *Game) pauseAllowed() bool {
+	return (g.paused || g.inProgress()) && g.race == nil && g.coop == nil
+}
+
+func (g *Game) togglePause() error {
+	if g.paused {
+		g.paused = false
+		g.resumeTimer(time.Since(g.pausedAt))
+		return nil
+	}
+	g.paused, g.pausedAt = true, time.Now()
+	return nil
+}
+
+func (g *Game) drawPause(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, float32(g.viewWidth()), float32(g.viewHeight()), activeTheme.Background, false)
+	g.drawCentered(screen, tr("已暂停"), g.viewHeight()/2-10)
+	g.drawCentered(screen, fmt.Sprintf(tr("按 %s 继续"), bindingTitle("pause")), g.viewHeight()/2+16)
+}
+
+// 操作设置界面：点击一项后按下新的按键或鼠标按键完成绑定，Esc 取消
+var rebinding string
+
+func (g *Game) controlsButtons() []*menuButton {
+	var buttons []*menuButton
+	for _, a := range bindableActions {
+		a := a
+		b, _ := actionBinding(a.id)
+		label := tr(a.title) + ": " + b.title()
+		if rebinding == a.id {
+			label = tr(a.title) + ": ..."
+		}
+		buttons = append(buttons, &menuButton{Button: &Button{Text: label}, action: func() error {
+			rebinding = a.id
+			g.switchScene(SceneControls)
+			return nil
+		}})
+	}
+	onOff := tr("关")
+	if globalConfig.SwapButtons {
+		onOff = tr("开")
+	}
+	back := g.backButton()
+	back.action = func() error {
+		g.switchScene(SceneSettings)
+		return nil
+	}
+	return append(buttons,
+		&menuButton{Button: &Button{Text: tr("交换左右键") + ": " + onOff}, action: func() error {
+			globalConfig.SwapButtons = !globalConfig.SwapButtons
+			g.applySettings()
+			return nil
+		}},
+		&menuButton{Button: &Button{Text: "恢复默认"}, action: func() error {
+			globalConfig.Bindings = nil
+			g.applySettings()
+			return nil
+		}},
+		back,
+	)
+}
+
+// 等待新绑定时接管全部输入，返回 true 表示本帧的输入已处理
+func (g *Game) updateRebind() bool {
+	if rebinding == "" {
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		rebinding = ""
+		g.switchScene(SceneControls)
+		return true
+	}
+	var pressed *inputBinding
+	if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+		pressed = &inputBinding{key: keys[0]}
+	}
+	for btn := ebiten.MouseButton0; btn <= ebiten.MouseButtonMax; btn++ {
+		if inpututil.IsMouseButtonJustPressed(btn) {
+			// 记录的是交换前的按键，左手模式中显示和生效的都是实际按下的键
+			pressed = &inputBinding{mouse: true, button: btn}
+			*pressed = pressed.swapped()
+		}
+	}
+	if pressed != nil {
+		rebind(rebinding, *pressed)
+		rebinding = ""
+		g.applySettings()
+	}
+	return true
+}
+
+func (g *Game) drawControls(screen *ebiten.Image) {
+	if rebinding != "" {
+		g.drawCentered(screen, tr("按下新的按键，Esc 取消"), g.screenHeight()-12)
+	}
+}
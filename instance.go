@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"minesweeper/storage"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const instanceHello = "minesweeper"
+
+var errAlreadyRunning = errors.New("已有实例在运行")
+
+// 单实例锁：运行中的实例在本地端口监听，并把端口号写入档案目录下的锁文件，
+// 后启动的实例据此判断同一档案是否已被占用
+type instanceLock struct {
+	listener       net.Listener
+	focusRequested atomic.Bool
+	raiseFrames    int
+}
+
+// 当前进程持有的实例锁，未能获取时为 nil
+var globalInstance *instanceLock
+
+func lockFilePath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "instance.lock"), nil
+}
+
+// 连接锁文件中记录的实例，确认对方确实是本游戏
+func dialInstance() (net.Conn, error) {
+	path, err := lockFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:"+strings.TrimSpace(string(data)), 500*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(time.Second))
+	hello, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || strings.TrimSpace(hello) != instanceHello {
+		conn.Close()
+		return nil, fmt.Errorf("锁文件指向的端口不属于本游戏")
+	}
+	return conn, nil
+}
+
+func acquireInstance() (*instanceLock, error) {
+	if conn, err := dialInstance(); err == nil {
+		conn.Close()
+		return nil, errAlreadyRunning
+	}
+
+	path, err := lockFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("获取数据目录失败: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("创建实例锁失败: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	if err := storage.WriteFileAtomic(path, []byte(strconv.Itoa(port))); err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	l := &instanceLock{listener: ln}
+	go l.serve()
+	return l, nil
+}
+
+func (l *instanceLock) serve() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(time.Second))
+			fmt.Fprintln(conn, instanceHello)
+			cmd, _ := bufio.NewReader(conn).ReadString('\n')
+			if strings.TrimSpace(cmd) == "focus" {
+				l.focusRequested.Store(true)
+			}
+		}()
+	}
+}
+
+// 在游戏循环中调用，响应其他实例发来的切换窗口请求
+func (l *instanceLock) update() {
+	if l.focusRequested.Swap(false) {
+		if ebiten.IsWindowMinimized() {
+			ebiten.RestoreWindow()
+		}
+		// 临时置顶以把窗口带到前台
+		ebiten.SetWindowFloating(true)
+		l.raiseFrames = 2
+	} else if l.raiseFrames > 0 {
+		l.raiseFrames--
+		if l.raiseFrames == 0 {
+			ebiten.SetWindowFloating(false)
+		}
+	}
+}
+
+// 请求已运行的实例切换到前台
+func requestFocus() error {
+	conn, err := dialInstance()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = fmt.Fprintln(conn, "focus")
+	return err
+}
+
+// 寻找一个未被占用的独立档案并获取其实例锁
+func acquireSeparateProfile() (*instanceLock, error) {
+	for n := 2; n < 100; n++ {
+		profile = strconv.Itoa(n)
+		l, err := acquireInstance()
+		if !errors.Is(err, errAlreadyRunning) {
+			return l, err
+		}
+	}
+	return nil, errAlreadyRunning
+}
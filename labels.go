@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	coordGutter   = 24 // 标签区域的宽度，足够显示三位行号
+	debugCharSize = 6  // ebitenutil 调试字体的字符宽度
+)
+
+// 开启坐标标签时在棋盘左侧和上方留出的宽度。无尽模式没有固定的坐标原点，不显示
+func (g *Game) labelGutter() int {
+	if !globalConfig.CoordLabels || g.scene == SceneEndless {
+		return 0
+	}
+	return coordGutter
+}
+
+// 列名按表格的方式编号：A-Z，之后是 AA、AB……
+func columnName(x int) string {
+	name := ""
+	for x++; x > 0; x = (x - 1) / 26 {
+		name = string(rune('A'+(x-1)%26)) + name
+	}
+	return name
+}
+
+// 格子的坐标名称，如 C7，行号从 1 开始
+func coordName(x, y int) string {
+	return columnName(x) + strconv.Itoa(y+1)
+}
+
+// 在画布的标签区域绘制镜头可见范围内的列名和行号
+func (g *Game) drawCoordLabels(screen *ebiten.Image) {
+	view := g.cameraCells()
+	for x := view.Min.X; x < view.Max.X && x < g.gridWidth; x++ {
+		name := columnName(x)
+		px, _ := g.cellScreenPos(x, 0)
+		cx := canvasInset + int(px) + (cellSize-len(name)*debugCharSize)/2
+		if cx >= canvasInset && cx+len(name)*debugCharSize <= canvasInset+g.viewWidth() {
+			ebitenutil.DebugPrintAt(screen, name, cx, (canvasInset-16)/2)
+		}
+	}
+	for y := view.Min.Y; y < view.Max.Y && y < g.gridHeight; y++ {
+		name := strconv.Itoa(y + 1)
+		_, py := g.cellScreenPos(0, y)
+		cy := canvasInset + int(py) + (cellSize-16)/2
+		if cy >= canvasInset && cy+16 <= canvasInset+g.viewHeight() {
+			ebitenutil.DebugPrintAt(screen, name, canvasInset-2-len(name)*debugCharSize, cy)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 界面按 32 像素的格子绘制到画布上，再按窗口实际大小等比缩放后居中显示。
+// 画布和缩放比例与对局无关，换局时保留
+var (
+	canvas      *ebiten.Image
+	innerCanvas *ebiten.Image // 显示坐标标签时界面先画在这里，再放到画布的标签内侧
+	canvasScale = 1.0
+	canvasX     float64 // 画布左上角在窗口中的位置
+	canvasY     float64
+	canvasInset int // 画布左侧和上方留给坐标标签的宽度
+)
+
+// 按窗口大小计算缩放比例和居中位置，屏幕使用窗口的实际像素尺寸，
+// 系统缩放为 150%、200% 时不会再被 ebiten 放大而变模糊
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	dsf := ebiten.DeviceScaleFactor()
+	outsideWidth = int(math.Ceil(float64(outsideWidth) * dsf))
+	outsideHeight = int(math.Ceil(float64(outsideHeight) * dsf))
+	canvasInset = g.labelGutter()
+	w, h := float64(g.canvasWidth()), float64(g.canvasHeight())
+	canvasScale = math.Min(float64(outsideWidth)/w, float64(outsideHeight)/h)
+	if canvasScale <= 0 {
+		canvasScale = 1
+	}
+	canvasX = math.Floor((float64(outsideWidth) - w*canvasScale) / 2)
+	canvasY = math.Floor((float64(outsideHeight) - h*canvasScale) / 2)
+	return outsideWidth, outsideHeight
+}
+
+// 画布包括界面、坐标标签和计分板
+func (g *Game) canvasWidth() int {
+	return g.screenWidth() + g.labelGutter() + g.scorePanel()
+}
+
+func (g *Game) canvasHeight() int {
+	return g.screenHeight() + g.labelGutter()
+}
+
+// 鼠标在界面上的坐标，所有点击判断都应使用它而不是 ebiten.CursorPosition
+func cursorPosition() (int, int) {
+	x, y := ebiten.CursorPosition()
+	return int(math.Floor((float64(x)-canvasX)/canvasScale)) - canvasInset,
+		int(math.Floor((float64(y)-canvasY)/canvasScale)) - canvasInset
+}
+
+// 返回 w×h 的图片，img 尺寸不符时重新创建
+func resizeImage(img *ebiten.Image, w, h int) *ebiten.Image {
+	if img != nil && img.Bounds().Dx() == w && img.Bounds().Dy() == h {
+		return img
+	}
+	if img != nil {
+		img.Dispose()
+	}
+	return ebiten.NewImage(w, h)
+}
+
+// 绘制整个界面和坐标标签到画布上
+func (g *Game) drawCanvas() *ebiten.Image {
+	canvas = resizeImage(canvas, g.canvasWidth(), g.canvasHeight())
+	boardLayer.img = nil
+	if canvasInset == 0 && g.scorePanel() == 0 {
+		boardLayer.target = canvas
+		g.drawScreen(canvas)
+		return canvas
+	}
+
+	innerCanvas = resizeImage(innerCanvas, g.screenWidth(), g.screenHeight())
+	boardLayer.target = innerCanvas
+	g.drawScreen(innerCanvas)
+	canvas.Fill(activeTheme.Background)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(canvasInset), float64(canvasInset))
+	canvas.DrawImage(innerCanvas, op)
+	if g.scene == ScenePlaying && canvasInset > 0 {
+		g.drawCoordLabels(canvas)
+	}
+	if g.scorePanel() > 0 {
+		g.drawScorePanel(canvas, canvasInset+g.screenWidth())
+	}
+	return canvas
+}
+
+// 把画布缩放到窗口上
+func presentCanvas(screen, img *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(canvasScale, canvasScale)
+	op.GeoM.Translate(canvasX, canvasY)
+	// 整数倍放大时保持像素清晰
+	if canvasScale != math.Trunc(canvasScale) {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(img, op)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/board"
+)
+
+// 轻松模式：踩到地雷时消耗一条生命，这颗地雷移到随机的安全格子并重新计算数字，
+// 对局继续。用过生命的对局单独记录，见 variant。对战、挑战、合作、谜题和街机中不可用
+var livesOptions = []int{0, 1, 2, 3}
+
+// 踩中后被移走的地雷，自动存档恢复时按顺序重新移动
+type relocation struct {
+	FromX int `json:"from_x"`
+	FromY int `json:"from_y"`
+	ToX   int `json:"to_x"`
+	ToY   int `json:"to_y"`
+}
+
+func livesTitle(n int) string {
+	if n == 0 {
+		return tr("关")
+	}
+	return fmt.Sprintf("%d %s", n, tr("条命"))
+}
+
+func (g *Game) livesAllowed() bool {
+	return g.race == nil && g.challenge == nil && g.coop == nil && g.puzzle == nil && g.arcade == nil
+}
+
+// 剩余的生命，对局开始时按设置确定，中途修改设置不影响当前对局
+func (g *Game) livesLeft() int {
+	if !g.livesAllowed() {
+		return 0
+	}
+	return g.lives - len(g.relocations)
+}
+
+// 踩到 (x, y) 的地雷时尝试消耗一条生命，成功时地雷已经移走，格子可以正常翻开
+func (g *Game) spareLife(x, y int) bool {
+	if g.livesLeft() <= 0 {
+		return false
+	}
+	// 优先选择周围没有翻开格子的位置，不改变玩家已经看到的数字，没有这样的位置时才放宽
+	var candidates, hidden []relocation
+	for ty, row := range g.grid {
+		for tx, cell := range row {
+			if cell.revealed || cell.hasMine || (tx == x && ty == y) || !g.exists(tx, ty) {
+				continue
+			}
+			r := relocation{FromX: x, FromY: y, ToX: tx, ToY: ty}
+			candidates = append(candidates, r)
+			if !g.nextToRevealed(tx, ty) {
+				hidden = append(hidden, r)
+			}
+		}
+	}
+	if len(hidden) > 0 {
+		candidates = hidden
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	// 按种子和已用的生命数选择，同一局的结果可以重现
+	rng := board.NewRand(g.seed + int64(len(g.relocations)) + 1)
+	r := candidates[rng.IntN(len(candidates))]
+	g.moveMine(r)
+	g.relocations = append(g.relocations, r)
+
+	g.playSound("explosion")
+	showToast(fmt.Sprintf("%s %d", tr("失去一条生命，剩余"), g.livesLeft()))
+	return true
+}
+
+// (x, y) 周围是否有已翻开的格子
+func (g *Game) nextToRevealed(x, y int) bool {
+	for _, p := range g.adjacency().Neighbors(nil, g.gridWidth, g.gridHeight, x, y) {
+		if g.grid[p[1]][p[0]].revealed {
+			return true
+		}
+	}
+	return false
+}
+
+// 移动一颗地雷并重新计算数字、3BV 和布局哈希
+func (g *Game) moveMine(r relocation) {
+	g.grid[r.FromY][r.FromX].hasMine = false
+	to := &g.grid[r.ToY][r.ToX]
+	to.hasMine = true
+	if to.flagged {
+		g.flaggedMines++
+	}
+
+	mines := make([][]bool, g.gridHeight)
+	for y, row := range g.grid {
+		mines[y] = make([]bool, g.gridWidth)
+		for x, cell := range row {
+			mines[y][x] = cell.hasMine
+		}
+	}
+	counts := board.CountNeighborsOn(g.adjacency(), mines)
+	for y := range g.grid {
+		for x := range g.grid[y] {
+			g.grid[y][x].neighbors = counts[y][x]
+		}
+	}
+	g.bbbv, _ = g.compute3BV()
+	g.boardHash = board.Hash(mines)
+}
+
+// 在状态栏右侧画出生命，用掉的显示为灰色
+func (g *Game) drawLives(screen *ebiten.Image) {
+	if !g.livesAllowed() || g.lives == 0 {
+		return
+	}
+	const size = 12
+	left := g.livesLeft()
+	for i := 0; i < g.lives; i++ {
+		clr := color.RGBA{220, 50, 70, 255}
+		if i >= left {
+			clr = color.RGBA{120, 120, 120, 255}
+		}
+		x := float32(g.viewWidth() - 10 - (g.lives-i)*(size+4))
+		drawHeart(screen, x, float32(g.viewHeight()+statusInfoTop+4), size, clr)
+	}
+}
+
+// 两个圆加上逐行收窄的下半部分拼成心形，(x, y) 为左上角
+func drawHeart(screen *ebiten.Image, x, y, size float32, clr color.Color) {
+	r := size / 4
+	vector.DrawFilledCircle(screen, x+r, y+r, r, clr, true)
+	vector.DrawFilledCircle(screen, x+3*r, y+r, r, clr, true)
+	for dy := float32(0); dy < size-r; dy++ {
+		half := size / 2 * (1 - dy/(size-r))
+		vector.StrokeLine(screen, x+size/2-half, y+r+dy, x+size/2+half, y+r+dy, 1, clr, true)
+	}
+}
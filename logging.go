@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 日志分为 debug、info、warn、error 四级，默认记录 info 及以上，启动时可用 --log-level 调整。
+// 同时写入标准错误和日志文件，Windows 下没有控制台，只能通过文件查看。
+// 日志文件超过 logMaxSize 时轮换，保留 logBackups 个旧文件，获取实例锁后每次启动也轮换一次，
+// 上次运行的日志在 minesweeper.1.log 中。其他实例正在写入时只追加，不轮换，
+// 以免移走对方正在写的文件。标准库 log 的输出按 info 级别记录
+const (
+	logName    = "minesweeper.log"
+	logMaxSize = 1 << 20
+	logBackups = 3
+)
+
+var logLevel = new(slog.LevelVar)
+
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	level, ok := logLevels[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("未知日志级别: %s", s)
+	}
+	return level, nil
+}
+
+// 日志文件的路径，不随档案变化，出错时可以从错误界面直接打开
+func logPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "minesweeper", logName), nil
+}
+
+// 第 i 个旧日志文件，如 minesweeper.1.log
+func logBackupPath(path string, i int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), i, ext)
+}
+
+// 超过大小后轮换的日志文件，可以被多个协程同时写入
+type rotatingFile struct {
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	size  int64
+	owned bool // 已获取实例锁，可以轮换
+}
+
+// 以追加方式打开已有的日志，轮换要等到获取实例锁之后，见 rotateLog
+func openRotatingFile(path string) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// 关闭当前文件，旧文件依次后移，最旧的一个被覆盖。
+// 当前文件无法移走时（如仍被其他进程打开）继续追加写入原文件
+func (r *rotatingFile) rotate() error {
+	if r.f != nil {
+		r.f.Close()
+	}
+	for i := logBackups - 1; i > 0; i-- {
+		os.Rename(logBackupPath(r.path, i), logBackupPath(r.path, i+1))
+	}
+	if err := os.Rename(r.path, logBackupPath(r.path, 1)); err != nil && !os.IsNotExist(err) {
+		if openErr := r.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.owned && r.size > 0 && r.size+int64(len(p)) > logMaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+var logFile *rotatingFile
+
+// 获取实例锁后调用：开始新的日志文件，之后超过大小时也自动轮换。
+// 启动时其他实例可能正在写同一个文件，必须在确认没有其他实例之后才能轮换
+func rotateLog() {
+	if logFile == nil {
+		return
+	}
+	logFile.mu.Lock()
+	logFile.owned = true
+	err := logFile.rotate()
+	logFile.mu.Unlock()
+	if err != nil {
+		slog.Warn("轮换日志文件失败", "err", err)
+	}
+}
+
+// 打开日志文件并设置默认的日志记录器，文件无法打开时只写入标准错误
+func openLog() {
+	var w io.Writer = os.Stderr
+	path, err := logPath()
+	if err == nil {
+		logFile, err = openRotatingFile(path)
+	}
+	if err == nil {
+		w = io.MultiWriter(os.Stderr, logFile)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: logLevel})))
+	if err != nil {
+		slog.Warn("打开日志文件失败", "err", err)
+	}
+}
+
+// 记录错误后退出，代替 log.Fatal，退出前关闭日志文件
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	if logFile != nil {
+		logFile.Close()
+	}
+	os.Exit(1)
+}
+
+// 对局的开始和结束记入日志，提交问题报告时可以看到出错前玩过的棋盘
+func subscribeLog(bus *EventBus) {
+	bus.Subscribe(EventGameStarted, func(e Event) {
+		slog.Debug("开局", "difficulty", difficultyKeys[e.Difficulty], "variant", e.Variant, "seed", e.Seed, "x", e.X, "y", e.Y)
+	})
+	end := func(result string) func(Event) {
+		return func(e Event) {
+			slog.Info(result, "difficulty", difficultyKeys[e.Difficulty], "variant", e.Variant, "hash", e.Hash, "elapsed", e.Elapsed, "moves", len(e.Moves))
+		}
+	}
+	bus.Subscribe(EventGameWon, end("获胜"))
+	bus.Subscribe(EventGameLost, end("踩雷"))
+	bus.Subscribe(EventGameAbandoned, end("放弃"))
+}
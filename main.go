@@ -1,38 +1,182 @@
-package main
-
-import (
-	"log"
-
-	_ "github.com/ebitengine/hideconsole"
-	"github.com/hajimehoshi/ebiten/v2"
-)
-
-//go:generate go run tools/generate.go
-
-const (
-	screenWidth  = 800
-	screenHeight = 600
-	cellSize     = 32
-	gridWidth    = 16
-	gridHeight   = 16
-	mineCount    = 40
-)
-
-func main() {
-	game, err := NewGame(Easy) // 默认中等难度
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	config := difficultySettings[Easy]
-	windowWidth := config.GridWidth * cellSize
-	windowHeight := config.GridHeight*cellSize + 80 // 增加底部空间
-
-	ebiten.SetWindowSize(windowWidth, windowHeight)
-	ebiten.SetWindowTitle("扫雷游戏")
-	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeType(1))
-
-	if err := ebiten.RunGame(game); err != nil {
-		log.Fatal(err)
-	}
-}
+package main
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	_ "github.com/ebitengine/hideconsole"
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/storage"
+)
+
+//go:generate go run tools/generate.go
+
+const (
+	screenWidth  = 800
+	screenHeight = 600
+	cellSize     = 32
+)
+
+// 加载当前档案的配置和统计数据，各项数据保存时使用加载时打开的存储
+func (g *Game) loadProfile() {
+	// 切换档案时同步完旧档案的存档再停止
+	if s, ok := g.store.(*storage.Sync); ok {
+		if err := s.Close(); err != nil {
+			slog.Warn("同步存档失败", "err", err)
+		}
+	}
+	store := openStorage()
+	g.store = store
+
+	cfg, err := loadConfig(store)
+	if err != nil {
+		slog.Warn("读取配置失败", "err", err)
+	}
+	globalConfig = cfg
+	// 配置中有同步服务器地址和本机的窗口位置，只保存在本机，其余存档通过服务器在设备间同步
+	if cfg.SyncServer != "" {
+		store = storage.NewSync(store, &storage.HTTPRemote{URL: cfg.SyncServer})
+		g.store = store
+	}
+	checkExperiments(cfg)
+	activeTheme = selectTheme(cfg, time.Now())
+	setWindowTitle(tr("扫雷游戏"))
+	applyWindowMode()
+
+	stats, err := loadStats(store)
+	if err != nil {
+		slog.Warn("读取统计数据失败", "err", err)
+	}
+	stats.subscribe(events)
+	globalStats = stats
+
+	seeds, err := loadSeedHistory(store)
+	if err != nil {
+		slog.Warn("读取种子记录失败", "err", err)
+	}
+	seeds.subscribe(events)
+	globalSeeds = seeds
+
+	g.subscribeAutosave(events)
+
+	puzzles, err := loadPuzzleProgress(store)
+	if err != nil {
+		slog.Warn("读取谜题记录失败", "err", err)
+	}
+	puzzles.subscribe(events)
+	globalPuzzles = puzzles
+
+	arcadeScores, err := loadArcadeScores(store)
+	if err != nil {
+		slog.Warn("读取街机排行榜失败", "err", err)
+	}
+	arcadeScores.subscribe(events)
+	globalArcade = arcadeScores
+
+	drills, err := loadDrillStats(store)
+	if err != nil {
+		slog.Warn("读取练习统计失败", "err", err)
+	}
+	globalDrills = drills
+
+	board, err := loadScoreboard(store)
+	if err != nil {
+		slog.Warn("读取计分板失败", "err", err)
+	}
+	board.subscribe(events)
+	globalScoreboard = board
+
+	// 切换档案时停止旧队列的后台提交
+	globalSubmissions.close()
+	submissions, err := loadSubmissionQueue(store)
+	if err != nil {
+		slog.Warn("读取待提交成绩失败", "err", err)
+	}
+	submissions.subscribe(events)
+	submissions.start()
+	globalSubmissions = submissions
+
+	rivals, err := loadRivalCache(store)
+	if err != nil {
+		slog.Warn("读取对手成绩失败", "err", err)
+	}
+	globalRivals = rivals
+}
+
+func main() {
+	opts, err := parseLaunchOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	openLog()
+	if err != nil {
+		showStartupError(&startupError{title: "启动参数无效", err: err, hints: []string{"使用 -h 参数查看可用的启动参数"}})
+		return
+	}
+	logLevel.Set(opts.logLevel)
+	slog.Info("启动", "version", appVersion(), "os", runtime.GOOS)
+
+	// 启动失败时在窗口中说明原因，不直接退出
+	am, err := NewAssetManager()
+	if err != nil {
+		showStartupError(err)
+		return
+	}
+	game := NewGame(am, Easy)
+	game.subscribe(events)
+	subscribeLog(events)
+
+	globalInstance, err = acquireInstance()
+	if err == nil {
+		rotateLog()
+	}
+	if errors.Is(err, errAlreadyRunning) {
+		// 同一档案已在运行，先询问用户而不是同时读写存档
+		game.switchScene(SceneDuplicate)
+	} else {
+		if err != nil {
+			slog.Warn("检查重复启动失败", "err", err)
+		}
+		game.loadProfile()
+		game.checkNews()
+		game.checkRestore()
+	}
+
+	restoreWindowGeometry(game)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeType(1))
+	// 关闭窗口时先显示本次小结，见 session.go
+	ebiten.SetWindowClosingHandled(true)
+	session.subscribe(events)
+
+	// 检测到重复启动时等待用户选择，不直接开局
+	if game.scene != SceneDuplicate {
+		if err := opts.apply(game); err != nil {
+			showStartupError(&startupError{title: "启动参数无效", err: err, hints: []string{"使用 -h 参数查看可用的启动参数"}})
+			return
+		}
+	}
+
+	if err := ebiten.RunGame(game); err != nil {
+		fatal("游戏异常退出", err)
+	}
+	// 正常退出，下次启动不需要恢复
+	game.clearCheckpoint()
+	// 只保存窗口的位置和大小，见 windowsize.go，启动参数等只在本次运行中生效的设置不会因此写入。
+	// 在重复启动的询问界面退出时没有加载档案，不能覆盖已运行的窗口的配置
+	if game.scene != SceneDuplicate {
+		window := globalConfig.Window
+		if err := patchConfig(func(cfg *Config) { cfg.Window = window }); err != nil {
+			slog.Warn("保存窗口位置失败", "err", err)
+		}
+	}
+	if s, ok := game.store.(*storage.Sync); ok {
+		if err := s.Close(); err != nil {
+			slog.Warn("同步存档失败", "err", err)
+		}
+	}
+}
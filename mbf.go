@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+// MBF 棋盘文件，与其他扫雷工具交换棋盘，格式见 board.ParseMBF。
+// 导入的棋盘作为谜题打开，MBF 不记录起点，选第一个空白格子，没有空白格子时选第一个安全格子
+
+func isMBF(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mbf")
+}
+
+// 以文件名作为谜题名称
+func mbfTitle(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// 把 MBF 文件转换为谜题，name 为谜题名称
+func parseMBFPuzzle(data []byte, name string) (*puzzle, error) {
+	mines, err := board.ParseMBF(data)
+	if err != nil {
+		return nil, err
+	}
+	p := &puzzle{Name: name, StartX: -1, StartY: -1}
+	counts := board.CountNeighborsOn(board.Square8{}, mines)
+	for y, row := range mines {
+		var b strings.Builder
+		for x, m := range row {
+			if m {
+				b.WriteByte(puzzleMine)
+				continue
+			}
+			b.WriteByte(puzzleSafe)
+			if p.StartX < 0 || counts[y][x] == 0 && counts[p.StartY][p.StartX] != 0 {
+				p.StartX, p.StartY = x, y
+			}
+		}
+		p.Rows = append(p.Rows, b.String())
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// 当前对局的地雷布局，只有经典矩形棋盘可以导出为 MBF
+func (g *Game) mbfAllowed() bool {
+	return g.minesPlaced && g.topology == (board.Square8{}) && g.shape == nil
+}
+
+func (g *Game) exportMBF() error {
+	mines := make([][]bool, g.gridHeight)
+	for y, row := range g.grid {
+		mines[y] = make([]bool, g.gridWidth)
+		for x, cell := range row {
+			mines[y][x] = cell.hasMine
+		}
+	}
+	name, err := saveMBF(g.store, mines)
+	if err != nil {
+		slog.Warn("导出 MBF 失败", "err", err)
+		showToast(tr("导出 MBF 失败"))
+		return nil
+	}
+	showToast(tr("已导出 MBF") + ": " + filepath.Base(name))
+	return nil
+}
+
+// 保存到档案存储的 puzzles 目录，返回文件名
+func saveMBF(store storage.Storage, mines [][]bool) (string, error) {
+	data, err := board.EncodeMBF(mines)
+	if err != nil {
+		return "", err
+	}
+	name := "puzzles/board-" + time.Now().Format("20060102-150405") + ".mbf"
+	if err := store.Write(name, data); err != nil {
+		return "", fmt.Errorf("保存 MBF 失败: %v", err)
+	}
+	return name, nil
+}
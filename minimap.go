@@ -0,0 +1,179 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	minimapSize   = 120 // 小地图较长一边的像素数
+	minimapMargin = 8
+)
+
+var (
+	minimapHidden   = color.RGBA{90, 90, 90, 220}
+	minimapRevealed = color.RGBA{200, 200, 200, 220}
+	minimapFlagged  = color.RGBA{220, 40, 40, 255}
+	minimapMine     = color.RGBA{0, 0, 0, 255}
+	minimapEmpty    = color.RGBA{0, 0, 0, 100} // 无尽模式中尚未生成的区域
+)
+
+// 棋盘比窗口大时在角落显示的小地图。每个像素对应一片区域中的一个格子，
+// 所以无论棋盘多大每帧的工作量都一样
+type minimap struct {
+	img    *ebiten.Image
+	pixels []byte
+	bounds image.Rectangle // 小地图覆盖的棋盘范围，以格子为单位
+	screen image.Rectangle // 小地图在屏幕上的位置
+}
+
+// 按棋盘范围更新小地图的尺寸和位置，右下角对齐 (right, bottom)
+func (m *minimap) layout(bounds image.Rectangle, right, bottom int) {
+	w, h := bounds.Dx(), bounds.Dy()
+	if w >= h {
+		w, h = minimapSize, clamp(minimapSize*h/w, 1, minimapSize)
+	} else {
+		w, h = clamp(minimapSize*w/h, 1, minimapSize), minimapSize
+	}
+	if m.img == nil || m.img.Bounds().Dx() != w || m.img.Bounds().Dy() != h {
+		if m.img != nil {
+			m.img.Dispose()
+		}
+		m.img = ebiten.NewImage(w, h)
+		m.pixels = make([]byte, 4*w*h)
+	}
+	m.bounds = bounds
+	m.screen = image.Rect(right-minimapMargin-w, bottom-minimapMargin-h, right-minimapMargin, bottom-minimapMargin)
+}
+
+// 屏幕坐标对应的棋盘格子，不在小地图上时 ok 为 false
+func (m *minimap) cellAt(px, py int) (x, y int, ok bool) {
+	if m.img == nil || !image.Pt(px, py).In(m.screen) {
+		return 0, 0, false
+	}
+	x = m.bounds.Min.X + (px-m.screen.Min.X)*m.bounds.Dx()/m.screen.Dx()
+	y = m.bounds.Min.Y + (py-m.screen.Min.Y)*m.bounds.Dy()/m.screen.Dy()
+	return x, y, true
+}
+
+// 按 sample 返回的颜色绘制小地图，view 是镜头可见的格子范围
+func (m *minimap) draw(screen *ebiten.Image, view image.Rectangle, sample func(x, y int) color.RGBA) {
+	w, h := m.screen.Dx(), m.screen.Dy()
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			c := sample(m.bounds.Min.X+px*m.bounds.Dx()/w, m.bounds.Min.Y+py*m.bounds.Dy()/h)
+			i := 4 * (py*w + px)
+			// WritePixels 需要预乘 alpha 的颜色
+			m.pixels[i] = byte(int(c.R) * int(c.A) / 255)
+			m.pixels[i+1] = byte(int(c.G) * int(c.A) / 255)
+			m.pixels[i+2] = byte(int(c.B) * int(c.A) / 255)
+			m.pixels[i+3] = c.A
+		}
+	}
+	m.img.WritePixels(m.pixels)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(m.screen.Min.X), float64(m.screen.Min.Y))
+	screen.DrawImage(m.img, op)
+
+	// 镜头范围
+	sx := float32(w) / float32(m.bounds.Dx())
+	sy := float32(h) / float32(m.bounds.Dy())
+	vector.StrokeRect(screen,
+		float32(m.screen.Min.X)+float32(view.Min.X-m.bounds.Min.X)*sx,
+		float32(m.screen.Min.Y)+float32(view.Min.Y-m.bounds.Min.Y)*sy,
+		float32(view.Dx())*sx, float32(view.Dy())*sy, 1, color.White, false)
+	vector.StrokeRect(screen, float32(m.screen.Min.X), float32(m.screen.Min.Y), float32(w), float32(h), 1,
+		activeTheme.ButtonBorder, false)
+}
+
+func minimapColor(cell Cell) color.RGBA {
+	switch {
+	case cell.flagged:
+		return minimapFlagged
+	case cell.revealed && cell.hasMine:
+		return minimapMine
+	case cell.revealed:
+		return minimapRevealed
+	}
+	return minimapHidden
+}
+
+// 棋盘超出窗口时才需要小地图
+func (g *Game) hasMinimap() bool {
+	return g.gridWidth > maxViewCols || g.gridHeight > maxViewRows
+}
+
+func (g *Game) cameraCells() image.Rectangle {
+	return image.Rect(g.camX/cellSize, g.camY/cellSize,
+		(g.camX+g.viewWidth()+cellSize-1)/cellSize, (g.camY+g.viewHeight()+cellSize-1)/cellSize)
+}
+
+// 按住左键点击或拖动小地图时把镜头移到对应位置，返回 true 表示输入已被处理
+func (g *Game) updateMinimap() bool {
+	if !g.hasMinimap() || !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+	x, y, ok := g.minimap.cellAt(cursorPosition())
+	if !ok {
+		return false
+	}
+	g.moveCamera(x*cellSize+cellSize/2-g.viewWidth()/2, y*cellSize+cellSize/2-g.viewHeight()/2)
+	return true
+}
+
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	if !g.hasMinimap() {
+		return
+	}
+	g.minimap.layout(image.Rect(0, 0, g.gridWidth, g.gridHeight), g.viewWidth(), g.viewHeight())
+	g.minimap.draw(screen, g.cameraCells(), func(x, y int) color.RGBA {
+		return minimapColor(g.shownCell(x, y))
+	})
+}
+
+// 无尽模式的小地图覆盖所有已生成的区块
+func (g *Game) endlessMinimapBounds() image.Rectangle {
+	e := g.endless
+	bounds := g.endlessCameraCells()
+	for k := range e.chunks {
+		bounds = bounds.Union(image.Rect(k.x*chunkSize, k.y*chunkSize, (k.x+1)*chunkSize, (k.y+1)*chunkSize))
+	}
+	return bounds
+}
+
+func (g *Game) endlessCameraCells() image.Rectangle {
+	e := g.endless
+	return image.Rect(floorDiv(e.camX, cellSize), floorDiv(e.camY, cellSize),
+		floorDiv(e.camX+g.screenWidth()-1, cellSize)+1, floorDiv(e.camY+g.endlessViewHeight()-1, cellSize)+1)
+}
+
+func (g *Game) updateEndlessMinimap() bool {
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+	x, y, ok := g.minimap.cellAt(cursorPosition())
+	if !ok {
+		return false
+	}
+	g.endless.camX = x*cellSize + cellSize/2 - g.screenWidth()/2
+	g.endless.camY = y*cellSize + cellSize/2 - g.endlessViewHeight()/2
+	return true
+}
+
+func (g *Game) drawEndlessMinimap(screen *ebiten.Image) {
+	e := g.endless
+	g.minimap.layout(g.endlessMinimapBounds(), g.screenWidth(), g.endlessViewHeight())
+	g.minimap.draw(screen, g.endlessCameraCells(), func(x, y int) color.RGBA {
+		// 只读取已生成的区块，小地图不会触发生成
+		k := chunkKey{floorDiv(x, chunkSize), floorDiv(y, chunkSize)}
+		c, ok := e.chunks[k]
+		if !ok {
+			return minimapEmpty
+		}
+		return minimapColor(c.cells[y-k.y*chunkSize][x-k.x*chunkSize])
+	})
+}
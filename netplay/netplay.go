@@ -0,0 +1,279 @@
+// Package netplay 实现局域网对战使用的简单协议：TCP 上逐行传输的 JSON 消息
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// 协议版本，双方不一致时拒绝连接。双方按种子各自布雷，布雷算法变化时也要递增
+const ProtocolVersion = 3
+
+// 默认端口
+const DefaultPort = 47800
+
+// 消息类型
+const (
+	TypeHello    = "hello"    // 握手
+	TypeStart    = "start"    // 主机下发棋盘参数，双方开始
+	TypeProgress = "progress" // 完成百分比
+	TypeFinish   = "finish"   // 一方结束：通关或踩雷
+	TypeReveal   = "reveal"   // 合作模式：翻开格子
+	TypeMark     = "mark"     // 合作模式：切换标记
+	TypePing     = "ping"     // 合作模式：提示队友注意某个格子
+	TypeChat     = "chat"     // 合作模式：聊天
+	TypeCursor   = "cursor"   // 合作模式：光标所在格子
+)
+
+// 对局模式
+const (
+	ModeRace   = ""       // 竞速：各自在相同棋盘上比拼
+	ModeCoop   = "coop"   // 合作：多人共用一个棋盘
+	ModeDefuse = "defuse" // 拆弹：两人共用一个棋盘，双方看到的信息不同
+)
+
+type Message struct {
+	Type       string        `json:"type"`
+	Version    int           `json:"version,omitempty"`
+	Mode       string        `json:"mode,omitempty"`
+	Difficulty string        `json:"difficulty,omitempty"`
+	Seed       int64         `json:"seed,omitempty"`
+	StartX     int           `json:"start_x,omitempty"`
+	StartY     int           `json:"start_y,omitempty"`
+	Progress   int           `json:"progress,omitempty"`
+	Won        bool          `json:"won,omitempty"`
+	Elapsed    time.Duration `json:"elapsed,omitempty"`
+	X          int           `json:"x,omitempty"`
+	Y          int           `json:"y,omitempty"`
+	Text       string        `json:"text,omitempty"`
+	Player     int           `json:"player,omitempty"` // 合作模式中操作者的编号，主机为 0
+	Mark       int           `json:"mark,omitempty"`   // 发起标记时看到的格子标记状态，用于检测冲突
+}
+
+// Conn 在后台收发消息，游戏循环通过 Poll 非阻塞地读取
+type Conn struct {
+	conn     net.Conn
+	incoming chan Message
+	outgoing chan Message
+
+	mu     sync.Mutex
+	err    error
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newConn(c net.Conn) (*Conn, error) {
+	conn := &Conn{
+		conn:     c,
+		incoming: make(chan Message, 64),
+		outgoing: make(chan Message, 64),
+		closed:   make(chan struct{}),
+	}
+
+	// 同步完成握手
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := json.NewEncoder(c).Encode(Message{Type: TypeHello, Version: ProtocolVersion}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("发送握手失败: %v", err)
+	}
+	reader := bufio.NewReader(c)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("接收握手失败: %v", err)
+	}
+	var hello Message
+	if err := json.Unmarshal(line, &hello); err != nil || hello.Type != TypeHello {
+		c.Close()
+		return nil, errors.New("对方不是扫雷游戏")
+	}
+	if hello.Version != ProtocolVersion {
+		c.Close()
+		return nil, fmt.Errorf("协议版本不一致: %d != %d", hello.Version, ProtocolVersion)
+	}
+	c.SetDeadline(time.Time{})
+
+	go conn.readLoop(reader)
+	go conn.writeLoop()
+	return conn, nil
+}
+
+func (c *Conn) readLoop(reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		var m Message
+		if err := json.Unmarshal(line, &m); err != nil {
+			c.fail(fmt.Errorf("无效消息: %v", err))
+			return
+		}
+		select {
+		case c.incoming <- m:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *Conn) writeLoop() {
+	encoder := json.NewEncoder(c.conn)
+	for {
+		select {
+		case m := <-c.outgoing:
+			if err := encoder.Encode(m); err != nil {
+				c.fail(err)
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+	c.Close()
+}
+
+// Send 将消息放入发送队列，连接已关闭时丢弃
+func (c *Conn) Send(m Message) {
+	select {
+	case c.outgoing <- m:
+	case <-c.closed:
+	}
+}
+
+// Poll 取出一条已收到的消息，没有时返回 false
+func (c *Conn) Poll() (Message, bool) {
+	select {
+	case m := <-c.incoming:
+		return m, true
+	default:
+		return Message{}, false
+	}
+}
+
+// Err 返回导致连接断开的错误，连接正常时为 nil
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Conn) Closed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Conn) Close() {
+	c.once.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+// Host 监听端口并在后台接受对手连接，直到 Close
+type Host struct {
+	listener net.Listener
+	accepted chan *Conn
+	done     chan struct{}
+	once     sync.Once
+}
+
+func Listen(port int) (*Host, error) {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("监听端口失败: %v", err)
+	}
+	h := &Host{listener: ln, accepted: make(chan *Conn, 8), done: make(chan struct{})}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn, err := newConn(c)
+			if err != nil {
+				// 握手失败的连接直接忽略，继续等待
+				continue
+			}
+			select {
+			case h.accepted <- conn:
+			case <-h.done:
+				conn.Close()
+				return
+			}
+		}
+	}()
+	return h, nil
+}
+
+// Accepted 返回下一个已连接的对手，没有新连接时返回 nil
+func (h *Host) Accepted() *Conn {
+	select {
+	case c := <-h.accepted:
+		return c
+	default:
+		return nil
+	}
+}
+
+// Close 停止接受新连接，已经返回的连接不受影响
+func (h *Host) Close() {
+	h.once.Do(func() {
+		close(h.done)
+		h.listener.Close()
+	})
+	// 关闭尚未取走的连接
+	for {
+		select {
+		case c := <-h.accepted:
+			c.Close()
+		default:
+			return
+		}
+	}
+}
+
+// Dial 连接主机并完成握手，addr 可以省略端口
+func Dial(addr string) (*Conn, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(DefaultPort))
+	}
+	c, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("连接失败: %v", err)
+	}
+	return newConn(c)
+}
+
+// LocalAddresses 返回本机的局域网 IPv4 地址，供对手输入
+func LocalAddresses() []string {
+	var result []string
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			result = append(result, ipnet.IP.String())
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"minesweeper/assets"
+
+	"golang.org/x/image/font"
+)
+
+// 更新日志中的一个版本
+type changelogEntry struct {
+	Version string              `json:"version"`
+	Items   []map[string]string `json:"items"` // 语言代码 -> 文字
+}
+
+func loadChangelog() ([]changelogEntry, error) {
+	data, err := assets.GetChangelog()
+	if err != nil {
+		return nil, fmt.Errorf("加载更新日志失败: %v", err)
+	}
+	var entries []changelogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析更新日志失败: %v", err)
+	}
+	return entries, nil
+}
+
+// 自上次查看以来的新版本条目；首次运行只返回最新版本
+func unseenChangelog(entries []changelogEntry, lastSeen string) []changelogEntry {
+	if len(entries) == 0 || entries[0].Version == lastSeen {
+		return nil
+	}
+	if lastSeen == "" {
+		return entries[:1]
+	}
+	for i, entry := range entries {
+		if entry.Version == lastSeen {
+			return entries[:i]
+		}
+	}
+	return entries
+}
+
+// 启动时检查是否有未读的更新内容，有则先显示更新面板
+func (g *Game) checkNews() {
+	entries, err := loadChangelog()
+	if err != nil {
+		slog.Warn("读取更新记录失败", "err", err)
+		return
+	}
+	g.news = unseenChangelog(entries, globalConfig.LastSeenVersion)
+	if len(g.news) > 0 {
+		g.switchScene(SceneNews)
+	}
+}
+
+func (g *Game) dismissNews() {
+	globalConfig.LastSeenVersion = g.news[0].Version
+	g.news = nil
+	if err := saveConfig(globalConfig); err != nil {
+		slog.Warn("保存配置失败", "err", err)
+	}
+	if g.restore != nil {
+		g.switchScene(SceneRestore)
+		return
+	}
+	g.switchScene(SceneMainMenu)
+}
+
+func (g *Game) newsLines() []string {
+	var lines []string
+	for _, entry := range g.news {
+		lines = append(lines, "v"+entry.Version)
+		for _, item := range entry.Items {
+			msg, ok := item[currentLanguage()]
+			if !ok {
+				msg = item["zh"]
+			}
+			lines = append(lines, wrapText(g.gameFont, "· "+msg, g.screenWidth()-40)...)
+		}
+	}
+	return lines
+}
+
+// 按像素宽度折行
+func wrapText(face font.Face, s string, maxWidth int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, r := range s {
+		if line.Len() > 0 && font.MeasureString(face, line.String()+string(r)).Ceil() > maxWidth {
+			lines = append(lines, line.String())
+			line.Reset()
+			line.WriteString("  ")
+		}
+		line.WriteRune(r)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
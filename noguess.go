@@ -0,0 +1,72 @@
+package main
+
+import (
+	"time"
+
+	"minesweeper/solver"
+)
+
+// 无猜模式要求的推理深度，实验性的 deep 放在末尾
+var noGuessModes = []string{"", "trivial", "subset", "deep"}
+
+var noGuessTitles = map[string]string{
+	"":        "关",
+	"trivial": "简单推理",
+	"subset":  "子集推理",
+	"deep":    "深度推理",
+}
+
+const noGuessTimeBudget = 1500 * time.Millisecond
+
+// 后台进行中的无猜布局搜索，首次点击后开始，找到布局后再翻开点击的格子
+type noGuessSearch struct {
+	x, y    int
+	rerolls int        // 找到的布局最近玩过而重新搜索的次数
+	result  chan int64 // 缓冲为 1，对局重置后搜索结果直接丢弃
+}
+
+// 首次点击 (x, y) 时是否需要搜索无猜布局，求解器模拟的是完整的经典棋盘，其他拓扑和形状不适用
+func (g *Game) wantsNoGuess(x, y int) bool {
+	return globalConfig.NoGuess != "" && x >= 0 && g.topologyName() == "" && g.shape == nil &&
+		g.puzzle == nil && g.arcade == nil && g.bot == nil
+}
+
+// 在后台从当前种子派生符合无猜模式的布局种子，见 solver.NoGuessSeed
+func (g *Game) startNoGuessSearch(x, y, rerolls int) {
+	config := g.size
+	mode := globalConfig.NoGuess
+	if mode == solver.NoGuessDeep && !experimentEnabled(expDeepNoGuess) {
+		mode = solver.NoGuessSubset
+	}
+	s := &noGuessSearch{x: x, y: y, rerolls: rerolls, result: make(chan int64, 1)}
+	g.noGuess = s
+	seed := g.seed
+	go func() {
+		candidate, _ := solver.NoGuessSeed(config.GridWidth, config.GridHeight, config.MineCount, seed,
+			solver.Point{X: x, Y: y}, mode, noGuessTimeBudget)
+		s.result <- candidate
+	}()
+}
+
+// 搜索完成后按找到的种子布雷并翻开首次点击的格子
+func (g *Game) updateNoGuess() {
+	s := g.noGuess
+	if s == nil {
+		return
+	}
+	select {
+	case seed := <-s.result:
+		g.noGuess = nil
+		g.seed = seed
+		g.layoutMines(s.x, s.y)
+		// 最近玩过相同的布局时换一个种子重新搜索
+		if s.rerolls < maxRerolls && globalSeeds.playedRecently(g.boardHash) {
+			g.minesPlaced = false
+			g.seed = g.rng.Int64()
+			g.startNoGuessSearch(s.x, s.y, s.rerolls+1)
+			return
+		}
+		g.revealAt(s.x, s.y)
+	default:
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"minesweeper/storage"
+)
+
+// 观战用的棋盘状态，只包含玩家能看到的信息，不泄露地雷位置，
+// 与存档格式无关，供直播叠加层和外部工具读取
+type observerState struct {
+	Difficulty string `json:"difficulty"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Mines      int    `json:"mines"`
+	MinesLeft  int    `json:"mines_left"`
+	Elapsed    int64  `json:"elapsed_ms"`
+	Status     string `json:"status"` // menu, ready, playing, won, lost
+	// 每行一个字符串：# 未翻开，F 旗帜，? 问号，0-8 数字，
+	// 结束后 * 为地雷，X 为踩中的地雷，x 为插错的旗帜
+	Rows []string `json:"rows"`
+}
+
+// 默认的观战端口，只监听本机
+const defaultObserverPort = 47801
+
+// 观战输出：最新状态缓存在内存中供 HTTP 读取，并写入 observer.json
+type observer struct {
+	latest  atomic.Value // []byte
+	key     string
+	writes  chan observerWrite
+	server  *http.Server
+	running bool
+}
+
+type observerWrite struct {
+	store storage.Storage
+	data  []byte
+}
+
+var globalObserver = &observer{writes: make(chan observerWrite, 1)}
+
+func init() {
+	go globalObserver.writeLoop()
+}
+
+func (g *Game) observerState() observerState {
+	config := g.size
+	s := observerState{
+		Difficulty: difficultyKeys[g.difficulty],
+		Width:      config.GridWidth,
+		Height:     config.GridHeight,
+		Mines:      g.mineCount(),
+		MinesLeft:  g.minesLeft(),
+		Elapsed:    g.elapsedTime.Milliseconds(),
+	}
+	switch {
+	case g.scene != ScenePlaying:
+		s.Status = "menu"
+	case g.won:
+		s.Status = "won"
+	case g.gameOver:
+		s.Status = "lost"
+	case g.firstClick:
+		s.Status = "ready"
+	default:
+		s.Status = "playing"
+	}
+
+	for y, row := range g.grid {
+		line := make([]byte, len(row))
+		for x, cell := range row {
+			line[x] = observedCell(cell, g.gameOver, x == g.explodedX && y == g.explodedY)
+		}
+		s.Rows = append(s.Rows, string(line))
+	}
+	return s
+}
+
+func observedCell(cell Cell, gameOver, exploded bool) byte {
+	switch {
+	case cell.revealed && cell.hasMine && exploded && gameOver:
+		return 'X'
+	case cell.revealed && cell.hasMine:
+		return '*'
+	case cell.revealed:
+		return byte('0' + cell.neighbors)
+	case cell.flagged && gameOver && !cell.hasMine:
+		return 'x'
+	case cell.flagged:
+		return 'F'
+	case cell.questioned:
+		return '?'
+	}
+	return '#'
+}
+
+// 每帧调用，状态变化时更新缓存和文件
+func (g *Game) updateObserver() {
+	o := globalObserver
+	if !globalConfig.ObserverOutput {
+		o.stop()
+		return
+	}
+	o.start()
+
+	// 操作、结果或整秒计时变化时才重新生成
+	key := fmt.Sprint(g.scene, g.difficulty, len(g.moves), g.gameOver, g.won, int(g.elapsedTime.Seconds()))
+	if key == o.key {
+		return
+	}
+	o.key = key
+
+	data, err := json.Marshal(g.observerState())
+	if err != nil {
+		slog.Warn("生成观战状态失败", "err", err)
+		return
+	}
+	o.latest.Store(data)
+	select {
+	case <-o.writes:
+	default:
+	}
+	o.writes <- observerWrite{g.store, data}
+}
+
+func (o *observer) writeLoop() {
+	for w := range o.writes {
+		if err := w.store.Write("observer.json", w.data); err != nil {
+			slog.Warn("写入观战状态失败", "err", err)
+		}
+	}
+}
+
+func (o *observer) start() {
+	if o.running {
+		return
+	}
+	o.running = true
+
+	port := globalConfig.ObserverPort
+	if port == 0 {
+		port = defaultObserverPort
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		// 端口被占用时仍然输出文件
+		slog.Warn("启动观战服务失败", "err", err)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/state", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := o.latest.Load().([]byte)
+		if data == nil {
+			http.Error(w, "no state", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		// 允许浏览器中的直播叠加层跨域读取
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(data)
+	})
+	o.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go o.server.Serve(ln)
+}
+
+func (o *observer) stop() {
+	if !o.running {
+		return
+	}
+	o.running = false
+	o.key = ""
+	if o.server != nil {
+		o.server.Close()
+		o.server = nil
+	}
+}
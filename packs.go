@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/assets"
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+// 谜题包：一组按顺序排列的谜题。内置的谜题包随游戏发布，
+// 档案目录 packs 文件夹中的 JSON 文件作为外部谜题包加载
+type puzzlePack struct {
+	Name    string    `json:"name"`
+	Author  string    `json:"author,omitempty"`
+	Puzzles []*puzzle `json:"puzzles"`
+}
+
+func parsePuzzlePack(data []byte) (*puzzlePack, error) {
+	var p puzzlePack
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("解析谜题包失败: %v", err)
+	}
+	if len(p.Puzzles) == 0 {
+		return nil, fmt.Errorf("谜题包 %s 是空的", p.Name)
+	}
+	for i, pz := range p.Puzzles {
+		if err := pz.validate(); err != nil {
+			return nil, fmt.Errorf("谜题包 %s 第 %d 题无效: %v", p.Name, i+1, err)
+		}
+	}
+	return &p, nil
+}
+
+// 加载内置和外部的谜题包，无效的谜题包记录日志后跳过
+func loadPuzzlePacks() []*puzzlePack {
+	var packs []*puzzlePack
+	names, err := assets.ListPuzzlePacks()
+	if err != nil {
+		slog.Warn("读取内置谜题包失败", "err", err)
+	}
+	for _, name := range names {
+		data, err := assets.GetPuzzlePack(name)
+		if err != nil {
+			slog.Warn("读取内置谜题包失败", "err", err)
+			continue
+		}
+		p, err := parsePuzzlePack(data)
+		if err != nil {
+			slog.Warn("内置谜题包无效", "err", err)
+			continue
+		}
+		packs = append(packs, p)
+	}
+
+	dir, err := dataDir()
+	if err != nil {
+		return packs
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "packs", "*.json"))
+	sort.Strings(files)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			slog.Warn("读取谜题包失败", "err", err)
+			continue
+		}
+		p, err := parsePuzzlePack(data)
+		if err != nil {
+			slog.Warn("谜题包无效", "err", err)
+			continue
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		packs = append(packs, p)
+	}
+	return packs
+}
+
+// 谜题的完成记录，按地雷布局的哈希保存，同一个谜题出现在多个谜题包中时共用记录
+type puzzleRecord struct {
+	Time  time.Duration `json:"time"`
+	Stars int           `json:"stars"`
+}
+
+type PuzzleProgress struct {
+	Records map[string]*puzzleRecord `json:"records"`
+
+	name  string
+	store storage.Storage
+}
+
+var globalPuzzles = &PuzzleProgress{Records: make(map[string]*puzzleRecord)}
+
+func loadPuzzleProgress(store storage.Storage) (*PuzzleProgress, error) {
+	p := &PuzzleProgress{name: "puzzles.json", store: store}
+	err := loadFile(p.store, p.name, "puzzles", p)
+	if p.Records == nil {
+		p.Records = make(map[string]*puzzleRecord)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("读取谜题记录失败: %v", err)
+	}
+	return p, nil
+}
+
+func (p *PuzzleProgress) save() error {
+	if p.name == "" {
+		return nil
+	}
+	return saveFile(p.store, p.name, "puzzles", p)
+}
+
+// 星级：完成得一星，3BV/s 不低于 1 得两星，同时没有多余的点击得三星
+func puzzleStars(e Event) int {
+	stars := 1
+	if e.Elapsed > 0 && float64(e.BBBV)/e.Elapsed.Seconds() >= 1 {
+		stars++
+		if len(e.Moves) <= e.BBBV {
+			stars++
+		}
+	}
+	return stars
+}
+
+func (p *PuzzleProgress) subscribe(bus *EventBus) {
+	bus.Subscribe(EventGameWon, func(e Event) {
+		if e.Variant != "puzzle" {
+			return
+		}
+		r := p.Records[e.Hash]
+		if r == nil {
+			r = &puzzleRecord{}
+			p.Records[e.Hash] = r
+		}
+		if r.Time == 0 || e.Elapsed < r.Time {
+			r.Time = e.Elapsed
+		}
+		if stars := puzzleStars(e); stars > r.Stars {
+			r.Stars = stars
+		}
+		if err := p.save(); err != nil {
+			slog.Warn("保存谜题记录失败", "err", err)
+		}
+	})
+}
+
+func (p *PuzzleProgress) record(pz *puzzle) *puzzleRecord {
+	return p.Records[board.Hash(pz.mines())]
+}
+
+// 谜题列表界面的状态，从菜单进入时重新加载谜题包
+var puzzleList struct {
+	packs []*puzzlePack
+	pack  int
+}
+
+const puzzleColumns = 4
+
+func (g *Game) puzzleButtons() []*menuButton {
+	if puzzleList.packs == nil {
+		puzzleList.packs = loadPuzzlePacks()
+	}
+	if len(puzzleList.packs) == 0 {
+		return []*menuButton{g.backButton()}
+	}
+	puzzleList.pack %= len(puzzleList.packs)
+	pack := puzzleList.packs[puzzleList.pack]
+
+	var buttons []*menuButton
+	// 关卡按编号排成网格，完成的关卡显示星级
+	w := (g.screenWidth() - 20 - 10*(puzzleColumns-1)) / puzzleColumns
+	for i, pz := range pack.Puzzles {
+		pz := pz
+		label := fmt.Sprint(i + 1)
+		if r := globalPuzzles.record(pz); r != nil {
+			label += " " + strings.Repeat("★", r.Stars) + strings.Repeat("☆", 3-r.Stars)
+		}
+		buttons = append(buttons, &menuButton{
+			Button: &Button{X: 10 + i%puzzleColumns*(w+10), Y: 70 + i/puzzleColumns*44, W: w, H: 36, Text: label},
+			action: func() error { return g.confirmAbandon(func() error { return g.beginPuzzle(pz) }) },
+		})
+	}
+
+	switchPack := func(d int) func() error {
+		return func() error {
+			n := len(puzzleList.packs)
+			puzzleList.pack = (puzzleList.pack + d + n) % n
+			g.switchScene(ScenePuzzles)
+			return nil
+		}
+	}
+	// 底部一行：切换谜题包、编辑器和返回
+	row := []*menuButton{
+		{Button: &Button{Text: "上一组", Disabled: len(puzzleList.packs) < 2}, action: switchPack(-1)},
+		{Button: &Button{Text: "下一组", Disabled: len(puzzleList.packs) < 2}, action: switchPack(1)},
+		{Button: &Button{Text: "编辑器"}, action: g.openEditor},
+		g.backButton(),
+	}
+	quarter := (g.screenWidth() - 50) / 4
+	for i, btn := range row {
+		btn.X, btn.Y, btn.W, btn.H = 10+i*(quarter+10), g.screenHeight()-44, quarter, 34
+	}
+	return append(buttons, row...)
+}
+
+func (g *Game) drawPuzzleList(screen *ebiten.Image) {
+	if len(puzzleList.packs) == 0 {
+		g.drawCentered(screen, tr("没有谜题"), 80)
+		return
+	}
+	pack := puzzleList.packs[puzzleList.pack]
+	solved := 0
+	for _, pz := range pack.Puzzles {
+		if globalPuzzles.record(pz) != nil {
+			solved++
+		}
+	}
+	g.drawCentered(screen, fmt.Sprintf("%s  %d/%d", tr(pack.Name), solved, len(pack.Puzzles)), 56)
+}
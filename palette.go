@@ -0,0 +1,168 @@
+package main
+
+import (
+	"image/color"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const paletteRows = 8 // 最多显示的候选命令数
+
+// Ctrl+P 打开的命令面板，输入时按模糊匹配筛选命令
+type commandPalette struct {
+	query    string
+	selected int
+}
+
+func (g *Game) openPalette() {
+	g.palette = &commandPalette{}
+}
+
+// 按顺序在 s 中找到 query 的每个字符即为匹配，连续匹配和靠前的匹配得分更高
+func fuzzyScore(query, s string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	score, qi, last := 0, 0, -2
+	for i, r := range []rune(strings.ToLower(s)) {
+		if qi < len(q) && unicode.ToLower(r) == q[qi] {
+			score += 10
+			if i == last+1 {
+				score += 15
+			}
+			if i == 0 {
+				score += 20
+			}
+			score -= i
+			last = i
+			qi++
+		}
+	}
+	return score, qi == len(q)
+}
+
+// 当前可用且与输入匹配的命令，按得分排序。中文名称、翻译后的名称和 id 都参与匹配
+func (g *Game) paletteMatches() []*command {
+	type match struct {
+		c     *command
+		score int
+	}
+	var matches []match
+	for _, c := range commands {
+		if !c.available(g) || c.id == "palette" {
+			continue
+		}
+		best, ok := -1<<31, false
+		for _, s := range []string{c.title, tr(c.title), c.id} {
+			if score, matched := fuzzyScore(g.palette.query, s); matched && score > best {
+				best, ok = score, true
+			}
+		}
+		if ok {
+			matches = append(matches, match{c, best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	result := make([]*command, len(matches))
+	for i, m := range matches {
+		result[i] = m.c
+	}
+	return result
+}
+
+// 面板打开时处理全部输入，返回 true 表示本帧输入已被面板处理
+func (g *Game) updatePalette() (bool, error) {
+	p := g.palette
+	if p == nil {
+		return false, nil
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		p.query += string(r)
+		p.selected = 0
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && p.query != "" {
+		runes := []rune(p.query)
+		p.query = string(runes[:len(runes)-1])
+		p.selected = 0
+	}
+
+	matches := g.paletteMatches()
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) && p.selected < len(matches)-1 {
+		p.selected++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) && p.selected > 0 {
+		p.selected--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.palette = nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		g.palette = nil
+		if p.selected < len(matches) {
+			g.playSound("click")
+			return true, matches[p.selected].run(g)
+		}
+	}
+	return true, nil
+}
+
+func (g *Game) drawPalette(screen *ebiten.Image) {
+	p := g.palette
+	if p == nil {
+		return
+	}
+	width := float32(g.screenWidth() - 40)
+	vector.DrawFilledRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		activeTheme.MenuOverlay, false)
+	vector.DrawFilledRect(screen, 20, 20, width, 28, activeTheme.ButtonBg, false)
+	vector.StrokeRect(screen, 20, 20, width, 28, 1, activeTheme.ButtonBorder, false)
+	text.Draw(screen, "> "+p.query, g.gameFont, 28, 40, activeTheme.Text)
+
+	// 选中项较靠后时向下滚动
+	matches := g.paletteMatches()
+	first := 0
+	if p.selected >= paletteRows {
+		first = p.selected - paletteRows + 1
+	}
+	for i := first; i < len(matches) && i < first+paletteRows; i++ {
+		y := 52 + (i-first)*26
+		bg := activeTheme.ButtonBg
+		if i == p.selected {
+			bg = activeTheme.ButtonHover
+		}
+		vector.DrawFilledRect(screen, 20, float32(y), width, 24, bg, false)
+		text.Draw(screen, tr(matches[i].title), g.gameFont, 28, y+18, activeTheme.Text)
+		if hint := matches[i].hint(); hint != "" {
+			w := text.BoundString(g.gameFont, hint).Dx()
+			text.Draw(screen, hint, g.gameFont, 20+int(width)-w-8, y+18, color.RGBA{150, 150, 150, 255})
+		}
+	}
+}
+
+// 快捷键的显示文字
+func (c *command) hint() string {
+	if b, ok := actionBinding(c.id); ok {
+		return b.title()
+	}
+	if c.key == noKey {
+		return ""
+	}
+	name := c.key.String()
+	switch c.key {
+	case ebiten.KeyBackquote:
+		name = "`"
+	}
+	if c.ctrl {
+		name = "Ctrl+" + name
+	}
+	return name
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"minesweeper/storage"
+)
+
+// 持久化文件的外层结构，带校验和以发现损坏
+type envelope struct {
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// 迁移函数把数据从版本 i 升级到 i+1
+type migration func(data map[string]any) error
+
+// 每种文件按顺序排列的迁移函数，当前版本号即迁移函数的数量。
+// 修改文件格式时只需在末尾追加新的迁移函数，旧文件读取时会依次升级。
+var migrations = map[string][]migration{
+	"config": {
+		// 0 -> 1: 引入版本号，之前的文件没有外层结构或版本字段
+		func(data map[string]any) error { return nil },
+	},
+	"stats": {
+		// 0 -> 1: 引入版本号
+		func(data map[string]any) error { return nil },
+	},
+	"seeds": {
+		// 0 -> 1: 记录布雷算法的版本。之前的记录按旧算法布雷，重玩时按哈希判断布局是否相同
+		func(data map[string]any) error {
+			records, _ := data["records"].([]any)
+			for _, r := range records {
+				if r, ok := r.(map[string]any); ok && r["layout"] == nil {
+					r["layout"] = 1
+				}
+			}
+			return nil
+		},
+	},
+	"checkpoint": {
+		// 0 -> 1: 布雷改用 math/rand/v2，旧存档的种子得到的布局不同，只有谜题可以恢复
+		func(data map[string]any) error {
+			if data["puzzle"] == nil {
+				return errors.New("旧版本的布局无法重现")
+			}
+			return nil
+		},
+		// 1 -> 2: 棋盘改用 board.State 的格式保存，旧存档只有谜题带有地雷布局
+		func(data map[string]any) error {
+			p, _ := data["puzzle"].(map[string]any)
+			if p == nil {
+				return errors.New("旧版本的存档没有地雷布局")
+			}
+			rows, _ := data["rows"].([]any)
+			layout, _ := p["rows"].([]any)
+			if len(rows) == 0 || len(layout) != len(rows) {
+				return errors.New("存档尺寸不匹配")
+			}
+			mines := make([]any, len(layout))
+			for i, row := range layout {
+				s, _ := row.(string)
+				mines[i] = strings.ReplaceAll(s, string(puzzleMissing), " ")
+			}
+			width, _ := rows[0].(string)
+			elapsed, _ := data["elapsed"].(float64)
+			data["version"] = 1
+			data["width"] = len(width)
+			data["height"] = len(rows)
+			data["elapsed_ms"] = int64(elapsed / 1e6)
+			data["mines"] = mines
+			data["cells"] = rows
+			delete(data, "rows")
+			delete(data, "elapsed")
+			return nil
+		},
+	},
+}
+
+func schemaVersion(kind string) int {
+	return len(migrations[kind])
+}
+
+// 将 version 版本的数据依次升级到当前版本
+func migrate(kind string, version int, data []byte) ([]byte, error) {
+	steps := migrations[kind]
+	if version >= len(steps) {
+		if version > len(steps) {
+			slog.Warn("文件版本高于当前支持的版本，尽量读取", "kind", kind, "version", version, "supported", len(steps))
+		}
+		return data, nil
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for v := version; v < len(steps); v++ {
+		if err := steps[v](m); err != nil {
+			return nil, fmt.Errorf("从版本 %d 迁移失败: %v", v, err)
+		}
+	}
+	return json.Marshal(m)
+}
+
+var errChecksum = errors.New("校验和不匹配")
+
+func checksum(data []byte) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// 打开当前档案的目录，无法获取目录时退回到内存存储，数据在退出后丢失
+func openStorage() storage.Storage {
+	dir, err := dataDir()
+	if err != nil {
+		slog.Warn("获取数据目录失败，存档不会保存", "err", err)
+		return storage.NewMemory()
+	}
+	return storage.Dir(dir)
+}
+
+// 将 v 序列化后带版本号和校验和写入 store 中的 name，旧文件校验通过时先备份为 .bak
+func saveFile(store storage.Storage, name, kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化失败: %v", err)
+	}
+	sum, err := checksum(data)
+	if err != nil {
+		return fmt.Errorf("计算校验和失败: %v", err)
+	}
+	out, err := json.MarshalIndent(envelope{
+		Version:  schemaVersion(kind),
+		Checksum: sum,
+		Data:     data,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化失败: %v", err)
+	}
+
+	if old, err := store.Read(name); err == nil {
+		if _, _, err := unwrap(old); err == nil {
+			if err := store.Write(name+".bak", old); err != nil {
+				return err
+			}
+		}
+	}
+
+	return store.Write(name, out)
+}
+
+// 读取 name 并校验、迁移到当前版本，文件损坏时尝试从 .bak 恢复；文件不存在时返回 os.ErrNotExist
+func loadFile(store storage.Storage, name, kind string, v any) error {
+	raw, err := store.Read(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var data []byte
+	var version int
+	if err == nil {
+		data, version, err = unwrap(raw)
+	}
+	if err != nil {
+		backup, bakErr := store.Read(name + ".bak")
+		if bakErr != nil {
+			return fmt.Errorf("读取 %s 失败: %v", name, err)
+		}
+		data, version, bakErr = unwrap(backup)
+		if bakErr != nil {
+			return fmt.Errorf("读取 %s 失败且备份不可用: %v", name, err)
+		}
+
+		slog.Warn("文件已损坏，从备份恢复", "file", name, "err", err)
+		if err := store.Write(name, backup); err != nil {
+			slog.Warn("恢复文件失败", "err", err)
+		}
+	}
+
+	data, err = migrate(kind, version, data)
+	if err != nil {
+		return fmt.Errorf("升级 %s 失败: %v", name, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %v", name, err)
+	}
+	return nil
+}
+
+// 校验并取出数据部分及其版本号；没有外层结构的旧格式文件视为版本 0 原样返回
+func unwrap(raw []byte) ([]byte, int, error) {
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, 0, err
+	}
+	if env.Checksum == "" || env.Data == nil {
+		return raw, 0, nil
+	}
+
+	sum, err := checksum(env.Data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if sum != env.Checksum {
+		return nil, 0, errChecksum
+	}
+	return env.Data, env.Version, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"minesweeper/discord"
+)
+
+// Discord 应用 ID，发布时通过 -ldflags "-X main.discordAppID=..." 指定，为空时不启用
+var discordAppID string
+
+// Discord 对状态更新有频率限制：两次更新至少间隔 presenceMinGap，
+// 内容不变时每隔 presenceInterval 重发一次，以便 Discord 晚于游戏启动时也能连上
+const (
+	presenceMinGap   = 4 * time.Second
+	presenceInterval = 30 * time.Second
+)
+
+// 在后台与 Discord 通信，游戏循环只提交最新的状态
+type presence struct {
+	updates chan *discord.Activity
+	last    discord.Activity
+	sentAt  time.Time
+	enabled bool
+}
+
+var globalPresence = &presence{updates: make(chan *discord.Activity, 1)}
+
+func init() {
+	go globalPresence.run()
+}
+
+func (p *presence) run() {
+	var client *discord.Client
+	var retryAt time.Time
+	for a := range p.updates {
+		if client == nil {
+			if a == nil || time.Now().Before(retryAt) {
+				continue
+			}
+			c, err := discord.Connect(discordAppID)
+			if err != nil {
+				// Discord 未运行时不频繁重试
+				retryAt = time.Now().Add(time.Minute)
+				continue
+			}
+			client = c
+		}
+		if err := client.SetActivity(a); err != nil {
+			slog.Warn("更新 Discord 状态失败", "err", err)
+			client.Close()
+			client = nil
+		}
+	}
+}
+
+// 提交状态，旧的未发送状态会被替换
+func (p *presence) submit(a *discord.Activity) {
+	select {
+	case <-p.updates:
+	default:
+	}
+	p.updates <- a
+}
+
+// 每帧调用，状态变化或间隔到期时提交
+func (g *Game) updatePresence() {
+	p := globalPresence
+	if !globalConfig.DiscordPresence || discordAppID == "" {
+		if p.enabled {
+			p.enabled = false
+			p.submit(nil)
+		}
+		return
+	}
+
+	if p.enabled && time.Since(p.sentAt) < presenceMinGap {
+		return
+	}
+	a := g.activity()
+	if p.enabled && a == p.last && time.Since(p.sentAt) < presenceInterval {
+		return
+	}
+	p.enabled = true
+	p.last = a
+	p.sentAt = time.Now()
+	p.submit(&a)
+}
+
+func (g *Game) activity() discord.Activity {
+	a := discord.Activity{Details: tr("扫雷游戏") + " - " + tr(difficultyNames[g.difficulty])}
+	switch {
+	case g.scene != ScenePlaying:
+		a.State = tr("在菜单中")
+	case g.won:
+		a.State = fmt.Sprintf(tr("胜利 %s"), formatDuration(g.elapsedTime))
+	case g.gameOver:
+		a.State = tr("踩雷了")
+	default:
+		a.State = fmt.Sprintf(tr("剩余地雷 %d"), g.minesLeft())
+		if !g.firstClick {
+			a.Start = g.startTime
+		}
+	}
+	return a
+}
+
+// 地雷总数减去已插旗数
+func (g *Game) minesLeft() int {
+	left := g.mineCount()
+	for _, row := range g.grid {
+		for _, cell := range row {
+			if cell.flagged {
+				left--
+			}
+		}
+	}
+	return left
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/solver"
+)
+
+// 地雷概率提示：按玩家能看到的信息计算每个未翻开格子是地雷的概率，
+// 从绿色（安全）到红色（地雷）着色并显示百分比。对战、挑战和街机模式中不可用，
+// 其他对局中显示过概率的成绩单独记录，见 variant
+var probability probabilityCache
+
+type probabilityCache struct {
+	on    bool
+	moves int      // 计算时的操作数，之后有新操作时重新计算
+	grid  [][]Cell // 计算时的棋盘，换局后重新计算
+	probs []float64
+}
+
+func (g *Game) probabilityAllowed() bool {
+	return g.race == nil && g.challenge == nil && g.coop == nil && g.arcade == nil
+}
+
+func (g *Game) toggleProbability() error {
+	if g.arcade != nil {
+		showToast(tr("街机模式中不可用"))
+		return nil
+	}
+	if !g.probabilityAllowed() {
+		showToast(tr("对战和挑战中不可用"))
+		return nil
+	}
+	probability.on = !probability.on
+	probability.grid = nil
+	return nil
+}
+
+// 棋盘变化后在 Update 中重新计算概率，Draw 只使用缓存的结果
+func (g *Game) updateProbability() {
+	if !probability.on || !g.probabilityAllowed() || !g.inProgress() {
+		return
+	}
+	g.assisted = true
+	if probability.current(g) {
+		return
+	}
+	probability.grid, probability.moves = g.grid, len(g.moves)
+	probability.probs = solver.Probabilities(g.visibleView(), g.mineCount())
+}
+
+// 缓存的结果是否对应当前棋盘
+func (p *probabilityCache) current(g *Game) bool {
+	return len(p.grid) > 0 && &p.grid[0] == &g.grid[0] && p.moves == len(g.moves)
+}
+
+func (g *Game) drawProbability(screen *ebiten.Image) {
+	if !probability.on || !g.probabilityAllowed() || !g.inProgress() {
+		return
+	}
+	// 刚有新操作时沿用上一次的结果，避免闪烁，已翻开的格子不会着色
+	if probability.probs == nil || len(probability.grid) == 0 || &probability.grid[0] != &g.grid[0] {
+		return
+	}
+
+	for y, row := range g.grid {
+		for x, cell := range row {
+			if cell.revealed || cell.flagged || !g.exists(x, y) {
+				continue
+			}
+			p := probability.probs[y*g.gridWidth+x]
+			clr := color.RGBA{uint8(200 * p), uint8(200 * (1 - p)), 0, 90}
+			px, py := g.cellScreenPos(x, y)
+			vector.DrawFilledRect(screen, px+2, py+2, cellSize-4, cellSize-4, clr, false)
+			drawCellText(screen, strconv.Itoa(int(p*100+0.5)), float64(px), float64(py), cellSize)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"minesweeper/storage"
+)
+
+const maxTraceEvents = 200000 // 约 1 分钟的记录，超出后自动停止
+
+// 按名称统计每帧各部分耗时，调试模式下显示，也可以录制为 Chrome 追踪文件
+// （chrome://tracing 或 Perfetto 打开）
+type profiler struct {
+	stats   map[string]*spanStat
+	order   []string // 按首次出现的顺序显示
+	tracing bool
+	start   time.Time // 开始录制的时间，追踪文件中的时间戳相对于它
+	events  []traceEvent
+	store   storage.Storage // 开始录制时的档案存储，停止时写入追踪文件
+}
+
+type spanStat struct {
+	last time.Duration
+	avg  time.Duration // 指数移动平均
+}
+
+type traceEvent struct {
+	Name string `json:"name"`
+	Ph   string `json:"ph"`
+	Ts   int64  `json:"ts"`  // 微秒
+	Dur  int64  `json:"dur"` // 微秒
+	Pid  int    `json:"pid"`
+	Tid  int    `json:"tid"`
+}
+
+var prof = &profiler{stats: make(map[string]*spanStat)}
+
+// 开始计时，返回的函数结束计时。用法：defer prof.span("board")()
+func (p *profiler) span(name string) func() {
+	start := time.Now()
+	return func() {
+		p.record(name, start, time.Since(start))
+	}
+}
+
+func (p *profiler) record(name string, start time.Time, d time.Duration) {
+	s, ok := p.stats[name]
+	if !ok {
+		s = &spanStat{avg: d}
+		p.stats[name] = s
+		p.order = append(p.order, name)
+	}
+	s.last = d
+	s.avg = (s.avg*19 + d) / 20
+
+	if p.tracing {
+		p.events = append(p.events, traceEvent{
+			Name: name,
+			Ph:   "X",
+			Ts:   start.Sub(p.start).Microseconds(),
+			Dur:  d.Microseconds(),
+			Pid:  1,
+			Tid:  1,
+		})
+		if len(p.events) >= maxTraceEvents {
+			p.stopTrace()
+		}
+	}
+}
+
+// 调试叠加层中显示的各部分平均耗时
+func (p *profiler) summary() string {
+	var b strings.Builder
+	for _, name := range p.order {
+		fmt.Fprintf(&b, "\n%-9s %6.2fms", name, float64(p.stats[name].avg.Microseconds())/1000)
+	}
+	if p.tracing {
+		fmt.Fprintf(&b, "\nREC %d", len(p.events))
+	}
+	return b.String()
+}
+
+// 开始或停止录制，停止时把记录写入 store 中的追踪文件
+func (p *profiler) toggleTrace(store storage.Storage) {
+	if p.tracing {
+		p.stopTrace()
+		return
+	}
+	p.tracing = true
+	p.start = time.Now()
+	p.events = nil
+	p.store = store
+}
+
+func (p *profiler) stopTrace() {
+	p.tracing = false
+	name := "trace-" + time.Now().Format("20060102-150405") + ".json"
+	data, err := json.Marshal(map[string]any{"traceEvents": p.events})
+	p.events = nil
+	if err == nil {
+		err = p.store.Write(name, data)
+	}
+	if err != nil {
+		slog.Warn("保存追踪文件失败", "err", err)
+		return
+	}
+	showToast(tr("已保存") + " " + name)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+// 谜题文件中的格子
+const (
+	puzzleMine    = '*'
+	puzzleSafe    = '.'
+	puzzleMissing = '-' // 不规则形状以外的格子
+	maxPuzzleSize = 100
+)
+
+// 谜题：手工布置的棋盘，在编辑器中制作，拖放到窗口中或通过 --puzzle 打开。
+// 地雷位置固定，开局时自动翻开起点
+type puzzle struct {
+	Name     string   `json:"name,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Topology string   `json:"topology,omitempty"`
+	Rows     []string `json:"rows"` // 每个格子一个字符，见 puzzleMine 等
+	StartX   int      `json:"start_x"`
+	StartY   int      `json:"start_y"`
+}
+
+func parsePuzzle(data []byte) (*puzzle, error) {
+	var p puzzle
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("解析谜题失败: %v", err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *puzzle) validate() error {
+	if len(p.Rows) == 0 || len(p.Rows) > maxPuzzleSize {
+		return fmt.Errorf("无效的谜题高度: %d", len(p.Rows))
+	}
+	w := len(p.Rows[0])
+	if w == 0 || w > maxPuzzleSize {
+		return fmt.Errorf("无效的谜题宽度: %d", w)
+	}
+	for y, row := range p.Rows {
+		if len(row) != w {
+			return fmt.Errorf("第 %d 行的宽度不一致", y+1)
+		}
+		if i := strings.IndexFunc(row, func(r rune) bool {
+			return r != puzzleMine && r != puzzleSafe && r != puzzleMissing
+		}); i >= 0 {
+			return fmt.Errorf("第 %d 行有无效的字符 %q", y+1, row[i])
+		}
+	}
+	t, ok := squareTopology(p.Topology)
+	if !ok {
+		return fmt.Errorf("未知棋盘类型: %s", p.Topology)
+	}
+	// 环形棋盘小于 3 格时同一个格子会从两侧重复相邻
+	if _, torus := t.(board.Torus); torus && (w < 3 || len(p.Rows) < 3) {
+		return fmt.Errorf("环形谜题至少需要 3x3: %dx%d", w, len(p.Rows))
+	}
+	if p.StartX < 0 || p.StartX >= w || p.StartY < 0 || p.StartY >= len(p.Rows) || p.Rows[p.StartY][p.StartX] != puzzleSafe {
+		return fmt.Errorf("无效的起点: %d, %d", p.StartX, p.StartY)
+	}
+	if p.mineCount() == 0 {
+		return fmt.Errorf("谜题中没有地雷")
+	}
+	return nil
+}
+
+func (p *puzzle) size() (w, h int) {
+	return len(p.Rows[0]), len(p.Rows)
+}
+
+func (p *puzzle) topology() board.Topology {
+	t, _ := squareTopology(p.Topology)
+	return t
+}
+
+func (p *puzzle) mines() [][]bool {
+	mines := make([][]bool, len(p.Rows))
+	for y, row := range p.Rows {
+		mines[y] = make([]bool, len(row))
+		for x := range row {
+			mines[y][x] = row[x] == puzzleMine
+		}
+	}
+	return mines
+}
+
+func (p *puzzle) mineCount() int {
+	n := 0
+	for _, row := range p.Rows {
+		n += strings.Count(row, string(puzzleMine))
+	}
+	return n
+}
+
+// 谜题的轮廓，所有格子都存在时为 nil
+func (p *puzzle) mask() *board.Mask {
+	missing := false
+	for _, row := range p.Rows {
+		missing = missing || strings.ContainsRune(row, puzzleMissing)
+	}
+	if !missing {
+		return nil
+	}
+	w, h := p.size()
+	return board.NewMask("puzzle", w, h, func(x, y int) bool {
+		return p.Rows[y][x] != puzzleMissing
+	})
+}
+
+func (p *puzzle) title() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return tr("谜题")
+}
+
+func loadPuzzle(path string) (*puzzle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取谜题失败: %v", err)
+	}
+	if isMBF(path) {
+		return parseMBFPuzzle(data, mbfTitle(path))
+	}
+	return parsePuzzle(data)
+}
+
+// 保存到档案存储的 puzzles 目录，返回文件名
+func savePuzzle(store storage.Storage, p *puzzle) (string, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化失败: %v", err)
+	}
+	name := "puzzles/puzzle-" + time.Now().Format("20060102-150405") + ".json"
+	if err := store.Write(name, data); err != nil {
+		return "", fmt.Errorf("保存谜题失败: %v", err)
+	}
+	return name, nil
+}
+
+// 开始谜题对局。棋盘大小由谜题决定，使用自定义难度，地雷已经放好，起点自动翻开
+func (g *Game) beginPuzzle(p *puzzle) error {
+	w, h := p.size()
+	if err := g.newVariantRoundSized(Custom, DifficultyConfig{w, h, p.mineCount()}, p.topology(), ""); err != nil {
+		return err
+	}
+	g.shape = p.mask()
+	g.puzzle = p
+
+	g.setMines(p.mines(), p.StartX, p.StartY)
+	g.revealCell(p.StartX, p.StartY)
+	showToast(p.title())
+	return nil
+}
+
+// 拖放到窗口中的谜题文件或棋盘状态文件直接打开，对局进行中时先询问是否放弃
+func (g *Game) openDroppedPuzzle() error {
+	files := ebiten.DroppedFiles()
+	if files == nil {
+		return nil
+	}
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	data, err := fs.ReadFile(files, entries[0].Name())
+	if err != nil {
+		slog.Warn("读取拖放的文件失败", "err", err)
+		return nil
+	}
+	if name := entries[0].Name(); isMBF(name) {
+		p, err := parseMBFPuzzle(data, mbfTitle(name))
+		if err != nil {
+			slog.Warn("打开 MBF 文件失败", "err", err)
+			showToast(tr("无法打开谜题"))
+			return nil
+		}
+		return g.confirmAbandon(func() error { return g.beginPuzzle(p) })
+	}
+	// 带版本号的是棋盘状态文件，见 board.State
+	var probe struct {
+		Version int `json:"version"`
+	}
+	if json.Unmarshal(data, &probe) == nil && probe.Version > 0 {
+		failed := func(err error) {
+			slog.Warn("打开棋盘文件失败", "err", err)
+			showToast(tr("无法打开棋盘"))
+		}
+		s, err := board.LoadState(data)
+		if err != nil {
+			failed(err)
+			return nil
+		}
+		return g.confirmAbandon(func() error {
+			if err := g.loadBoardState(s); err != nil {
+				failed(err)
+			}
+			return nil
+		})
+	}
+	p, err := parsePuzzle(data)
+	if err != nil {
+		slog.Warn("打开谜题失败", "err", err)
+		showToast(tr("无法打开谜题"))
+		return nil
+	}
+	return g.confirmAbandon(func() error { return g.beginPuzzle(p) })
+}
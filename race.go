@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"minesweeper/netplay"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// 局域网对战中的状态
+type raceState struct {
+	conn             *netplay.Conn
+	opponentProgress int
+	sentProgress     int
+	finishSent       bool
+	outcome          string // 对战结果，为空表示仍在进行
+}
+
+// 对战大厅：创建房间或输入地址加入
+type raceLobby struct {
+	host    *netplay.Host
+	dialing chan dialResult
+	conn    *netplay.Conn   // 已连接、等待主机下发棋盘的连接
+	peers   []*netplay.Conn // 主机：合作模式下已加入、等待开始的玩家
+	addr    string
+	status  string
+	mode    string // 主机选择的模式，见 netplay.ModeRace 等
+}
+
+// 实验性的拆弹模式放在末尾
+var lobbyModes = []string{netplay.ModeRace, netplay.ModeCoop, netplay.ModeDefuse}
+
+var modeTitles = map[string]string{
+	netplay.ModeRace:   "竞速",
+	netplay.ModeCoop:   "合作",
+	netplay.ModeDefuse: "拆弹",
+}
+
+type dialResult struct {
+	conn *netplay.Conn
+	err  error
+}
+
+func (g *Game) raceButtons() []*menuButton {
+	if g.lobby == nil {
+		g.lobby = &raceLobby{addr: globalConfig.LastRaceAddr}
+	}
+
+	buttons := []*menuButton{
+		{Button: &Button{Text: "创建房间"}, action: func() error {
+			if len(g.lobby.peers) > 0 {
+				return g.beginCoopHost()
+			}
+			g.hostRace()
+			return nil
+		}},
+		{Button: &Button{Text: "加入房间"}, action: func() error {
+			g.joinRace()
+			return nil
+		}},
+		{Button: &Button{Text: tr("模式") + ": " + tr(modeTitles[g.lobby.mode]), Disabled: g.lobby.host != nil}, action: func() error {
+			modes := lobbyModes
+			if !experimentEnabled(expDefuseMode) {
+				modes = modes[:2]
+			}
+			next := 0
+			for i, mode := range modes {
+				if mode == g.lobby.mode {
+					next = (i + 1) % len(modes)
+				}
+			}
+			g.lobby.mode = modes[next]
+			g.switchScene(SceneRace)
+			return nil
+		}},
+		{Button: &Button{Text: "返回"}, action: func() error {
+			g.closeLobby()
+			g.switchScene(SceneMainMenu)
+			return nil
+		}},
+	}
+	if len(g.lobby.peers) > 0 {
+		buttons[0].Text = "开始游戏"
+	}
+	g.layoutMenuButtons(buttons, 100)
+	// 地址输入框位于两个按钮之间
+	for _, btn := range buttons[1:] {
+		btn.Y += 50
+	}
+	return buttons
+}
+
+func (g *Game) hostRace() {
+	if g.lobby.host != nil || g.lobby.conn != nil {
+		return
+	}
+	host, err := netplay.Listen(netplay.DefaultPort)
+	if err != nil {
+		g.lobby.status = err.Error()
+		return
+	}
+	g.lobby.host = host
+	g.lobby.status = tr("等待对手加入") + " " + strings.Join(netplay.LocalAddresses(), " / ")
+	// 刷新按钮状态，开房后不能再切换模式
+	g.switchScene(SceneRace)
+}
+
+func (g *Game) joinRace() {
+	if g.lobby.dialing != nil || g.lobby.conn != nil || g.lobby.addr == "" {
+		return
+	}
+	globalConfig.LastRaceAddr = g.lobby.addr
+	if err := saveConfig(globalConfig); err != nil {
+		slog.Warn("保存配置失败", "err", err)
+	}
+
+	g.lobby.status = tr("正在连接...")
+	result := make(chan dialResult, 1)
+	g.lobby.dialing = result
+	addr := g.lobby.addr
+	go func() {
+		conn, err := netplay.Dial(addr)
+		result <- dialResult{conn, err}
+	}()
+}
+
+func (g *Game) closeLobby() {
+	if g.lobby == nil {
+		return
+	}
+	if g.lobby.host != nil {
+		g.lobby.host.Close()
+	}
+	if g.lobby.conn != nil {
+		g.lobby.conn.Close()
+	}
+	for _, conn := range g.lobby.peers {
+		conn.Close()
+	}
+	g.lobby = nil
+}
+
+// 大厅的输入和网络状态处理
+func (g *Game) updateLobby() error {
+	l := g.lobby
+	if l == nil {
+		return nil
+	}
+
+	// 地址输入
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(l.addr) < 40 && (r == '.' || r == ':' || r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			l.addr += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(l.addr) > 0 {
+		l.addr = l.addr[:len(l.addr)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		g.joinRace()
+	}
+
+	// 主机：对手连接后下发棋盘参数，合作模式则等待主机开始
+	if l.host != nil {
+		if conn := l.host.Accepted(); conn != nil {
+			if l.mode != netplay.ModeCoop {
+				// 与合作模式相同，主机是 0 号玩家，唯一的对手是 1 号
+				start := g.startMessage(l.mode)
+				m := start
+				m.Player = 1
+				conn.Send(m)
+				return g.beginRace(conn, start, true)
+			}
+			l.peers = append(l.peers, conn)
+			g.switchScene(SceneRace)
+		}
+		if len(l.peers) > 0 {
+			joined := l.peers[:0]
+			for _, conn := range l.peers {
+				if !conn.Closed() {
+					joined = append(joined, conn)
+				}
+			}
+			if len(joined) != len(l.peers) {
+				l.peers = joined
+				g.switchScene(SceneRace)
+			}
+			l.status = fmt.Sprintf(tr("已有 %d 名玩家加入"), len(l.peers))
+		}
+	}
+
+	// 加入方：连接完成后等待主机下发棋盘
+	if l.dialing != nil {
+		select {
+		case res := <-l.dialing:
+			l.dialing = nil
+			if res.err != nil {
+				l.status = res.err.Error()
+			} else {
+				l.conn = res.conn
+				l.status = tr("已连接，等待主机开始")
+			}
+		default:
+		}
+	}
+	if l.conn != nil {
+		if m, ok := l.conn.Poll(); ok && m.Type == netplay.TypeStart {
+			conn := l.conn
+			l.conn = nil
+			return g.beginRace(conn, m, false)
+		}
+		if l.conn.Closed() {
+			l.status = tr("连接已断开")
+			l.conn = nil
+		}
+	}
+	return nil
+}
+
+// 主机生成的开局参数：当前难度、新的种子，起始格子位于中央
+func (g *Game) startMessage(mode string) netplay.Message {
+	config := g.size
+	return netplay.Message{
+		Type:       netplay.TypeStart,
+		Mode:       mode,
+		Difficulty: difficultyKeys[g.difficulty],
+		Seed:       g.rng.Int64(),
+		StartX:     config.GridWidth / 2,
+		StartY:     config.GridHeight / 2,
+	}
+}
+
+// 所有人使用相同的种子和起始格子开局，保证棋盘完全一致
+func (g *Game) startBoard(m netplay.Message) error {
+	g.closeLobby()
+	// 对方已经开局，无法再询问，进行中的对局直接记为放弃
+	if g.inProgress() {
+		g.publish(EventGameAbandoned)
+	}
+	difficulty, _ := difficultyByKey(m.Difficulty)
+	if err := g.newRound(difficulty); err != nil {
+		return err
+	}
+	g.seed = m.Seed
+	g.layoutMines(m.StartX, m.StartY)
+	return nil
+}
+
+// 与单个对手开局：竞速、拆弹，或作为加入方参与合作
+func (g *Game) beginRace(conn *netplay.Conn, m netplay.Message, host bool) error {
+	if err := g.startBoard(m); err != nil {
+		conn.Close()
+		return err
+	}
+	switch {
+	case m.Mode == netplay.ModeRace:
+		g.race = &raceState{conn: conn}
+	case host:
+		g.coop = newCoopHost(g, m.Mode, []*netplay.Conn{conn})
+	default:
+		g.coop = newCoopClient(g, m.Mode, conn, m.Player)
+	}
+	return nil
+}
+
+func (g *Game) endRace() {
+	if g.race != nil {
+		g.race.conn.Close()
+		g.race = nil
+	}
+}
+
+// 已翻开的安全格子占比
+func (g *Game) progress() int {
+	safe := g.cellCount() - g.mineCount()
+	if safe == 0 {
+		return 0
+	}
+	return g.revealedSafe * 100 / safe
+}
+
+// 对战是否已分出胜负，之后不再接受操作
+func (g *Game) raceDecided() bool {
+	return g.race != nil && g.race.outcome != ""
+}
+
+func (g *Game) updateRace() {
+	r := g.race
+	if r == nil {
+		return
+	}
+
+	for {
+		m, ok := r.conn.Poll()
+		if !ok {
+			break
+		}
+		switch m.Type {
+		case netplay.TypeProgress:
+			r.opponentProgress = m.Progress
+		case netplay.TypeFinish:
+			if m.Won {
+				r.opponentProgress = 100
+			}
+			if r.outcome == "" {
+				if m.Won {
+					r.outcome = fmt.Sprintf(tr("对手先完成 (%s)"), formatDuration(m.Elapsed))
+				} else {
+					r.outcome = tr("对手踩雷，你赢了")
+				}
+			}
+		}
+	}
+
+	if r.outcome == "" && r.conn.Closed() {
+		r.outcome = tr("对手已断开")
+	}
+}
+
+// 翻开格子后把进度发给对手，对局结束时通知对手并决定胜负
+func (g *Game) subscribeRace(bus *EventBus) {
+	bus.Subscribe(EventCellRevealed, func(Event) {
+		r := g.race
+		if r == nil {
+			return
+		}
+		if p := g.progress(); p != r.sentProgress {
+			r.sentProgress = p
+			r.conn.Send(netplay.Message{Type: netplay.TypeProgress, Progress: p})
+		}
+	})
+	finish := func(Event) {
+		r := g.race
+		if r == nil || r.finishSent {
+			return
+		}
+		r.finishSent = true
+		r.conn.Send(netplay.Message{Type: netplay.TypeFinish, Won: g.won, Elapsed: g.elapsedTime})
+		if r.outcome == "" {
+			if g.won {
+				r.outcome = tr("你先完成了！")
+			} else {
+				r.outcome = tr("你踩雷了，对手获胜")
+			}
+		}
+	}
+	bus.Subscribe(EventGameWon, finish)
+	bus.Subscribe(EventGameLost, finish)
+}
+
+func (g *Game) drawLobby(screen *ebiten.Image) {
+	l := g.lobby
+	if l == nil {
+		return
+	}
+	for i, line := range wrapText(g.gameFont, l.status, g.screenWidth()-20) {
+		text.Draw(screen, line, g.gameFont, 10, 70+i*18, activeTheme.Text)
+	}
+
+	join := g.menuButtons[1]
+	cursor := ""
+	if time.Now().UnixMilli()/500%2 == 0 {
+		cursor = "_"
+	}
+	input := &Button{X: join.X, Y: join.Y - 44, W: join.W, H: 34, Text: l.addr + cursor}
+	text.Draw(screen, tr("对方地址")+":", g.gameFont, input.X, input.Y-6, activeTheme.Text)
+	g.drawButton(screen, input)
+}
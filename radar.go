@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/solver"
+)
+
+// 雷达辅助：按住雷达键（默认空格，可在操作界面重新绑定）一小段时间后，用淡色标出当前可以推理确定安全的格子，
+// 显示两秒后消失并进入较长的冷却。只提示位置、不代为翻开，
+// 给需要降低推理负担的玩家一点帮助而不替他们玩
+const (
+	radarHold     = 400 * time.Millisecond // 需要按住的时间，避免误触
+	radarDuration = 2 * time.Second
+	radarCooldown = 30 * time.Second
+)
+
+// 冷却全局保存，重新开局不会重置
+var radar struct {
+	pressed  time.Time // 开始按住的时间，零值表示没有按住
+	fired    bool      // 本次按住已经触发过
+	until    time.Time // 标记显示到这个时间
+	cooldown time.Time // 冷却结束的时间
+	cells    []solver.Point
+	grid     [][]Cell // 标记所属的棋盘，换局后不再显示
+}
+
+// 与概率提示相同，对战、挑战和街机模式中不可用，用过雷达的成绩单独记录
+func (g *Game) radarAllowed() bool {
+	return globalConfig.Radar && !g.firstClick && g.probabilityAllowed()
+}
+
+// 按住雷达键触发雷达，冷却中只提示剩余时间
+func (g *Game) updateRadar() {
+	if !g.radarAllowed() {
+		return
+	}
+	b, _ := actionBinding("radar")
+	if !b.pressed() {
+		radar.pressed, radar.fired = time.Time{}, false
+		return
+	}
+	if b.justPressed() {
+		radar.pressed = time.Now()
+	}
+	if radar.fired || radar.pressed.IsZero() || time.Since(radar.pressed) < radarHold {
+		return
+	}
+	radar.fired = true
+	g.fireRadar()
+}
+
+// 标出可以确定安全的格子，也可以点击状态栏的提示按钮触发
+func (g *Game) fireRadar() {
+	if left := time.Until(radar.cooldown); left > 0 {
+		showToast(fmt.Sprintf("%s %ds", tr("雷达冷却中"), int(math.Ceil(left.Seconds()))))
+		return
+	}
+	radar.cells = solver.Deduce(g.visibleView()).Safe
+	radar.grid = g.grid
+	radar.until = time.Now().Add(radarDuration)
+	radar.cooldown = time.Now().Add(radarCooldown)
+	if len(radar.cells) == 0 {
+		showToast(tr("没有可确定的安全格子"))
+		return
+	}
+	g.assisted = true
+}
+
+// 玩家当前能看到的棋盘，插旗的格子仍视为未知
+func (g *Game) visibleView() *solver.View {
+	view := solver.NewView(g.gridWidth, g.gridHeight)
+	view.Topology = g.adjacency()
+	for y, row := range g.grid {
+		for x, cell := range row {
+			if cell.revealed && !cell.hasMine {
+				view.Set(x, y, cell.neighbors)
+			}
+		}
+	}
+	return view
+}
+
+// 淡色标记，最后半秒逐渐消失
+func (g *Game) drawRadar(screen *ebiten.Image) {
+	left := time.Until(radar.until)
+	if left <= 0 || g.gameOver || g.won || len(radar.grid) == 0 || &radar.grid[0] != &g.grid[0] {
+		return
+	}
+	alpha := 70.0
+	if left < 500*time.Millisecond {
+		alpha *= left.Seconds() / 0.5
+	}
+	clr := color.RGBA{0, uint8(alpha * 0.6), uint8(alpha), uint8(alpha)}
+	for _, p := range radar.cells {
+		if g.grid[p.Y][p.X].revealed || g.grid[p.Y][p.X].flagged {
+			continue
+		}
+		px, py := g.cellScreenPos(p.X, p.Y)
+		vector.DrawFilledRect(screen, px+2, py+2, cellSize-4, cellSize-4, clr, false)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"minesweeper/board"
+	"minesweeper/solver"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type moveKind int
+
+const (
+	moveReveal moveKind = iota
+	moveFlag
+)
+
+// 玩家的一次操作，at 为相对开局的时间
+type move struct {
+	kind moveKind
+	x, y int
+	at   time.Duration
+}
+
+func (g *Game) recordMove(kind moveKind, x, y int) {
+	g.moves = append(g.moves, move{kind: kind, x: x, y: y, at: time.Since(g.startTime)})
+}
+
+// 翻开和标记都记入操作记录，用于复盘、重放和导出 GIF
+func (g *Game) subscribeRecorder(bus *EventBus) {
+	bus.Subscribe(EventCellRevealed, func(e Event) { g.recordMove(moveReveal, e.X, e.Y) })
+	bus.Subscribe(EventFlagToggled, func(e Event) { g.recordMove(moveFlag, e.X, e.Y) })
+}
+
+// 对局复盘结果
+type gameAnalysis struct {
+	moves     int
+	duration  time.Duration
+	longest   time.Duration // 最长的一次思考时间
+	longestAt int           // 对应第几步，从 1 开始
+	guesses   int           // 没有可推理的安全格时被迫猜测的次数
+	mistakes  []solver.Point
+	heat      [][]int // 每个格子被点击的次数
+	maxHeat   int
+}
+
+// 按顺序重放操作记录，在每次翻开前用求解器判断是否存在可以确定安全的格子
+func analyzeGame(grid [][]Cell, moves []move, topology board.Topology) *gameAnalysis {
+	height, width := len(grid), len(grid[0])
+	a := &gameAnalysis{moves: len(moves), heat: make([][]int, height)}
+	for y := range a.heat {
+		a.heat[y] = make([]int, width)
+	}
+
+	view := solver.NewView(width, height)
+	view.Topology = topology
+	var last time.Duration
+	for i, m := range moves {
+		if think := m.at - last; think > a.longest {
+			a.longest, a.longestAt = think, i+1
+		}
+		last = m.at
+		a.duration = m.at
+
+		a.heat[m.y][m.x]++
+		if a.heat[m.y][m.x] > a.maxHeat {
+			a.maxHeat = a.heat[m.y][m.x]
+		}
+
+		if m.kind != moveReveal || view.At(m.x, m.y) != solver.Unknown {
+			continue
+		}
+
+		// 第一次点击总是猜测，不计入
+		if i > 0 {
+			result := solver.Deduce(view)
+			safe := false
+			for _, p := range result.Safe {
+				if p.X == m.x && p.Y == m.y {
+					safe = true
+					break
+				}
+			}
+			if !safe {
+				if len(result.Safe) > 0 {
+					a.mistakes = append(a.mistakes, solver.Point{X: m.x, Y: m.y})
+				} else {
+					a.guesses++
+				}
+			}
+		}
+
+		if grid[m.y][m.x].hasMine {
+			break
+		}
+		replayReveal(grid, view, m.x, m.y)
+	}
+	return a
+}
+
+// 在可见棋盘上重现翻开操作，包括空白格子的连锁展开
+func replayReveal(grid [][]Cell, view *solver.View, x, y int) {
+	board.FloodFillOn(view.Topology, len(grid[0]), len(grid), x, y, func(x, y int) bool {
+		if view.At(x, y) != solver.Unknown {
+			return false
+		}
+		n := grid[y][x].neighbors
+		view.Set(x, y, n)
+		return n == 0
+	})
+}
+
+func (g *Game) toggleReview() {
+	g.showingReview = !g.showingReview
+	if g.showingReview && g.review == nil {
+		g.review = analyzeGame(g.grid, g.moves, g.adjacency())
+	}
+}
+
+// 绘制点击热力图、失误位置和统计文字
+func (g *Game) drawReview(screen *ebiten.Image) {
+	a := g.review
+	view := g.boardView(screen)
+	for y, row := range a.heat {
+		for x, n := range row {
+			if n == 0 {
+				continue
+			}
+			alpha := uint8(60 + 160*n/a.maxHeat)
+			px, py := g.cellScreenPos(x, y)
+			vector.DrawFilledRect(view, px, py, cellSize, cellSize, color.RGBA{alpha, alpha / 4, 0, alpha}, false)
+		}
+	}
+	for _, p := range a.mistakes {
+		px, py := g.cellScreenPos(p.X, p.Y)
+		vector.StrokeRect(view, px+1, py+1, cellSize-2, cellSize-2, 2, color.RGBA{255, 230, 0, 255}, false)
+	}
+
+	avg := time.Duration(0)
+	if a.moves > 0 {
+		avg = a.duration / time.Duration(a.moves)
+	}
+	lines := []string{
+		fmt.Sprintf("%s: %d", tr("步数"), a.moves),
+		fmt.Sprintf("%s: %.1fs", tr("平均每步"), avg.Seconds()),
+		fmt.Sprintf("%s: %.1fs (#%d)", tr("最长思考"), a.longest.Seconds(), a.longestAt),
+		fmt.Sprintf("%s: %d", tr("猜测"), a.guesses),
+		fmt.Sprintf("%s: %d", tr("失误"), len(a.mistakes)),
+	}
+
+	lineHeight := 20
+	boxHeight := len(lines)*lineHeight + 12
+	vector.DrawFilledRect(screen, 8, 8, float32(g.screenWidth()-16), float32(boxHeight), activeTheme.Overlay, false)
+	for i, line := range lines {
+		text.Draw(screen, line, g.gameFont, 16, 8+(i+1)*lineHeight, activeTheme.Text)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"minesweeper/storage"
+)
+
+// 对手成绩的刷新间隔
+const rivalRefresh = 5 * time.Minute
+
+// 各难度的最佳用时，键为 difficultyKeys 中的名称
+type rivalBests map[string]time.Duration
+
+// 上次获取到的对手成绩，保存在磁盘上以便下次启动时发现新纪录
+type RivalCache struct {
+	Bests map[string]rivalBests `json:"bests"`
+
+	name      string
+	store     storage.Storage
+	pending   chan map[string]rivalBests
+	checkedAt time.Time
+}
+
+var globalRivals = &RivalCache{Bests: make(map[string]rivalBests)}
+
+func loadRivalCache(store storage.Storage) (*RivalCache, error) {
+	c := &RivalCache{Bests: make(map[string]rivalBests)}
+
+	c.name = "rivals.json"
+	c.store = store
+
+	err := loadFile(c.store, c.name, "rivals", c)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("读取对手成绩失败: %v", err)
+	}
+	if c.Bests == nil {
+		c.Bests = make(map[string]rivalBests)
+	}
+	return c, nil
+}
+
+func (c *RivalCache) save() error {
+	if c.name == "" {
+		return nil
+	}
+	return saveFile(c.store, c.name, "rivals", c)
+}
+
+type leaderboardEntry struct {
+	Name string        `json:"name"`
+	Time time.Duration `json:"time"`
+}
+
+var leaderboardClient = &http.Client{Timeout: 5 * time.Second}
+
+func getJSON(u string, v any) error {
+	resp, err := leaderboardClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("服务器返回 %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("解析应答失败: %v", err)
+	}
+	return nil
+}
+
+func fetchLeaderboard(server, difficulty string) ([]leaderboardEntry, error) {
+	var entries []leaderboardEntry
+	err := getJSON(strings.TrimRight(server, "/")+"/api/leaderboard/"+url.PathEscape(difficulty), &entries)
+	return entries, err
+}
+
+func fetchPlayerBests(server, name string) (rivalBests, error) {
+	var resp struct {
+		Bests rivalBests `json:"bests"`
+	}
+	err := getJSON(strings.TrimRight(server, "/")+"/api/players/"+url.PathEscape(name), &resp)
+	return resp.Bests, err
+}
+
+func isRival(name string) bool {
+	for _, r := range globalConfig.Rivals {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func toggleRival(name string) {
+	rivals := globalConfig.Rivals[:0:0]
+	for _, r := range globalConfig.Rivals {
+		if r != name {
+			rivals = append(rivals, r)
+		}
+	}
+	if len(rivals) == len(globalConfig.Rivals) {
+		rivals = append(rivals, name)
+	}
+	globalConfig.Rivals = rivals
+	if err := saveConfig(globalConfig); err != nil {
+		slog.Warn("保存配置失败", "err", err)
+	}
+	// 立即获取新对手的成绩
+	globalRivals.checkedAt = time.Time{}
+}
+
+// 定期在后台获取对手成绩，对手刷新了比我更好的纪录时提示
+func (g *Game) updateRivals() {
+	c := globalRivals
+	server := globalConfig.ChallengeServer
+	if c.pending != nil {
+		select {
+		case bests := <-c.pending:
+			c.pending = nil
+			c.merge(bests)
+		default:
+		}
+		return
+	}
+	if server == "" || len(globalConfig.Rivals) == 0 || time.Since(c.checkedAt) < rivalRefresh {
+		return
+	}
+
+	c.checkedAt = time.Now()
+	names := append([]string(nil), globalConfig.Rivals...)
+	result := make(chan map[string]rivalBests, 1)
+	c.pending = result
+	go func() {
+		bests := make(map[string]rivalBests)
+		for _, name := range names {
+			b, err := fetchPlayerBests(server, name)
+			if err != nil {
+				slog.Warn("获取对手成绩失败", "name", name, "err", err)
+				continue
+			}
+			bests[name] = b
+		}
+		result <- bests
+	}()
+}
+
+func (c *RivalCache) merge(bests map[string]rivalBests) {
+	for name, b := range bests {
+		old := c.Bests[name]
+		for key, t := range b {
+			d, ok := difficultyByKey(key)
+			if !ok || t <= 0 {
+				continue
+			}
+			mine := globalStats.forDifficulty(d).BestTime
+			// 只有已知旧成绩时才提示，刚添加的对手不算“刷新”
+			if prev, known := old[key]; old != nil && (!known || t < prev) && mine > 0 && t < mine {
+				showToast(fmt.Sprintf(tr("%s 以 %s 超过了你的%s纪录"), name, formatDuration(t), tr(difficultyNames[d])))
+			}
+		}
+		c.Bests[name] = b
+	}
+	if err := c.save(); err != nil {
+		slog.Warn("保存对手成绩失败", "err", err)
+	}
+}
+
+// 对手界面：选定难度下每个对手的最佳用时与我的对比
+type rivalsView struct {
+	difficulty  Difficulty
+	leaderboard bool // 显示排行榜以便标记对手
+	entries     []leaderboardEntry
+	loading     chan leaderboardResult
+	status      string
+}
+
+type leaderboardResult struct {
+	entries []leaderboardEntry
+	err     error
+}
+
+func (g *Game) rivalButtons() []*menuButton {
+	if g.rivals == nil {
+		g.rivals = &rivalsView{difficulty: g.difficulty}
+	}
+	v := g.rivals
+
+	var buttons []*menuButton
+	if v.leaderboard {
+		for i, e := range v.entries {
+			y := 90 + i*28
+			if y > g.screenHeight()-80 {
+				break
+			}
+			star := "☆"
+			if isRival(e.Name) {
+				star = "★"
+			}
+			name := e.Name
+			buttons = append(buttons, &menuButton{Button: &Button{X: 8, Y: y - 18, W: 28, H: 24, Text: star}, action: func() error {
+				toggleRival(name)
+				g.switchScene(SceneRivals)
+				return nil
+			}})
+		}
+	}
+
+	half := (g.screenWidth() - 30) / 2
+	view := "排行榜"
+	if v.leaderboard {
+		view = "对手"
+	}
+	bottom := g.screenHeight() - 44
+	back := &menuButton{Button: &Button{Text: "返回"}, action: func() error {
+		g.rivals = nil
+		g.switchScene(SceneStats)
+		return nil
+	}}
+	back.X, back.Y, back.W, back.H = 20+half, bottom, half, 34
+	return append(buttons,
+		&menuButton{Button: &Button{X: 10, Y: 40, W: g.screenWidth() - 20, H: 28, Text: tr(difficultyNames[v.difficulty])}, action: func() error {
+			v.difficulty = (v.difficulty + 1) % Difficulty(len(difficultySettings))
+			g.loadLeaderboard()
+			return nil
+		}},
+		&menuButton{Button: &Button{X: 10, Y: bottom, W: half, H: 34, Text: view}, action: func() error {
+			v.leaderboard = !v.leaderboard
+			g.loadLeaderboard()
+			return nil
+		}},
+		back,
+	)
+}
+
+// 切换难度或视图后重新获取排行榜
+func (g *Game) loadLeaderboard() {
+	v := g.rivals
+	v.entries = nil
+	v.status = ""
+	if v.leaderboard {
+		server := globalConfig.ChallengeServer
+		if server == "" {
+			v.status = tr("未配置排行榜服务器")
+		} else {
+			v.status = tr("正在获取排行榜...")
+			result := make(chan leaderboardResult, 1)
+			v.loading = result
+			key := difficultyKeys[v.difficulty]
+			go func() {
+				entries, err := fetchLeaderboard(server, key)
+				result <- leaderboardResult{entries, err}
+			}()
+		}
+	}
+	g.switchScene(SceneRivals)
+}
+
+func (g *Game) updateRivalsView() {
+	v := g.rivals
+	if v == nil || v.loading == nil {
+		return
+	}
+	select {
+	case res := <-v.loading:
+		v.loading = nil
+		v.status = ""
+		if res.err != nil {
+			v.status = res.err.Error()
+		}
+		v.entries = res.entries
+		g.switchScene(SceneRivals)
+	default:
+	}
+}
+
+func (g *Game) drawRivals(screen *ebiten.Image) {
+	v := g.rivals
+	if v == nil {
+		return
+	}
+	if v.status != "" {
+		for i, line := range wrapText(g.gameFont, v.status, g.screenWidth()-20) {
+			text.Draw(screen, line, g.gameFont, 10, 90+i*18, activeTheme.Text)
+		}
+		return
+	}
+
+	key := difficultyKeys[v.difficulty]
+	if v.leaderboard {
+		for i, e := range v.entries {
+			y := 90 + i*28
+			if y > g.screenHeight()-80 {
+				break
+			}
+			text.Draw(screen, fmt.Sprintf("%d. %s", i+1, e.Name), g.gameFont, 42, y, activeTheme.Text)
+			text.Draw(screen, formatDuration(e.Time), g.gameFont, g.screenWidth()-60, y, activeTheme.Text)
+		}
+		return
+	}
+
+	mine := globalStats.forDifficulty(v.difficulty).BestTime
+	text.Draw(screen, fmt.Sprintf("%s: %s", tr("我"), bestString(mine)), g.gameFont, 10, 94, activeTheme.Text)
+	if len(globalConfig.Rivals) == 0 {
+		text.Draw(screen, tr("在排行榜中点击 ☆ 添加对手"), g.gameFont, 10, 122, activeTheme.Text)
+		return
+	}
+	for i, name := range globalConfig.Rivals {
+		theirs := globalRivals.Bests[name][key]
+		line := fmt.Sprintf("%s: %s", name, bestString(theirs))
+		if theirs > 0 && mine > 0 {
+			diff := mine - theirs
+			sign := "+"
+			if diff < 0 {
+				sign, diff = "-", -diff
+			}
+			line += fmt.Sprintf(" (%s%.1fs)", sign, diff.Seconds())
+		}
+		text.Draw(screen, line, g.gameFont, 10, 122+i*22, activeTheme.Text)
+	}
+}
+
+func bestString(d time.Duration) string {
+	if d <= 0 {
+		return "--:--"
+	}
+	return formatDuration(d)
+}
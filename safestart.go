@@ -0,0 +1,74 @@
+package main
+
+// 首次点击的安全范围，在设置中选择，关闭“首次点击安全”时不保护首次点击：
+// cell 只保证点中的格子没有地雷，可能翻开一个数字；
+// opening 点中的格子及相邻格子都没有地雷，总会连锁展开一小片；
+// cascade 在 opening 的基础上换种子重新布雷，直到连锁展开超出安全区
+const (
+	safeStartCell    = "cell"
+	safeStartOpening = "opening"
+	safeStartCascade = "cascade"
+)
+
+var safeStartModes = []string{safeStartCell, safeStartOpening, safeStartCascade}
+
+var safeStartTitles = map[string]string{
+	safeStartCell:    "安全格子",
+	safeStartOpening: "安全开局",
+	safeStartCascade: "大开局",
+}
+
+// 大开局最多换多少次种子，密度很高时可能始终无法满足
+const maxCascadeRerolls = 200
+
+// 按设置布雷，(x, y) 为首次点击的位置
+func (g *Game) placeFirstMines(x, y int) {
+	switch {
+	case globalConfig.NoGuess != "":
+		// 无猜模式必须从安全区开始
+		g.initializeGridSafely(x, y)
+	case !globalConfig.SafeFirstClick:
+		g.initializeGridSafely(-1, -1)
+	default:
+		g.safeCell = globalConfig.SafeStart == safeStartCell
+		g.initializeGridSafely(x, y)
+	}
+}
+
+// 是否需要换种子让首次点击展开得更大
+func (g *Game) wantsCascade(x, y int) bool {
+	return globalConfig.SafeFirstClick && globalConfig.SafeStart == safeStartCascade &&
+		globalConfig.NoGuess == "" && x >= 0 && !g.opensWide(x, y)
+}
+
+// 翻开 (x, y) 后连锁展开是否超出它的相邻格子，即至少有一个相邻格子也是空白
+func (g *Game) opensWide(x, y int) bool {
+	for _, p := range g.adjacency().Neighbors(nil, g.gridWidth, g.gridHeight, x, y) {
+		if c := g.grid[p[1]][p[0]]; !c.hasMine && c.neighbors == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func safeStartTitle() string {
+	if !globalConfig.SafeFirstClick {
+		return tr("关")
+	}
+	return tr(safeStartTitles[globalConfig.SafeStart])
+}
+
+// 设置中依次切换 关闭、安全格子、安全开局、大开局
+func nextSafeStart() {
+	if !globalConfig.SafeFirstClick {
+		globalConfig.SafeFirstClick, globalConfig.SafeStart = true, safeStartModes[0]
+		return
+	}
+	for i, mode := range safeStartModes {
+		if mode == globalConfig.SafeStart && i+1 < len(safeStartModes) {
+			globalConfig.SafeStart = safeStartModes[i+1]
+			return
+		}
+	}
+	globalConfig.SafeFirstClick = false
+}
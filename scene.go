@@ -0,0 +1,576 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// 当前显示的界面
+type Scene int
+
+const (
+	SceneMainMenu Scene = iota
+	ScenePlaying
+	SceneStats
+	SceneSettings
+	SceneDuplicate // 检测到同一档案已有实例在运行
+	SceneNews      // 升级后首次启动时的更新内容
+	SceneSeeds     // 种子历史
+	SceneRace      // 局域网对战大厅
+	SceneChallenge // 每日/每周挑战
+	SceneRivals    // 对手成绩对比及排行榜
+	SceneEndless   // 无尽模式
+	SceneRestore   // 询问是否恢复异常退出前的对局
+	SceneHex       // 六边形模式
+	SceneScoreboard
+	SceneRecap  // 退出前的本次小结
+	SceneEditor // 谜题编辑器
+	ScenePuzzles
+	SceneDrill  // 图案练习
+	SceneArcade // 街机模式的排行榜
+	SceneAchievements
+	SceneCosmetics // 主题、旗帜和音效包
+	SceneControls  // 按键绑定
+)
+
+// 菜单界面上的按钮及其动作
+type menuButton struct {
+	*Button
+	action func() error
+}
+
+var (
+	globalConfig = defaultConfig()
+	globalStats  = &Stats{Difficulties: make(map[string]*DifficultyStats)}
+)
+
+// 是否有可以继续的对局
+func (g *Game) inProgress() bool {
+	return !g.firstClick && !g.gameOver && !g.won
+}
+
+func (g *Game) switchScene(scene Scene) {
+	g.scene = scene
+	g.menuButtons = nil
+
+	switch scene {
+	case SceneMainMenu:
+		g.layoutMenuButtons([]*menuButton{
+			{Button: &Button{Text: "新游戏"}, action: func() error {
+				g.switchScene(ScenePlaying)
+				g.showingDifficultyMenu = true
+				return nil
+			}},
+			{Button: &Button{Text: "继续", Disabled: !g.inProgress()}, action: func() error {
+				g.switchScene(ScenePlaying)
+				g.paused = false
+				// 菜单中不计时
+				g.startTime = time.Now().Add(-g.elapsedTime)
+				return nil
+			}},
+			g.commandButton("stats"),
+			g.commandButton("challenge"),
+			g.commandButton("endless"),
+			g.commandButton("race"),
+			g.commandButton("scoreboard"),
+			g.commandButton("history"),
+			g.commandButton("puzzles"),
+			g.commandButton("drills"),
+			g.commandButton("arcade"),
+			g.commandButton("settings"),
+			{Button: &Button{Text: "退出"}, action: g.quit},
+		}, 70)
+	case SceneStats:
+		third := (g.screenWidth() - 40) / 3
+		back := g.backButton()
+		back.X, back.Y, back.W, back.H = 30+2*third, g.screenHeight()-44, third, 34
+		g.menuButtons = []*menuButton{
+			{Button: &Button{X: 10, Y: g.screenHeight() - 44, W: third, H: 34, Text: "对手"}, action: func() error {
+				g.switchScene(SceneRivals)
+				return nil
+			}},
+			{Button: &Button{X: 20 + third, Y: g.screenHeight() - 44, W: third, H: 34, Text: "成就"}, action: func() error {
+				g.switchScene(SceneAchievements)
+				return nil
+			}},
+			back,
+		}
+	case SceneAchievements:
+		back := g.backButton()
+		back.X, back.Y, back.W, back.H = 10, g.screenHeight()-44, g.screenWidth()-20, 34
+		back.action = func() error {
+			g.switchScene(SceneStats)
+			return nil
+		}
+		g.menuButtons = []*menuButton{back}
+	case SceneRivals:
+		g.menuButtons = g.rivalButtons()
+	case SceneSettings:
+		g.layoutMenuButtons(g.settingsButtons(), 56)
+	case SceneSeeds:
+		g.menuButtons = g.seedButtons()
+	case SceneRace:
+		g.menuButtons = g.raceButtons()
+	case SceneChallenge:
+		g.menuButtons = g.challengeButtons()
+	case SceneEndless:
+		// 踩雷后回到菜单再进入时开始新的一局
+		if g.endless == nil || g.endless.over {
+			g.startEndless()
+		}
+		g.menuButtons = []*menuButton{}
+	case SceneScoreboard:
+		g.menuButtons = g.scoreboardButtons()
+	case SceneRecap:
+		g.menuButtons = g.recapButtons()
+	case SceneEditor:
+		g.menuButtons = g.editorButtons()
+	case ScenePuzzles:
+		g.menuButtons = g.puzzleButtons()
+	case SceneDrill:
+		g.menuButtons = g.drillButtons()
+	case SceneArcade:
+		g.menuButtons = g.arcadeButtons()
+	case SceneCosmetics:
+		g.layoutMenuButtons(g.cosmeticsButtons(), 56)
+	case SceneControls:
+		g.layoutMenuButtons(g.controlsButtons(), 56)
+	case SceneHex:
+		// 棋盘大小跟随当前难度，结束后或难度变化时开始新的一局
+		if c, ok := hexSettings[g.difficulty]; g.hex == nil || g.hex.over || g.hex.won || (ok && c.radius != g.hex.radius) {
+			g.startHex()
+		}
+		g.menuButtons = []*menuButton{}
+	case SceneNews:
+		g.layoutMenuButtons([]*menuButton{
+			{Button: &Button{Text: "知道了"}, action: func() error {
+				g.dismissNews()
+				return nil
+			}},
+		}, g.screenHeight()-46)
+	case SceneRestore:
+		g.layoutMenuButtons([]*menuButton{
+			{Button: &Button{Text: "恢复对局"}, action: func() error {
+				cp := g.restore
+				g.restore = nil
+				if err := g.restoreCheckpoint(cp); err != nil {
+					slog.Warn("恢复对局失败", "err", err)
+					g.clearCheckpoint()
+					g.switchScene(SceneMainMenu)
+				}
+				return nil
+			}},
+			{Button: &Button{Text: "放弃"}, action: func() error {
+				g.restore = nil
+				g.clearCheckpoint()
+				g.switchScene(SceneMainMenu)
+				return nil
+			}},
+		}, 120)
+	case SceneDuplicate:
+		g.layoutMenuButtons([]*menuButton{
+			{Button: &Button{Text: "切换到已运行的窗口"}, action: func() error {
+				if err := requestFocus(); err != nil {
+					slog.Warn("切换窗口失败", "err", err)
+				}
+				return ebiten.Termination
+			}},
+			{Button: &Button{Text: "使用独立档案"}, action: func() error {
+				l, err := acquireSeparateProfile()
+				if err != nil {
+					return err
+				}
+				globalInstance = l
+				g.loadProfile()
+				g.switchScene(SceneMainMenu)
+				g.checkNews()
+				return nil
+			}},
+			{Button: &Button{Text: "退出"}, action: func() error {
+				return ebiten.Termination
+			}},
+		}, 110)
+	}
+}
+
+func (g *Game) backButton() *menuButton {
+	return &menuButton{Button: &Button{Text: "返回"}, action: func() error {
+		g.switchScene(SceneMainMenu)
+		return nil
+	}}
+}
+
+func (g *Game) settingsButtons() []*menuButton {
+	onOff := func(v bool) string {
+		if v {
+			return tr("开")
+		}
+		return tr("关")
+	}
+	toggle := func(label string, v *bool) *menuButton {
+		return &menuButton{Button: &Button{Text: tr(label) + ": " + onOff(*v)}, action: func() error {
+			*v = !*v
+			g.applySettings()
+			return nil
+		}}
+	}
+
+	language := currentLanguage()
+	for _, l := range languages {
+		if l.Code == currentLanguage() {
+			language = l.Name
+		}
+	}
+
+	return []*menuButton{
+		{Button: &Button{Text: tr("外观") + ": " + tr(activeTheme.Title)}, action: func() error {
+			return g.runCommand("cosmetics")
+		}},
+		toggle("季节主题", &globalConfig.SeasonalThemes),
+		{Button: &Button{Text: fmt.Sprintf("%s: %d%%", tr("音量"), int(globalConfig.Volume*100+0.5))}, action: func() error {
+			// 每次降低 20%，到 0 后回到 100%
+			globalConfig.Volume -= 0.2
+			if globalConfig.Volume < -0.01 {
+				globalConfig.Volume = 1
+			} else if globalConfig.Volume < 0.01 {
+				globalConfig.Volume = 0
+			}
+			g.applySettings()
+			g.playSound("click")
+			return nil
+		}},
+		{Button: &Button{Text: tr("语言") + ": " + language}, action: func() error {
+			next := 0
+			for i, l := range languages {
+				if l.Code == currentLanguage() {
+					next = (i + 1) % len(languages)
+				}
+			}
+			// 在设置中选择语言后不再使用启动参数指定的语言
+			globalConfig.Language = languages[next].Code
+			langOverride = ""
+			g.applySettings()
+			return nil
+		}},
+		toggle("问号标记", &globalConfig.QuestionMarks),
+		{Button: &Button{Text: tr("首次点击安全") + ": " + safeStartTitle()}, action: func() error {
+			nextSafeStart()
+			g.applySettings()
+			return nil
+		}},
+		toggle("无旗模式", &globalConfig.NoFlags),
+		toggle("限时模式", &globalConfig.Countdown),
+		toggle("PB 幽灵", &globalConfig.Ghost),
+		{Button: &Button{Text: tr("无猜模式") + ": " + tr(noGuessTitles[globalConfig.NoGuess])}, action: func() error {
+			modes := noGuessModes
+			if !experimentEnabled(expDeepNoGuess) {
+				modes = modes[:3]
+			}
+			next := 0
+			for i, mode := range modes {
+				if mode == globalConfig.NoGuess {
+					next = (i + 1) % len(modes)
+				}
+			}
+			globalConfig.NoGuess = modes[next]
+			g.applySettings()
+			return nil
+		}},
+		{Button: &Button{Text: tr("轻松模式") + ": " + livesTitle(globalConfig.Lives)}, action: func() error {
+			next := 0
+			for i, n := range livesOptions {
+				if n == globalConfig.Lives {
+					next = (i + 1) % len(livesOptions)
+				}
+			}
+			globalConfig.Lives = livesOptions[next]
+			g.applySettings()
+			return nil
+		}},
+		{Button: &Button{Text: tr("全屏") + ": " + onOff(fullscreen())}, action: func() error {
+			setFullscreen(!fullscreen())
+			g.applySettings()
+			return nil
+		}},
+		toggle("无边框窗口", &globalConfig.Borderless),
+		toggle("动画", &globalConfig.Animations),
+		toggle("逐步翻开", &globalConfig.ProgressiveReveal),
+		toggle("坐标标签", &globalConfig.CoordLabels),
+		{Button: &Button{Text: fmt.Sprintf("%s: %d%%", tr("无尽密度"), int(globalConfig.EndlessDensity*100+0.5))}, action: func() error {
+			next := 0
+			for i, d := range endlessDensities {
+				if d == globalConfig.EndlessDensity {
+					next = (i + 1) % len(endlessDensities)
+				}
+			}
+			globalConfig.EndlessDensity = endlessDensities[next]
+			g.applySettings()
+			return nil
+		}},
+		{Button: &Button{Text: fmt.Sprintf("%s: %gx", tr("GIF 速度"), globalConfig.GifSpeed)}, action: func() error {
+			next := 0
+			for i, s := range gifSpeeds {
+				if s == globalConfig.GifSpeed {
+					next = (i + 1) % len(gifSpeeds)
+				}
+			}
+			globalConfig.GifSpeed = gifSpeeds[next]
+			g.applySettings()
+			return nil
+		}},
+		g.commandButton("controls"),
+		toggle("重启确认", &globalConfig.ConfirmRestart),
+		toggle("退出小结", &globalConfig.SessionRecap),
+		toggle("雷达辅助", &globalConfig.Radar),
+		toggle("Discord 状态", &globalConfig.DiscordPresence),
+		toggle("观战输出", &globalConfig.ObserverOutput),
+		g.commandButton("bug-report"),
+		g.backButton(),
+	}
+}
+
+// 保存配置并立即生效
+func (g *Game) applySettings() {
+	activeTheme = selectTheme(globalConfig, time.Now())
+	setWindowTitle(tr("扫雷游戏"))
+	applyWindowMode()
+	if err := saveConfig(globalConfig); err != nil {
+		slog.Warn("保存配置失败", "err", err)
+	}
+	g.switchScene(g.scene)
+}
+
+// 当前是否全屏，启动参数优先于配置
+func fullscreen() bool {
+	return fullscreenOverride || globalConfig.Fullscreen
+}
+
+// 在设置中或按 F11 切换全屏后不再使用启动参数
+func setFullscreen(on bool) {
+	fullscreenOverride = false
+	globalConfig.Fullscreen = on
+}
+
+// 全屏和调整窗口大小时画面由 Layout 等比缩放并居中，见 layout.go
+func applyWindowMode() {
+	ebiten.SetFullscreen(fullscreen())
+	ebiten.SetWindowDecorated(!globalConfig.Borderless)
+}
+
+func (g *Game) screenWidth() int {
+	return g.viewWidth()
+}
+
+func (g *Game) screenHeight() int {
+	return g.viewHeight() + 80
+}
+
+// 按钮纵向排列并水平居中，按钮较多时缩小高度以适应窗口
+func (g *Game) layoutMenuButtons(buttons []*menuButton, top int) {
+	btnWidth := 180
+	btnHeight := 36
+	spacing := 12
+	if n := len(buttons); top+n*(btnHeight+spacing) > g.screenHeight() {
+		spacing = 6
+		btnHeight = (g.screenHeight()-top)/n - spacing
+	}
+
+	for i, btn := range buttons {
+		btn.X = (g.screenWidth() - btnWidth) / 2
+		btn.Y = top + i*(btnHeight+spacing)
+		btn.W = btnWidth
+		btn.H = btnHeight
+	}
+	g.menuButtons = buttons
+}
+
+func (g *Game) updateMenuScene() error {
+	if g.menuButtons == nil {
+		g.switchScene(g.scene)
+	}
+
+	if g.scene == SceneEndless {
+		return g.updateEndless()
+	}
+	if g.scene == SceneHex {
+		return g.updateHex()
+	}
+	if g.scene == SceneScoreboard {
+		g.updateScoreboard()
+	}
+	if g.scene == SceneRecap {
+		if err := g.updateRecap(); err != nil {
+			return err
+		}
+	}
+	if g.scene == SceneEditor {
+		g.updateEditor()
+	}
+	if g.scene == SceneDrill {
+		g.updateDrill()
+	}
+	if g.scene == SceneSeeds {
+		g.scrollSeeds()
+	}
+	if g.scene == SceneRace {
+		if err := g.updateLobby(); err != nil || g.scene != SceneRace {
+			return err
+		}
+	}
+	if g.scene == SceneRivals {
+		g.updateRivalsView()
+	}
+	if g.scene == SceneChallenge {
+		if err := g.updateChallenge(); err != nil || g.scene != SceneChallenge {
+			return err
+		}
+	}
+
+	x, y := cursorPosition()
+	for _, btn := range g.menuButtons {
+		btn.Hover = btn.Contains(x, y)
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && btn.Hover && !btn.Disabled {
+			g.playSound("click")
+			return btn.action()
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && g.scene == SceneNews {
+		g.dismissNews()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && g.scene != SceneMainMenu && g.scene != SceneDuplicate && g.scene != SceneRestore {
+		g.closeLobby()
+		g.switchScene(SceneMainMenu)
+	}
+	return nil
+}
+
+func (g *Game) drawMenuScene(screen *ebiten.Image) {
+	if g.menuButtons == nil {
+		g.switchScene(g.scene)
+	}
+
+	switch g.scene {
+	case SceneEndless:
+		g.drawEndless(screen)
+	case SceneHex:
+		g.drawHex(screen)
+	case SceneScoreboard:
+		g.drawCentered(screen, tr("计分板"), 36)
+		g.drawScoreboard(screen)
+	case SceneMainMenu:
+		g.drawCentered(screen, tr("扫雷"), 40)
+	case SceneEditor:
+		g.drawEditor(screen)
+	case SceneDrill:
+		g.drawCentered(screen, tr("图案练习"), 26)
+		g.drawDrill(screen)
+	case SceneAchievements:
+		g.drawCentered(screen, tr("成就"), 30)
+		g.drawAchievements(screen)
+	case SceneControls:
+		g.drawCentered(screen, tr("操作"), 36)
+		g.drawControls(screen)
+	case SceneCosmetics:
+		g.drawCentered(screen, tr("外观"), 36)
+		g.drawCosmetics(screen)
+	case SceneArcade:
+		g.drawCentered(screen, tr("街机模式"), 36)
+		g.drawArcadeScores(screen)
+	case ScenePuzzles:
+		g.drawCentered(screen, tr("谜题"), 30)
+		g.drawPuzzleList(screen)
+	case SceneRecap:
+		g.drawCentered(screen, tr("本次小结"), 40)
+		g.drawRecap(screen)
+	case SceneStats:
+		g.drawCentered(screen, tr("统计"), 40)
+		g.drawStats(screen)
+	case SceneSettings:
+		g.drawCentered(screen, tr("设置"), 36)
+	case SceneSeeds:
+		g.drawCentered(screen, tr("历史"), 36)
+	case SceneRace:
+		g.drawCentered(screen, tr("对战"), 36)
+		g.drawLobby(screen)
+	case SceneRivals:
+		g.drawCentered(screen, tr("对手"), 26)
+		g.drawRivals(screen)
+	case SceneChallenge:
+		g.drawCentered(screen, tr("挑战"), 36)
+		g.drawChallengeStatus(screen)
+	case SceneNews:
+		g.drawCentered(screen, tr("新功能"), 36)
+		for i, line := range g.newsLines() {
+			text.Draw(screen, line, g.gameFont, 20, 70+i*20, activeTheme.Text)
+		}
+	case SceneRestore:
+		g.drawCentered(screen, tr("上次的对局意外中断"), 50)
+		g.drawCentered(screen, fmt.Sprintf("%s  %s", tr(difficultyNames[g.restore.difficulty()]), formatDuration(time.Duration(g.restore.ElapsedMS)*time.Millisecond)), 80)
+	case SceneDuplicate:
+		g.drawCentered(screen, tr("游戏已在运行"), 50)
+		g.drawCentered(screen, tr("同时运行会互相覆盖存档"), 80)
+	}
+
+	for _, btn := range g.menuButtons {
+		g.drawButton(screen, btn.Button)
+	}
+	drawToast(screen, g.gameFont)
+}
+
+func (g *Game) drawCentered(screen *ebiten.Image, msg string, y int) {
+	bounds, _ := font.BoundString(g.gameFont, msg)
+	width := (bounds.Max.X - bounds.Min.X).Ceil()
+	text.Draw(screen, msg, g.gameFont, (g.screenWidth()-width)/2, y, activeTheme.Text)
+}
+
+func (g *Game) drawStats(screen *ebiten.Image) {
+	y := 80
+	for _, d := range []Difficulty{Easy, Medium, Hard} {
+		ds := globalStats.forDifficulty(d)
+		best := "--:--"
+		if ds.BestTime > 0 {
+			best = formatDuration(ds.BestTime)
+		}
+		lines := []string{
+			tr(difficultyNames[d]),
+			fmt.Sprintf("  %s: %d/%d  %s: %d", tr("胜/局"), ds.Won, ds.Played, tr("放弃局数"), ds.Abandoned),
+			fmt.Sprintf("  %s: %s  %s: %d", tr("最佳"), best, tr("连胜"), ds.LongestStreak),
+		}
+		for _, line := range lines {
+			text.Draw(screen, line, g.gameFont, 20, y, activeTheme.Text)
+			y += 22
+		}
+		y += 8
+	}
+
+	// 马步、无旗和限时玩法单独排名，玩过才显示
+	for _, v := range []string{"knight", "nf", "countdown"} {
+		line := variantTitle(v) + ":"
+		played := false
+		for _, d := range []Difficulty{Easy, Medium, Hard} {
+			ds := globalStats.forVariant(d, v)
+			best := "--:--"
+			if ds.BestTime > 0 {
+				best = formatDuration(ds.BestTime)
+			}
+			played = played || ds.Played > 0
+			line += fmt.Sprintf(" %s %s", tr(difficultyNames[d]), best)
+		}
+		if played {
+			text.Draw(screen, line, g.gameFont, 20, y, activeTheme.Text)
+			y += 22
+		}
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Seconds())/60, int(d.Seconds())%60)
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/storage"
+)
+
+const (
+	scorePanelWidth = 200 // 对局界面右侧计分板的宽度
+	maxPlayerName   = 16
+)
+
+// 线下比赛用的计分板：多名选手轮流在同一台电脑上游戏，记录每人各难度的最佳时间。
+// 开启展示模式后对局界面右侧一直显示排名
+type scoreboard struct {
+	Players []*scorePlayer `json:"players"`
+	Current int            `json:"current"` // 当前上场的选手
+	Rotate  bool           `json:"rotate"`  // 每局结束后自动轮到下一位
+	Active  bool           `json:"active"`  // 展示模式，只有开启时才记录成绩
+
+	name  string
+	store storage.Storage
+	input string // 计分板界面中正在输入的内容
+}
+
+type scorePlayer struct {
+	Name  string                   `json:"name"`
+	Best  map[string]time.Duration `json:"best"` // 按难度记录的最佳时间
+	Games int                      `json:"games"`
+	Wins  int                      `json:"wins"`
+}
+
+var globalScoreboard = &scoreboard{}
+
+func loadScoreboard(store storage.Storage) (*scoreboard, error) {
+	s := &scoreboard{name: "scoreboard.json", store: store}
+	err := loadFile(s.store, s.name, "scoreboard", s)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("读取计分板失败: %v", err)
+	}
+	return s, nil
+}
+
+func (s *scoreboard) save() error {
+	if s.name == "" {
+		return nil
+	}
+	return saveFile(s.store, s.name, "scoreboard", s)
+}
+
+func (s *scoreboard) current() *scorePlayer {
+	if s.Current < 0 || s.Current >= len(s.Players) {
+		return nil
+	}
+	return s.Players[s.Current]
+}
+
+func (s *scoreboard) player(name string) *scorePlayer {
+	for _, p := range s.Players {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *scoreboard) addPlayer(name string) *scorePlayer {
+	if p := s.player(name); p != nil {
+		return p
+	}
+	p := &scorePlayer{Name: name, Best: make(map[string]time.Duration)}
+	s.Players = append(s.Players, p)
+	return p
+}
+
+func (s *scoreboard) removeCurrent() {
+	if s.current() == nil {
+		return
+	}
+	s.Players = append(s.Players[:s.Current], s.Players[s.Current+1:]...)
+	if s.Current >= len(s.Players) {
+		s.Current = 0
+	}
+}
+
+func (s *scoreboard) next() {
+	if len(s.Players) > 0 {
+		s.Current = (s.Current + 1) % len(s.Players)
+	}
+}
+
+// 记录成绩，只保留更好的时间
+func (p *scorePlayer) record(difficulty string, t time.Duration) {
+	if p.Best == nil {
+		p.Best = make(map[string]time.Duration)
+	}
+	if best, ok := p.Best[difficulty]; !ok || t < best {
+		p.Best[difficulty] = t
+	}
+}
+
+// 展示模式下每局结束时记在当前选手名下，开启轮换时换下一位上场
+func (s *scoreboard) subscribe(bus *EventBus) {
+	handler := func(e Event) {
+		p := s.current()
+		if !s.Active || p == nil {
+			return
+		}
+		p.Games++
+		if e.Type == EventGameWon {
+			p.Wins++
+			p.record(statsKey(e.Difficulty, e.Variant), e.Elapsed)
+		}
+		if s.Rotate {
+			s.next()
+			showToast(tr("下一位") + ": " + s.current().Name)
+		}
+		if err := s.save(); err != nil {
+			slog.Warn("保存计分板失败", "err", err)
+		}
+	}
+	bus.Subscribe(EventGameWon, handler)
+	bus.Subscribe(EventGameLost, handler)
+}
+
+// 输入 "名字" 添加选手，"名字 1:23.4" 或 "名字 83.4" 为选手手动录入当前难度的成绩
+func (s *scoreboard) submit(input string, difficulty Difficulty) error {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil
+	}
+	name := input
+	var t time.Duration
+	if i := strings.LastIndex(input, " "); i > 0 {
+		if d, err := parseRaceTime(input[i+1:]); err == nil {
+			name, t = strings.TrimSpace(input[:i]), d
+		}
+	}
+	// 输入框为成绩留了余量，名字本身不超过 maxPlayerName 个字符
+	if runes := []rune(name); len(runes) > maxPlayerName {
+		name = strings.TrimSpace(string(runes[:maxPlayerName]))
+	}
+	p := s.addPlayer(name)
+	if t > 0 {
+		p.record(difficultyKeys[difficulty], t)
+	}
+	return s.save()
+}
+
+// 解析 "分:秒" 或秒数，秒可以带小数
+func parseRaceTime(v string) (time.Duration, error) {
+	minutes := 0.0
+	if i := strings.Index(v, ":"); i >= 0 {
+		m, err := strconv.Atoi(v[:i])
+		if err != nil {
+			return 0, fmt.Errorf("无效的时间: %s", v)
+		}
+		minutes, v = float64(m), v[i+1:]
+	}
+	sec, err := strconv.ParseFloat(v, 64)
+	// ParseFloat 接受 NaN 和 Inf，转换为 Duration 的结果没有意义
+	if err != nil || sec < 0 || minutes < 0 || math.IsNaN(sec) || math.IsInf(sec, 0) {
+		return 0, fmt.Errorf("无效的时间: %s", v)
+	}
+	total := (minutes*60 + sec) * float64(time.Second)
+	if total >= math.MaxInt64 {
+		return 0, fmt.Errorf("时间太长: %s", v)
+	}
+	return time.Duration(total), nil
+}
+
+// 按难度的最佳时间排序，没有成绩的选手排在最后
+func (s *scoreboard) ranking(difficulty string) []*scorePlayer {
+	ranked := append([]*scorePlayer(nil), s.Players...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, okA := ranked[i].Best[difficulty]
+		b, okB := ranked[j].Best[difficulty]
+		if okA != okB {
+			return okA
+		}
+		return a < b
+	})
+	return ranked
+}
+
+// 对局界面显示计分板时画布右侧增加的宽度
+func (g *Game) scorePanel() int {
+	if !globalScoreboard.Active || g.scene != ScenePlaying {
+		return 0
+	}
+	return scorePanelWidth
+}
+
+func (g *Game) scoreboardButtons() []*menuButton {
+	s := globalScoreboard
+	onOff := func(v bool) string {
+		if v {
+			return tr("开")
+		}
+		return tr("关")
+	}
+	refresh := func(action func()) func() error {
+		return func() error {
+			action()
+			if err := s.save(); err != nil {
+				slog.Warn("保存计分板失败", "err", err)
+			}
+			g.switchScene(SceneScoreboard)
+			return nil
+		}
+	}
+
+	half := (g.screenWidth() - 30) / 2
+	bottom := g.screenHeight() - 44
+	row := func(i int) int { return bottom - i*40 }
+	return []*menuButton{
+		{Button: &Button{X: 10, Y: row(2), W: half, H: 34, Text: tr("展示模式") + ": " + onOff(s.Active)},
+			action: refresh(func() { s.Active = !s.Active })},
+		{Button: &Button{X: 20 + half, Y: row(2), W: half, H: 34, Text: tr("轮流上场") + ": " + onOff(s.Rotate)},
+			action: refresh(func() { s.Rotate = !s.Rotate })},
+		{Button: &Button{X: 10, Y: row(1), W: half, H: 34, Text: "下一位", Disabled: len(s.Players) < 2},
+			action: refresh(s.next)},
+		{Button: &Button{X: 20 + half, Y: row(1), W: half, H: 34, Text: "移除选手", Disabled: s.current() == nil},
+			action: refresh(s.removeCurrent)},
+		{Button: &Button{X: 10, Y: bottom, W: half, H: 34, Text: "开始"}, action: func() error {
+			return g.confirmAbandon(g.restartRound)
+		}},
+		{Button: &Button{X: 20 + half, Y: bottom, W: half, H: 34, Text: "返回"}, action: func() error {
+			g.switchScene(SceneMainMenu)
+			return nil
+		}},
+	}
+}
+
+// 输入选手名字或成绩，点击列表中的名字选择上场的选手
+func (g *Game) updateScoreboard() {
+	s := globalScoreboard
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len([]rune(s.input)) < maxPlayerName+10 {
+			s.input += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && s.input != "" {
+		runes := []rune(s.input)
+		s.input = string(runes[:len(runes)-1])
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		if err := s.submit(s.input, g.difficulty); err != nil {
+			slog.Warn("保存计分板失败", "err", err)
+		}
+		s.input = ""
+		g.switchScene(SceneScoreboard)
+	}
+
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	_, y := cursorPosition()
+	i := (y - scoreListTop + 18) / 22
+	if y >= scoreListTop-18 && y <= g.screenHeight()-140 && i < len(s.Players) {
+		s.Current = i
+		g.switchScene(SceneScoreboard)
+	}
+}
+
+const scoreListTop = 110
+
+func (g *Game) drawScoreboard(screen *ebiten.Image) {
+	s := globalScoreboard
+	cursor := ""
+	if time.Now().UnixMilli()/500%2 == 0 {
+		cursor = "_"
+	}
+	text.Draw(screen, tr("输入名字，可在后面加成绩"), g.gameFont, 10, 60, activeTheme.Text)
+	g.drawButton(screen, &Button{X: 10, Y: 68, W: g.screenWidth() - 20, H: 26, Text: s.input + cursor})
+
+	key := difficultyKeys[g.difficulty]
+	for i, p := range s.Players {
+		y := scoreListTop + i*22
+		if y > g.screenHeight()-140 {
+			break
+		}
+		clr := activeTheme.Text
+		if i == s.Current {
+			clr = color.RGBA{255, 200, 0, 255}
+		}
+		best := "--"
+		if t, ok := p.Best[key]; ok {
+			best = formatDuration(t)
+		}
+		text.Draw(screen, fmt.Sprintf("%s  %d/%d", p.Name, p.Wins, p.Games), g.gameFont, 16, y, clr)
+		text.Draw(screen, best, g.gameFont, g.screenWidth()-90, y, clr)
+	}
+}
+
+// 对局界面右侧的排名，x 为面板左边缘
+func (g *Game) drawScorePanel(screen *ebiten.Image, x int) {
+	s := globalScoreboard
+	h := screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, float32(x), 0, scorePanelWidth, float32(h), activeTheme.Background, false)
+	vector.StrokeLine(screen, float32(x), 0, float32(x), float32(h), 1, activeTheme.ButtonBorder, false)
+
+	title := tr("计分板") + " · " + tr(difficultyNames[g.difficulty])
+	if v := g.variant(); v != "" {
+		title += " · " + variantTitle(v)
+	}
+	text.Draw(screen, title, g.gameFont, x+10, 24, activeTheme.Text)
+	if p := s.current(); p != nil {
+		text.Draw(screen, tr("上场")+": "+p.Name, g.gameFont, x+10, 48, color.RGBA{255, 200, 0, 255})
+	}
+
+	key := statsKey(g.difficulty, g.variant())
+	for i, p := range s.ranking(key) {
+		y := 80 + i*22
+		if y > h-10 {
+			break
+		}
+		best := "--"
+		if t, ok := p.Best[key]; ok {
+			best = formatDuration(t)
+		}
+		clr := activeTheme.Text
+		if p == s.current() {
+			clr = color.RGBA{255, 200, 0, 255}
+		}
+		name := p.Name
+		if len([]rune(name)) > 8 {
+			name = string([]rune(name)[:8])
+		}
+		text.Draw(screen, fmt.Sprintf("%d. %s", i+1, name), g.gameFont, x+10, y, clr)
+		text.Draw(screen, best, g.gameFont, x+scorePanelWidth-70, y, clr)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/storage"
+)
+
+// 按 F12 截图：在下一次 Draw 结束时读取整个窗口的画面，包括棋盘和状态栏，
+// 保存到档案存储的 screenshots 目录，文件名带时间。编码和写入在后台进行，不影响帧率
+var screenshotPending bool
+
+// 后台保存文件的结果，在 Update 中取出并提示
+type savedFile struct {
+	name   string
+	err    error
+	done   string // 成功时的提示，中文，显示时经过 tr
+	failed string // 失败时的日志和提示
+}
+
+var screenshotResults = make(chan savedFile, 4)
+
+func reportSaved(f savedFile) {
+	if f.err != nil {
+		slog.Warn(f.failed, "err", f.err)
+		showToast(tr(f.failed))
+		return
+	}
+	showToast(tr(f.done) + ": " + filepath.Base(f.name))
+}
+
+func (g *Game) updateSavedFiles() {
+	for {
+		select {
+		case f := <-screenshotResults:
+			reportSaved(f)
+		case f := <-gifExport:
+			gifExport = nil
+			reportSaved(f)
+		default:
+			return
+		}
+	}
+}
+
+func (g *Game) requestScreenshot() error {
+	screenshotPending = true
+	return nil
+}
+
+// 在 Draw 的最后调用，此时画面已经绘制完整。Draw 中只复制像素
+func saveScreenshotIfRequested(store storage.Storage, screen *ebiten.Image) {
+	if !screenshotPending {
+		return
+	}
+	screenshotPending = false
+	// ReadPixels 返回预乘透明度的 RGBA，与 image.RGBA 的格式相同
+	b := screen.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	screen.ReadPixels(img.Pix)
+	go func() {
+		name, err := writeScreenshot(store, img)
+		screenshotResults <- savedFile{name: name, err: err, done: "已保存截图", failed: "保存截图失败"}
+	}()
+}
+
+func writeScreenshot(store storage.Storage, img *image.RGBA) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("编码截图失败: %v", err)
+	}
+	name := "screenshots/minesweeper-" + time.Now().Format("20060102-150405.000") + ".png"
+	if err := store.Write(name, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("保存截图失败: %v", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"minesweeper/board"
+	"minesweeper/storage"
+)
+
+const (
+	maxSeedHistory = 50 // 非收藏记录的保留数量
+	maxRerolls     = 10 // 生成的布局最近玩过时最多重新生成的次数
+)
+
+// 一局对局的种子记录，种子、难度和起始位置共同决定地雷布局
+type seedRecord struct {
+	Difficulty string        `json:"difficulty"`
+	Seed       int64         `json:"seed"`
+	StartX     int           `json:"start_x"` // 首次点击位置，-1 表示没有安全区
+	StartY     int           `json:"start_y"`
+	Won        bool          `json:"won"`
+	Time       time.Duration `json:"time"`
+	PlayedAt   time.Time     `json:"played_at"`
+	Favorite   bool          `json:"favorite"`
+	Hash       string        `json:"hash,omitempty"`      // 地雷布局的哈希，可用来证明玩过的是不同的棋盘
+	SafeCell   bool          `json:"safe_cell,omitempty"` // 安全区只有首次点击的格子，见 safestart.go
+	Layout     int           `json:"layout,omitempty"`    // 布雷算法的版本，见 board.LayoutVersion
+}
+
+// 分享用的种子代码，格式为 难度-种子-起始X-起始Y，没有安全区时起始位置写作 _，
+// 安全区只有一个格子时加 -c，末尾是布雷算法的版本，如 -v2。没有版本的旧代码按版本 1 处理
+func (r *seedRecord) code() string {
+	code := fmt.Sprintf("%s-%d-%s-%s", r.Difficulty, r.Seed, startCoord(r.StartX), startCoord(r.StartY))
+	if r.SafeCell {
+		code += "-c"
+	}
+	return code + fmt.Sprintf("-v%d", r.layout())
+}
+
+// 没有记录版本的是改用 PCG 之前的种子
+func (r *seedRecord) layout() int {
+	if r.Layout == 0 {
+		return 1
+	}
+	return r.Layout
+}
+
+// 起始坐标，负数表示没有安全区，写作 _ 以免与分隔符混淆
+func startCoord(v int) string {
+	if v < 0 {
+		return "_"
+	}
+	return strconv.Itoa(v)
+}
+
+func parseStartCoord(s string) (int, error) {
+	if s == "_" {
+		return -1, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// 按 - 分隔种子代码。旧版本把没有安全区的起始位置写作 -1，
+// 分隔后出现的空字段与下一个字段合并为负数
+func splitSeedCode(code string) []string {
+	var parts []string
+	fields := strings.Split(code, "-")
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" && i > 0 && i+1 < len(fields) {
+			i++
+			parts = append(parts, "-"+fields[i])
+			continue
+		}
+		parts = append(parts, fields[i])
+	}
+	return parts
+}
+
+func parseSeedCode(code string) (*seedRecord, error) {
+	parts := splitSeedCode(strings.TrimSpace(code))
+	layout := 1
+	if n := len(parts); n > 4 && strings.HasPrefix(parts[n-1], "v") {
+		v, err := strconv.Atoi(parts[n-1][1:])
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("无效的布局版本: %s", parts[n-1])
+		}
+		layout, parts = v, parts[:n-1]
+	}
+	cell := len(parts) == 5 && parts[4] == "c"
+	if cell {
+		parts = parts[:4]
+	}
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("无效的种子代码: %s", code)
+	}
+	if _, ok := difficultyByKey(parts[0]); !ok {
+		return nil, fmt.Errorf("未知难度: %s", parts[0])
+	}
+	seed, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的种子: %v", err)
+	}
+	x, errX := parseStartCoord(parts[2])
+	y, errY := parseStartCoord(parts[3])
+	if errX != nil || errY != nil || (x < 0) != (y < 0) {
+		return nil, fmt.Errorf("无效的起始位置: %s", code)
+	}
+	return &seedRecord{Difficulty: parts[0], Seed: seed, StartX: x, StartY: y, SafeCell: cell, Layout: layout}, nil
+}
+
+// 自定义难度只有在本次运行指定了尺寸时才可用
+func difficultyByKey(key string) (Difficulty, bool) {
+	for d, k := range difficultyKeys {
+		if _, ok := difficultySettings[d]; ok && k == key {
+			return d, true
+		}
+	}
+	return Easy, false
+}
+
+// 最近玩过的种子，最新的在前
+type SeedHistory struct {
+	Records []*seedRecord `json:"records"`
+
+	name  string
+	store storage.Storage
+}
+
+var globalSeeds = &SeedHistory{}
+
+func loadSeedHistory(store storage.Storage) (*SeedHistory, error) {
+	h := &SeedHistory{}
+
+	h.name = "seeds.json"
+	h.store = store
+
+	err := loadFile(h.store, h.name, "seeds", h)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("读取种子记录失败: %v", err)
+	}
+	return h, nil
+}
+
+func (h *SeedHistory) save() error {
+	if h.name == "" {
+		return nil
+	}
+	return saveFile(h.store, h.name, "seeds", h)
+}
+
+// 添加记录，超出上限时丢弃最旧的非收藏记录
+func (h *SeedHistory) add(r *seedRecord) {
+	h.Records = append([]*seedRecord{r}, h.Records...)
+
+	kept := h.Records[:0]
+	normal := 0
+	for _, rec := range h.Records {
+		if !rec.Favorite {
+			normal++
+			if normal > maxSeedHistory {
+				continue
+			}
+		}
+		kept = append(kept, rec)
+	}
+	h.Records = kept
+}
+
+// 历史记录中是否有相同布局的对局
+func (h *SeedHistory) playedRecently(hash string) bool {
+	for _, r := range h.Records {
+		if r.Hash != "" && r.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *SeedHistory) subscribe(bus *EventBus) {
+	handler := func(e Event) {
+		// 种子记录按经典棋盘重玩，其他拓扑的对局不记录
+		if e.Variant != "" {
+			return
+		}
+		h.add(&seedRecord{
+			Difficulty: difficultyKeys[e.Difficulty],
+			Seed:       e.Seed,
+			StartX:     e.StartX,
+			StartY:     e.StartY,
+			Won:        e.Type == EventGameWon,
+			Time:       e.Elapsed,
+			PlayedAt:   time.Now(),
+			Hash:       e.Hash,
+			SafeCell:   e.SafeCell,
+			Layout:     board.LayoutVersion,
+		})
+		if err := h.save(); err != nil {
+			slog.Warn("保存种子记录失败", "err", err)
+		}
+	}
+	bus.Subscribe(EventGameWon, handler)
+	bus.Subscribe(EventGameLost, handler)
+}
+
+// 当前对局的种子，用于分享和问题报告
+func (g *Game) currentSeed() seedRecord {
+	return seedRecord{
+		Difficulty: difficultyKeys[g.difficulty],
+		Seed:       g.seed,
+		StartX:     g.startX,
+		StartY:     g.startY,
+		SafeCell:   g.safeCell,
+		Layout:     board.LayoutVersion,
+	}
+}
+
+// 按记录中的种子重新开始，地雷立即按原布局放置。旧版本的种子得到的布局不同，
+// 有哈希时按哈希判断，没有时按版本判断，不同时提示玩家
+func (g *Game) replaySeed(r *seedRecord) error {
+	difficulty, _ := difficultyByKey(r.Difficulty)
+	if err := g.newRound(difficulty); err != nil {
+		return err
+	}
+	g.seed, g.safeCell = r.Seed, r.SafeCell
+	g.layoutMines(r.StartX, r.StartY)
+	changed := r.layout() != board.LayoutVersion
+	if r.Hash != "" {
+		changed = r.Hash != g.boardHash
+	}
+	if changed {
+		slog.Warn("种子的布局与记录不同", "code", r.code(), "hash", r.Hash)
+		showToast(tr("这个种子来自旧版本，棋盘与原来不同"))
+	}
+	return nil
+}
+
+// 对战、合作、挑战和街机有自己的规则，谜题重新开始本来就是同一个棋盘
+func (g *Game) retryAllowed() bool {
+	return g.race == nil && g.coop == nil && g.challenge == nil && g.arcade == nil && g.puzzle == nil
+}
+
+// 重试刚才的棋盘：以相同的种子和起点重新布雷，拓扑和形状不变。
+// 已经知道地雷位置，成绩按 retry 玩法单独记录，见 variant
+func (g *Game) retryBoard() error {
+	if !g.retryAllowed() {
+		showToast(tr("对战和挑战中不可用"))
+		return nil
+	}
+	seed, x, y, cell := g.seed, g.startX, g.startY, g.safeCell
+	if err := g.newVariantRound(g.difficulty, g.topology, g.shapeName()); err != nil {
+		return err
+	}
+	g.seed, g.safeCell = seed, cell
+	g.layoutMines(x, y)
+	g.retry = true
+	showToast(tr("重试此局"))
+	return nil
+}
+
+// 种子历史界面：每行依次为收藏、重玩和复制按钮
+func (g *Game) seedButtons() []*menuButton {
+	var buttons []*menuButton
+	rowHeight := 30
+	top := 60
+	rows := (g.screenHeight() - top - 50) / rowHeight
+	if g.seedScroll > len(globalSeeds.Records)-rows {
+		g.seedScroll = len(globalSeeds.Records) - rows
+	}
+	if g.seedScroll < 0 {
+		g.seedScroll = 0
+	}
+
+	for i := g.seedScroll; i < len(globalSeeds.Records) && i < g.seedScroll+rows; i++ {
+		rec := globalSeeds.Records[i]
+		y := top + (i-g.seedScroll)*rowHeight
+
+		star := "☆"
+		if rec.Favorite {
+			star = "★"
+		}
+		result := tr("负")
+		if rec.Won {
+			result = tr("胜")
+		}
+		name := map[string]string{"easy": "简单", "medium": "中等", "hard": "困难"}[rec.Difficulty]
+		label := fmt.Sprintf("%s %s %s #%d", tr(name), result, formatDuration(rec.Time), rec.Seed%100000)
+
+		buttons = append(buttons,
+			&menuButton{Button: &Button{X: 8, Y: y, W: 28, H: rowHeight - 4, Text: star}, action: func() error {
+				rec.Favorite = !rec.Favorite
+				if err := globalSeeds.save(); err != nil {
+					slog.Warn("保存种子记录失败", "err", err)
+				}
+				g.switchScene(SceneSeeds)
+				return nil
+			}},
+			&menuButton{Button: &Button{X: 40, Y: y, W: g.screenWidth() - 108, H: rowHeight - 4, Text: label}, action: func() error {
+				return g.confirmAbandon(func() error { return g.replaySeed(rec) })
+			}},
+			&menuButton{Button: &Button{X: g.screenWidth() - 64, Y: y, W: 56, H: rowHeight - 4, Text: "复制"}, action: func() error {
+				if err := writeClipboard(rec.code()); err != nil {
+					slog.Warn("复制到剪贴板失败", "err", err)
+					showToast(rec.code())
+					return nil
+				}
+				showToast(tr("已复制种子"))
+				return nil
+			}},
+		)
+	}
+
+	half := (g.screenWidth() - 30) / 2
+	back := g.backButton()
+	back.X, back.Y, back.W, back.H = 20+half, g.screenHeight()-44, half, 34
+	buttons = append(buttons,
+		&menuButton{Button: &Button{X: 10, Y: g.screenHeight() - 44, W: half, H: 34, Text: "粘贴种子"}, action: func() error {
+			code, err := readClipboard()
+			if err != nil {
+				slog.Warn("读取剪贴板失败", "err", err)
+				return nil
+			}
+			rec, err := parseSeedCode(code)
+			if err != nil {
+				showToast(tr("剪贴板中没有有效的种子"))
+				return nil
+			}
+			return g.confirmAbandon(func() error { return g.replaySeed(rec) })
+		}},
+		back,
+	)
+	return buttons
+}
+
+func (g *Game) scrollSeeds() {
+	_, wy := ebiten.Wheel()
+	if wy == 0 {
+		return
+	}
+	if wy > 0 {
+		g.seedScroll--
+	} else {
+		g.seedScroll++
+	}
+	g.switchScene(SceneSeeds)
+}
@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestSeedCodeRoundTrip(t *testing.T) {
+	records := []*seedRecord{
+		{Difficulty: "easy", Seed: 123, StartX: 4, StartY: 5, Layout: 2},
+		{Difficulty: "hard", Seed: 9876543210, StartX: 0, StartY: 0, SafeCell: true, Layout: 2},
+		{Difficulty: "easy", Seed: 123, StartX: -1, StartY: -1, Layout: 2},
+		{Difficulty: "medium", Seed: 7, StartX: -1, StartY: -1, SafeCell: true, Layout: 1},
+	}
+	for _, want := range records {
+		code := want.code()
+		got, err := parseSeedCode(code)
+		if err != nil {
+			t.Errorf("%s: %v", code, err)
+			continue
+		}
+		if got.Difficulty != want.Difficulty || got.Seed != want.Seed || got.StartX != want.StartX ||
+			got.StartY != want.StartY || got.SafeCell != want.SafeCell || got.layout() != want.layout() {
+			t.Errorf("%s: 解析得到 %+v，应为 %+v", code, got, want)
+		}
+	}
+}
+
+func TestParseLegacySeedCode(t *testing.T) {
+	cases := []struct {
+		code         string
+		x, y, layout int
+	}{
+		{"easy-123-4-5", 4, 5, 1},
+		{"easy-123--1--1-v2", -1, -1, 2},
+		{"easy-123--1--1-c", -1, -1, 1},
+	}
+	for _, c := range cases {
+		r, err := parseSeedCode(c.code)
+		if err != nil {
+			t.Errorf("%s: %v", c.code, err)
+			continue
+		}
+		if r.StartX != c.x || r.StartY != c.y || r.layout() != c.layout {
+			t.Errorf("%s: 解析得到 (%d, %d) v%d", c.code, r.StartX, r.StartY, r.layout())
+		}
+	}
+}
+
+func TestParseSeedCodeInvalid(t *testing.T) {
+	for _, code := range []string{"", "easy-123", "easy-123-_-5", "nope-1-2-3", "easy-x-1-1", "easy-1-1-1-v0"} {
+		if _, err := parseSeedCode(code); err == nil {
+			t.Errorf("%q 应解析失败", code)
+		}
+	}
+}
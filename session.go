@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 本次运行的对局统计，只保存在内存中，切换档案也不会清空
+type sessionStats struct {
+	start time.Time
+	games int
+	wins  int
+	best  map[string]time.Duration // 按成绩分类记录的最佳时间，键见 statsKey
+}
+
+var session = &sessionStats{start: time.Now(), best: make(map[string]time.Duration)}
+
+func (s *sessionStats) subscribe(bus *EventBus) {
+	handler := func(e Event) {
+		s.games++
+		if e.Type != EventGameWon {
+			return
+		}
+		s.wins++
+		key := statsKey(e.Difficulty, e.Variant)
+		if best, ok := s.best[key]; !ok || e.Elapsed < best {
+			s.best[key] = e.Elapsed
+		}
+	}
+	bus.Subscribe(EventGameWon, handler)
+	bus.Subscribe(EventGameLost, handler)
+}
+
+// 今天的最佳时间：本次运行的成绩加上种子记录中今天早些时候的胜局
+func (s *sessionStats) bestToday() map[string]time.Duration {
+	best := make(map[string]time.Duration, len(s.best))
+	for k, v := range s.best {
+		best[k] = v
+	}
+	y, m, d := time.Now().Date()
+	for _, r := range globalSeeds.Records {
+		ry, rm, rd := r.PlayedAt.Date()
+		if !r.Won || ry != y || rm != m || rd != d {
+			continue
+		}
+		if cur, ok := best[r.Difficulty]; !ok || r.Time < cur {
+			best[r.Difficulty] = r.Time
+		}
+	}
+	return best
+}
+
+// 成绩分类的显示名称，如 "简单 · 马步"
+func statsKeyTitle(key string) string {
+	diff, variant, _ := strings.Cut(key, "/")
+	title := diff
+	if d, ok := difficultyByKey(diff); ok {
+		title = tr(difficultyNames[d])
+	}
+	if variant != "" {
+		title += " · " + variantTitle(variant)
+	}
+	return title
+}
+
+// 退出游戏。本次玩过多局时先显示小结，小结界面再次退出时直接关闭
+func (g *Game) quit() error {
+	if g.scene == SceneRecap || !globalConfig.SessionRecap || session.games < 2 {
+		return ebiten.Termination
+	}
+	g.switchScene(SceneRecap)
+	return nil
+}
+
+func (g *Game) recapButtons() []*menuButton {
+	half := (g.screenWidth() - 30) / 2
+	return []*menuButton{
+		{Button: &Button{X: 10, Y: g.screenHeight() - 44, W: half, H: 34, Text: "退出"}, action: func() error {
+			return ebiten.Termination
+		}},
+		{Button: &Button{X: 20 + half, Y: g.screenHeight() - 44, W: half, H: 34, Text: "不再显示"}, action: func() error {
+			globalConfig.SessionRecap = false
+			if err := saveConfig(globalConfig); err != nil {
+				return fmt.Errorf("保存配置失败: %v", err)
+			}
+			return ebiten.Termination
+		}},
+	}
+}
+
+// 回车或 Esc 跳过小结直接退出
+func (g *Game) updateRecap() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+	return nil
+}
+
+func (g *Game) drawRecap(screen *ebiten.Image) {
+	played := time.Since(session.start).Round(time.Minute)
+	lines := []string{
+		fmt.Sprintf("%s: %d", tr("对局"), session.games),
+		fmt.Sprintf("%s: %d (%d%%)", tr("胜利"), session.wins, session.wins*100/session.games),
+		fmt.Sprintf("%s: %d %s", tr("时长"), int(played.Minutes()), tr("分钟")),
+	}
+
+	best := session.bestToday()
+	keys := make([]string, 0, len(best))
+	for k := range best {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		lines = append(lines, "", tr("今日最佳"))
+	}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s  %s", statsKeyTitle(k), formatDuration(best[k])))
+	}
+	if len(sessionAchievements) > 0 {
+		lines = append(lines, "", tr("新成就"))
+	}
+	for _, title := range sessionAchievements {
+		lines = append(lines, tr(title))
+	}
+
+	for i, line := range lines {
+		g.drawCentered(screen, line, 80+i*24)
+	}
+}
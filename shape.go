@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"minesweeper/board"
+)
+
+// 自定义形状的图片，放在档案目录中，深色像素为格子，按当前难度的棋盘大小缩放
+const customShapeFile = "shape.png"
+
+// 形状的显示名称
+var shapeTitles = map[string]string{
+	"heart":   "爱心",
+	"diamond": "菱形",
+	"ring":    "圆环",
+	"custom":  "自定义",
+}
+
+// 按名称生成当前难度棋盘大小的轮廓
+func (g *Game) loadShape(name string) (*board.Mask, error) {
+	if m, ok := board.ShapeMask(name, g.gridWidth, g.gridHeight); ok {
+		return m, nil
+	}
+	if name != "custom" {
+		return nil, fmt.Errorf("未知形状: %s", name)
+	}
+
+	dir, err := dataDir()
+	if err != nil {
+		return nil, fmt.Errorf("获取档案目录失败: %v", err)
+	}
+	f, err := os.Open(filepath.Join(dir, customShapeFile))
+	if err != nil {
+		return nil, fmt.Errorf("读取自定义形状失败: %v", err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("解析自定义形状失败: %v", err)
+	}
+
+	// 每个格子取图片中对应位置的像素，不透明的深色像素表示格子存在
+	b := img.Bounds()
+	m := board.NewMask(name, g.gridWidth, g.gridHeight, func(x, y int) bool {
+		px := b.Min.X + (2*x+1)*b.Dx()/(2*g.gridWidth)
+		py := b.Min.Y + (2*y+1)*b.Dy()/(2*g.gridHeight)
+		r, gr, bl, a := img.At(px, py).RGBA()
+		return a >= 0x8000 && (r+gr+bl)/3 < 0x8000
+	})
+	if m.Count() < 10 {
+		return nil, fmt.Errorf("自定义形状的格子太少: %d", m.Count())
+	}
+	return m, nil
+}
+
+// 格子是否在棋盘上，不规则形状以外的格子不存在
+func (g *Game) exists(x, y int) bool {
+	return g.shape == nil || g.shape.Contains(x, y)
+}
+
+// 棋盘上的格子数
+func (g *Game) cellCount() int {
+	if g.shape != nil {
+		return g.shape.Count()
+	}
+	return g.gridWidth * g.gridHeight
+}
+
+// 地雷数按形状的面积同比减少，至少留出首次点击的安全区。谜题的地雷数是固定的
+func (g *Game) mineCount() int {
+	config := g.size
+	if g.shape == nil || g.puzzle != nil {
+		return config.MineCount
+	}
+	n := config.MineCount * g.shape.Count() / (config.GridWidth * config.GridHeight)
+	return clamp(n, 1, g.shape.Count()-9)
+}
+
+// 实际使用的相邻关系，不规则形状在拓扑上去掉形状以外的格子
+func (g *Game) adjacency() board.Topology {
+	if g.shape == nil {
+		return g.topology
+	}
+	return board.Shaped{Base: g.topology, Mask: g.shape}
+}
+
+func (g *Game) shapeName() string {
+	if g.shape == nil {
+		return ""
+	}
+	return g.shape.Name
+}
+
+// 依次切换内置形状，最后一个之后回到矩形棋盘
+func (g *Game) nextShape() error {
+	next := ""
+	if g.shape == nil {
+		next = board.Shapes[0]
+	}
+	for i, name := range board.Shapes {
+		if name == g.shapeName() && i+1 < len(board.Shapes) {
+			next = board.Shapes[i+1]
+		}
+	}
+	return g.newVariantRound(g.difficulty, g.topology, next)
+}
@@ -0,0 +1,59 @@
+package solver
+
+import (
+	"time"
+
+	"minesweeper/board"
+)
+
+// 无猜布局要求的推理深度，空字符串表示只要求无需猜测
+const (
+	NoGuessTrivial = "trivial" // 只用单格计数
+	NoGuessSubset  = "subset"  // 需要子集规则，但推理链不长
+	NoGuessDeep    = "deep"    // 至少 DeepSubsetSteps 次需要子集规则才能继续
+)
+
+const DeepSubsetSteps = 5
+
+// MatchesNoGuess 判断求解结果是否符合要求的推理深度
+func MatchesNoGuess(mode string, o Outcome) bool {
+	if !o.Solved {
+		return false
+	}
+	switch mode {
+	case NoGuessTrivial:
+		return o.MaxLevel == LevelTrivial
+	case NoGuessSubset:
+		return o.MaxLevel >= LevelSubset && o.SubsetSteps < DeepSubsetSteps
+	case NoGuessDeep:
+		return o.SubsetSteps >= DeepSubsetSteps
+	}
+	return true
+}
+
+// NoGuessSeed 从 seed 派生候选种子，直到找到从 start 开始无需猜测且符合推理深度的布局。
+// 超出时间预算时退而使用找到的任意无猜布局，仍找不到则使用最后一个候选，此时 ok 为 false
+func NoGuessSeed(width, height, mines int, seed int64, start Point, mode string, budget time.Duration) (candidate int64, ok bool) {
+	master := board.NewRand(seed)
+	deadline := time.Now().Add(budget)
+
+	var fallback int64
+	foundSolvable := false
+	candidate = seed
+	for time.Now().Before(deadline) {
+		candidate = master.Int64()
+		layout := board.PlaceMines(width, height, mines, candidate, start.X, start.Y)
+		outcome := Play(layout, start)
+		if MatchesNoGuess(mode, outcome) {
+			return candidate, true
+		}
+		if outcome.Solved && !foundSolvable {
+			fallback, foundSolvable = candidate, true
+		}
+	}
+
+	if foundSolvable {
+		return fallback, true
+	}
+	return candidate, false
+}
@@ -0,0 +1,376 @@
+package solver
+
+import (
+	"math"
+
+	"minesweeper/board"
+)
+
+const (
+	maxEnumerateNodes  = 200000 // 单个连通分量精确枚举时最多搜索的节点数，超出后改为抽样
+	probabilitySamples = 400
+)
+
+// 一个数字对边界格子的约束，cells 为边界格子的编号
+type rule struct {
+	cells []int
+	mines int
+}
+
+// 一个连通分量的枚举结果。counts[k] 为恰有 k 个地雷的布局所占的比例，
+// cellCounts[k][i] 为其中第 i 个格子是地雷的比例
+type componentStats struct {
+	cells      []int
+	counts     []float64
+	cellCounts [][]float64
+}
+
+// Probabilities 根据可见信息计算每个格子是地雷的概率，按行优先存放，已翻开的格子为 0。
+// mines 是棋盘上的地雷总数。与数字相邻的边界格子按连通分量精确枚举，
+// 分量太大时改为随机抽样近似；其余格子平分剩下的地雷。可见信息自相矛盾时返回 nil
+func Probabilities(v *View, mines int) []float64 {
+	t := v.Topology
+	if t == nil {
+		t = board.Square8{}
+	}
+
+	// 收集约束和边界格子
+	index := make(map[Point]int)
+	var frontier []Point
+	var rules []rule
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			n := v.At(x, y)
+			if n == Unknown {
+				continue
+			}
+			r := rule{mines: n}
+			for _, p := range v.Neighbors(x, y) {
+				if v.At(p.X, p.Y) != Unknown {
+					continue
+				}
+				i, ok := index[p]
+				if !ok {
+					i = len(frontier)
+					index[p] = i
+					frontier = append(frontier, p)
+				}
+				r.cells = append(r.cells, i)
+			}
+			if len(r.cells) > 0 {
+				rules = append(rules, r)
+			} else if r.mines > 0 {
+				return nil
+			}
+		}
+	}
+	others := 0
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			if _, ok := index[Point{x, y}]; !ok && v.At(x, y) == Unknown && board.Exists(t, v.Width, x, y) {
+				others++
+			}
+		}
+	}
+
+	var comps []*componentStats
+	for _, c := range components(len(frontier), rules) {
+		s := enumerate(c.cells, c.rules)
+		if s == nil {
+			s = sample(c.cells, c.rules)
+		}
+		if s == nil {
+			return nil
+		}
+		comps = append(comps, s)
+	}
+
+	// 剩余地雷放在非边界格子中的方式数作为权重，取对数避免溢出
+	weight := make([]float64, len(frontier)+1)
+	maxLog := math.Inf(-1)
+	for k := range weight {
+		weight[k] = logChoose(others, mines-k)
+		maxLog = math.Max(maxLog, weight[k])
+	}
+	if math.IsInf(maxLog, -1) {
+		return nil
+	}
+	for k := range weight {
+		weight[k] = math.Exp(weight[k] - maxLog)
+	}
+
+	total := []float64{1}
+	for _, s := range comps {
+		total = convolve(total, s.counts)
+	}
+	z := 0.0
+	otherMines := 0.0
+	for k, n := range total {
+		z += n * weight[k]
+		if others > 0 {
+			otherMines += n * weight[k] * float64(mines-k) / float64(others)
+		}
+	}
+	if z == 0 {
+		return nil
+	}
+
+	probs := make([]float64, len(v.Cells))
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			if _, ok := index[Point{x, y}]; !ok && v.At(x, y) == Unknown && board.Exists(t, v.Width, x, y) {
+				probs[y*v.Width+x] = otherMines / z
+			}
+		}
+	}
+	for i, s := range comps {
+		// 其他分量合在一起的地雷数分布
+		rest := []float64{1}
+		for j, o := range comps {
+			if j != i {
+				rest = convolve(rest, o.counts)
+			}
+		}
+		for k, row := range s.cellCounts {
+			w := 0.0
+			for r, n := range rest {
+				if k+r < len(weight) {
+					w += n * weight[k+r]
+				}
+			}
+			for ci, n := range row {
+				p := frontier[s.cells[ci]]
+				probs[p.Y*v.Width+p.X] += n * w / z
+			}
+		}
+	}
+	return probs
+}
+
+type component struct {
+	cells []int // 边界格子的编号
+	rules []rule
+}
+
+// 按共同约束把边界格子分成互不相关的分量
+func components(n int, rules []rule) []component {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, r := range rules {
+		for _, c := range r.cells[1:] {
+			parent[find(c)] = find(r.cells[0])
+		}
+	}
+
+	byRoot := make(map[int]int)
+	var comps []component
+	for i := 0; i < n; i++ {
+		root := find(i)
+		ci, ok := byRoot[root]
+		if !ok {
+			ci = len(comps)
+			byRoot[root] = ci
+			comps = append(comps, component{})
+		}
+		comps[ci].cells = append(comps[ci].cells, i)
+	}
+	for _, r := range rules {
+		ci := byRoot[find(r.cells[0])]
+		comps[ci].rules = append(comps[ci].rules, r)
+	}
+	return comps
+}
+
+// 分量内的回溯搜索，格子按分量内的顺序编号
+type search struct {
+	need   []int   // 每个约束要求的地雷数
+	byCell [][]int // 每个格子涉及的约束
+	mine   []bool
+	placed []int // 每个约束中已放的地雷数
+	open   []int // 每个约束中尚未决定的格子数
+	nodes  int
+}
+
+func newSearch(cells []int, rules []rule) *search {
+	local := make(map[int]int, len(cells))
+	for i, c := range cells {
+		local[c] = i
+	}
+	s := &search{
+		byCell: make([][]int, len(cells)),
+		mine:   make([]bool, len(cells)),
+		placed: make([]int, len(rules)),
+		open:   make([]int, len(rules)),
+	}
+	for ri, r := range rules {
+		for _, c := range r.cells {
+			s.byCell[local[c]] = append(s.byCell[local[c]], ri)
+		}
+		s.need = append(s.need, r.mines)
+		s.open[ri] = len(r.cells)
+	}
+	return s
+}
+
+// 决定第 i 个格子，返回是否仍可能满足所有约束
+func (s *search) set(i int, mine bool) bool {
+	s.mine[i] = mine
+	ok := true
+	for _, ri := range s.byCell[i] {
+		s.open[ri]--
+		if mine {
+			s.placed[ri]++
+		}
+		if s.placed[ri] > s.need[ri] || s.placed[ri]+s.open[ri] < s.need[ri] {
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (s *search) unset(i int) {
+	for _, ri := range s.byCell[i] {
+		s.open[ri]++
+		if s.mine[i] {
+			s.placed[ri]--
+		}
+	}
+	s.mine[i] = false
+}
+
+// 精确枚举分量的所有布局，搜索节点超出上限时返回 nil
+func enumerate(cells []int, rules []rule) *componentStats {
+	s := newSearch(cells, rules)
+	stats := newComponentStats(cells)
+	var walk func(i, mines int) bool
+	walk = func(i, mines int) bool {
+		s.nodes++
+		if s.nodes > maxEnumerateNodes {
+			return false
+		}
+		if i == len(cells) {
+			stats.add(s.mine, mines)
+			return true
+		}
+		for _, mine := range []bool{false, true} {
+			ok := s.set(i, mine)
+			n := mines
+			if mine {
+				n++
+			}
+			if ok && !walk(i+1, n) {
+				s.unset(i)
+				return false
+			}
+			s.unset(i)
+		}
+		return true
+	}
+	if !walk(0, 0) {
+		return nil
+	}
+	return stats.normalize()
+}
+
+// 随机决定搜索顺序，多次找出满足约束的布局，用出现频率近似概率。
+// 抽样并不严格均匀，只用于分量太大无法枚举的情况
+func sample(cells []int, rules []rule) *componentStats {
+	rng := board.NewRand(int64(len(cells)))
+	s := newSearch(cells, rules)
+	stats := newComponentStats(cells)
+	var walk func(i, mines int) bool
+	walk = func(i, mines int) bool {
+		s.nodes++
+		if s.nodes > maxEnumerateNodes {
+			return false
+		}
+		if i == len(cells) {
+			stats.add(s.mine, mines)
+			return true
+		}
+		first := rng.IntN(2) == 1
+		for _, mine := range []bool{first, !first} {
+			ok := s.set(i, mine)
+			n := mines
+			if mine {
+				n++
+			}
+			found := ok && walk(i+1, n)
+			s.unset(i)
+			if found {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < probabilitySamples; i++ {
+		s.nodes = 0
+		walk(0, 0)
+	}
+	return stats.normalize()
+}
+
+func newComponentStats(cells []int) *componentStats {
+	s := &componentStats{cells: cells, counts: make([]float64, len(cells)+1), cellCounts: make([][]float64, len(cells)+1)}
+	for k := range s.cellCounts {
+		s.cellCounts[k] = make([]float64, len(cells))
+	}
+	return s
+}
+
+func (s *componentStats) add(mine []bool, k int) {
+	s.counts[k]++
+	for i, m := range mine {
+		if m {
+			s.cellCounts[k][i]++
+		}
+	}
+}
+
+// 换算成比例，避免多个分量的布局数相乘后溢出。没有任何布局时返回 nil
+func (s *componentStats) normalize() *componentStats {
+	sum := 0.0
+	for _, n := range s.counts {
+		sum += n
+	}
+	if sum == 0 {
+		return nil
+	}
+	for k := range s.counts {
+		s.counts[k] /= sum
+		for i := range s.cellCounts[k] {
+			s.cellCounts[k][i] /= sum
+		}
+	}
+	return s
+}
+
+func convolve(a, b []float64) []float64 {
+	out := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			out[i+j] += x * y
+		}
+	}
+	return out
+}
+
+// ln C(n, k)，k 超出范围时为 -Inf
+func logChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
@@ -0,0 +1,139 @@
+package solver
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"minesweeper/board"
+)
+
+// 枚举未知格子中放 mines 个地雷的所有组合，保留与可见数字一致的布局，统计每个格子是地雷的比例
+func bruteForceProbabilities(v *View, mines int) []float64 {
+	t := v.Topology
+	if t == nil {
+		t = board.Square8{}
+	}
+	var unknown []Point
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			if v.At(x, y) == Unknown && board.Exists(t, v.Width, x, y) {
+				unknown = append(unknown, Point{x, y})
+			}
+		}
+	}
+
+	counts := make([]float64, len(v.Cells))
+	total := 0.0
+	mine := make([]bool, len(v.Cells))
+	consistent := func() bool {
+		for y := 0; y < v.Height; y++ {
+			for x := 0; x < v.Width; x++ {
+				n := v.At(x, y)
+				if n == Unknown {
+					continue
+				}
+				for _, p := range v.Neighbors(x, y) {
+					if mine[p.Y*v.Width+p.X] {
+						n--
+					}
+				}
+				if n != 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	var walk func(i, left int)
+	walk = func(i, left int) {
+		if left == 0 {
+			if consistent() {
+				total++
+				for j, m := range mine {
+					if m {
+						counts[j]++
+					}
+				}
+			}
+			return
+		}
+		if len(unknown)-i < left {
+			return
+		}
+		p := unknown[i]
+		mine[p.Y*v.Width+p.X] = true
+		walk(i+1, left-1)
+		mine[p.Y*v.Width+p.X] = false
+		walk(i+1, left)
+	}
+	walk(0, mines)
+	if total == 0 {
+		return nil
+	}
+	for i := range counts {
+		counts[i] /= total
+	}
+	return counts
+}
+
+// 随机布雷后从一个空白格子连锁翻开，得到玩家可见的棋盘
+func randomView(rng *rand.Rand, t board.Topology, w, h, mines int) *View {
+	grid := make([][]bool, h)
+	for y := range grid {
+		grid[y] = make([]bool, w)
+	}
+	for placed := 0; placed < mines; {
+		x, y := rng.IntN(w), rng.IntN(h)
+		if !grid[y][x] {
+			grid[y][x] = true
+			placed++
+		}
+	}
+	counts := board.CountNeighborsOn(t, grid)
+	v := NewView(w, h)
+	v.Topology = t
+	sx, sy := rng.IntN(w), rng.IntN(h)
+	for grid[sy][sx] {
+		sx, sy = rng.IntN(w), rng.IntN(h)
+	}
+	board.FloodFillOn(t, w, h, sx, sy, func(x, y int) bool {
+		if v.At(x, y) != Unknown || grid[y][x] {
+			return false
+		}
+		v.Set(x, y, counts[y][x])
+		return counts[y][x] == 0
+	})
+	return v
+}
+
+func TestProbabilitiesMatchBruteForce(t *testing.T) {
+	topologies := []board.Topology{board.Square8{}, board.Square4{}, board.Torus{}, board.Knight{}}
+	rng := board.NewRand(1)
+	for _, topo := range topologies {
+		for i := 0; i < 40; i++ {
+			const w, h, mines = 5, 4, 5
+			v := randomView(rng, topo, w, h, mines)
+			want := bruteForceProbabilities(v, mines)
+			got := Probabilities(v, mines)
+			if got == nil {
+				t.Fatalf("%s 第 %d 个棋盘: 返回 nil", topo.Name(), i)
+			}
+			for j := range want {
+				if math.Abs(got[j]-want[j]) > 1e-9 {
+					t.Fatalf("%s 第 %d 个棋盘 (%d, %d): 概率 %.6f，穷举为 %.6f",
+						topo.Name(), i, j%w, j/w, got[j], want[j])
+				}
+			}
+		}
+	}
+}
+
+func TestProbabilitiesContradiction(t *testing.T) {
+	v := NewView(3, 1)
+	v.Set(0, 0, 2)
+	v.Set(1, 0, 0)
+	if got := Probabilities(v, 1); got != nil {
+		t.Errorf("自相矛盾的棋盘应返回 nil，得到 %v", got)
+	}
+}
@@ -0,0 +1,302 @@
+// Package solver 根据玩家可见的棋盘信息进行逻辑推理，不依赖地雷的真实位置
+package solver
+
+import "minesweeper/board"
+
+// 可见格子状态：Unknown 表示未翻开，0-8 表示已翻开格子显示的数字
+const Unknown = -1
+
+type Point struct {
+	X, Y int
+}
+
+// 玩家可见的棋盘
+type View struct {
+	Width, Height int
+	Cells         []int          // 行优先存储
+	Topology      board.Topology // 格子的相邻关系，nil 表示周围 8 格相邻的方格棋盘
+}
+
+func NewView(width, height int) *View {
+	cells := make([]int, width*height)
+	for i := range cells {
+		cells[i] = Unknown
+	}
+	return &View{Width: width, Height: height, Cells: cells}
+}
+
+func (v *View) At(x, y int) int {
+	return v.Cells[y*v.Width+x]
+}
+
+func (v *View) Set(x, y, value int) {
+	v.Cells[y*v.Width+x] = value
+}
+
+// 按拓扑相邻的格子
+func (v *View) Neighbors(x, y int) []Point {
+	t := v.Topology
+	if t == nil {
+		t = board.Square8{}
+	}
+	var buf [8][2]int
+	ns := t.Neighbors(buf[:0], v.Width, v.Height, x, y)
+	points := make([]Point, len(ns))
+	for i, p := range ns {
+		points[i] = Point{p[0], p[1]}
+	}
+	return points
+}
+
+// 一个数字格子对周围未知格子的约束：cells 中恰好有 mines 个地雷
+type constraint struct {
+	cells map[Point]bool
+	mines int
+	at    Point // 数字所在的格子
+}
+
+// 推理所用规则的难度
+type Level int
+
+const (
+	LevelTrivial Level = iota // 单个数字的计数
+	LevelSubset               // 两个数字之间的子集关系，如 1-2-1
+)
+
+// 推理结果
+type Result struct {
+	Safe  []Point // 可以确定安全的未翻开格子
+	Mines []Point // 可以确定是地雷的格子
+	Level Level   // 得出结论所需的最高规则难度
+}
+
+// Deduce 反复应用单格规则和子集规则，直到无法得出新结论
+func Deduce(v *View) Result {
+	return DeduceLevel(v, LevelSubset)
+}
+
+// DeduceLevel 只使用不超过 max 难度的规则进行推理，优先使用简单规则
+func DeduceLevel(v *View, max Level) Result {
+	mines := make(map[Point]bool)
+	safe := make(map[Point]bool)
+	level := LevelTrivial
+
+	for {
+		constraints := buildConstraints(v, mines, safe)
+		changed := false
+
+		mark := func(cells map[Point]bool, asMine bool) {
+			for p := range cells {
+				if asMine && !mines[p] {
+					mines[p] = true
+					changed = true
+				} else if !asMine && !safe[p] {
+					safe[p] = true
+					changed = true
+				}
+			}
+		}
+
+		for _, c := range constraints {
+			if c.mines == 0 {
+				mark(c.cells, false)
+			} else if c.mines == len(c.cells) {
+				mark(c.cells, true)
+			}
+		}
+
+		// 子集规则：A ⊂ B 时，B\A 中恰有 B.mines-A.mines 个地雷
+		if !changed && max >= LevelSubset {
+			for i, a := range constraints {
+				for j, b := range constraints {
+					if i == j || len(a.cells) >= len(b.cells) || !subset(a.cells, b.cells) {
+						continue
+					}
+					diff := make(map[Point]bool)
+					for p := range b.cells {
+						if !a.cells[p] {
+							diff[p] = true
+						}
+					}
+					remaining := b.mines - a.mines
+					if remaining == 0 {
+						mark(diff, false)
+					} else if remaining == len(diff) {
+						mark(diff, true)
+					}
+				}
+			}
+			if changed {
+				level = LevelSubset
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	result := Result{Level: level}
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			p := Point{x, y}
+			if safe[p] {
+				result.Safe = append(result.Safe, p)
+			}
+			if mines[p] {
+				result.Mines = append(result.Mines, p)
+			}
+		}
+	}
+	return result
+}
+
+func buildConstraints(v *View, mines, safe map[Point]bool) []constraint {
+	var constraints []constraint
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			n := v.At(x, y)
+			if n == Unknown {
+				continue
+			}
+			c := constraint{cells: make(map[Point]bool), mines: n, at: Point{x, y}}
+			for _, p := range v.Neighbors(x, y) {
+				switch {
+				case mines[p]:
+					c.mines--
+				case v.At(p.X, p.Y) == Unknown && !safe[p]:
+					c.cells[p] = true
+				}
+			}
+			if len(c.cells) > 0 {
+				constraints = append(constraints, c)
+			}
+		}
+	}
+	return constraints
+}
+
+func subset(a, b map[Point]bool) bool {
+	for p := range a {
+		if !b[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// 模拟求解的结果
+type Outcome struct {
+	Solved      bool  // 无需猜测即可翻开所有安全格子
+	MaxLevel    Level // 求解过程中用到的最高规则难度
+	SubsetSteps int   // 需要子集规则才能继续的次数，越多推理链越长
+}
+
+// Play 从 start 开始，只依靠推理求解地雷布局已知的棋盘
+func Play(mines [][]bool, start Point) Outcome {
+	return PlayOn(board.Square8{}, mines, start)
+}
+
+// PlayOn 与 Play 相同，格子按拓扑 t 相邻，t 中不存在的格子不需要翻开
+func PlayOn(t board.Topology, mines [][]bool, start Point) Outcome {
+	height, width := len(mines), len(mines[0])
+	counts := board.CountNeighborsOn(t, mines)
+	safeCells := 0
+	for y := range mines {
+		for x := range mines[y] {
+			if !mines[y][x] && board.Exists(t, width, x, y) {
+				safeCells++
+			}
+		}
+	}
+
+	view := NewView(width, height)
+	view.Topology = t
+	revealed := 0
+	reveal := func(p Point) {
+		board.FloodFillOn(t, width, height, p.X, p.Y, func(x, y int) bool {
+			if view.At(x, y) != Unknown || mines[y][x] {
+				return false
+			}
+			view.Set(x, y, counts[y][x])
+			revealed++
+			return counts[y][x] == 0
+		})
+	}
+
+	var outcome Outcome
+	if mines[start.Y][start.X] || !board.Exists(t, width, start.X, start.Y) {
+		return outcome
+	}
+	reveal(start)
+	for revealed < safeCells {
+		// 先只用简单规则，推不出新结论时才用子集规则，否则难度会按整轮推理中用到的最高规则计算
+		result := DeduceLevel(view, LevelTrivial)
+		if len(result.Safe) == 0 {
+			result = Deduce(view)
+		}
+		if len(result.Safe) == 0 {
+			return outcome
+		}
+		if result.Level > outcome.MaxLevel {
+			outcome.MaxLevel = result.Level
+		}
+		if result.Level == LevelSubset {
+			outcome.SubsetSteps++
+		}
+		for _, p := range result.Safe {
+			reveal(p)
+		}
+	}
+	outcome.Solved = true
+	return outcome
+}
+
+// Hint 是一步推理：根据 Reasons 中的数字可以确定 Cell 是否为地雷
+type Hint struct {
+	Cell    Point
+	Mine    bool
+	Level   Level
+	Reasons []Point // 推理用到的数字格子
+}
+
+// NextHint 找出一步推理，优先使用简单规则，用于逐步演示求解过程。
+// known 为已经确定的地雷，不会再作为结论返回。找不到时 ok 为 false
+func NextHint(v *View, known map[Point]bool) (hint Hint, ok bool) {
+	constraints := buildConstraints(v, known, nil)
+	for _, c := range constraints {
+		if c.mines == 0 || c.mines == len(c.cells) {
+			return Hint{Cell: firstPoint(c.cells), Mine: c.mines > 0, Level: LevelTrivial, Reasons: []Point{c.at}}, true
+		}
+	}
+	for i, a := range constraints {
+		for j, b := range constraints {
+			if i == j || len(a.cells) >= len(b.cells) || !subset(a.cells, b.cells) {
+				continue
+			}
+			diff := make(map[Point]bool)
+			for p := range b.cells {
+				if !a.cells[p] {
+					diff[p] = true
+				}
+			}
+			remaining := b.mines - a.mines
+			if remaining == 0 || remaining == len(diff) {
+				return Hint{Cell: firstPoint(diff), Mine: remaining > 0, Level: LevelSubset, Reasons: []Point{a.at, b.at}}, true
+			}
+		}
+	}
+	return Hint{}, false
+}
+
+// 按行优先顺序取第一个格子，使结果不受 map 遍历顺序影响
+func firstPoint(cells map[Point]bool) Point {
+	var best Point
+	found := false
+	for p := range cells {
+		if !found || p.Y < best.Y || (p.Y == best.Y && p.X < best.X) {
+			best, found = p, true
+		}
+	}
+	return best
+}
@@ -0,0 +1,49 @@
+package main
+
+// 一帧内翻开的格子达到此数量时，用一个层叠的连锁音效代替单独的点击声
+const cascadeThreshold = 8
+
+// 请求播放音效。同一帧内的请求先合并，由 flushSounds 统一播放，
+// 避免连锁翻开或快速操作时叠加大量相同的声音
+func (g *Game) playSound(name string) {
+	g.queueSound(name, 1)
+}
+
+// weight 表示这次请求代表的操作数量，例如连锁翻开的格子数
+func (g *Game) queueSound(name string, weight int) {
+	if weight > 0 {
+		g.soundQueue[name] += weight
+	}
+}
+
+// 开局、翻开、标记和踩雷的音效
+func (g *Game) subscribeSounds(bus *EventBus) {
+	bus.Subscribe(EventGameStarted, func(Event) { g.playSound("click") })
+	bus.Subscribe(EventCellRevealed, func(e Event) {
+		// 连锁翻开的格子越多，点击声的权重越大，踩雷的声音在 EventGameLost 中播放
+		if !g.gameOver {
+			g.queueSound("click", max(len(e.Opened), 1))
+		}
+	})
+	bus.Subscribe(EventFlagToggled, func(Event) { g.playSound("flag") })
+	bus.Subscribe(EventGameLost, func(Event) { g.playSound("explosion") })
+}
+
+func (g *Game) flushSounds() {
+	if len(g.soundQueue) == 0 {
+		return
+	}
+	g.syncSoundPack()
+	if g.soundQueue["click"] >= cascadeThreshold {
+		delete(g.soundQueue, "click")
+		g.soundQueue["cascade"] = 1
+	}
+	for name := range g.soundQueue {
+		if player, ok := g.sounds[name]; ok && !muted {
+			player.SetVolume(globalConfig.Volume)
+			player.Rewind()
+			player.Play()
+		}
+		delete(g.soundQueue, name)
+	}
+}
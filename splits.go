@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 分段计时：记录本局翻开的安全格子首次达到每个百分点的时间，
+// 在 25%、50%、75% 和 100% 时与个人最佳对局比较，差值显示在状态栏。
+// 打开“PB 幽灵”后，状态栏上方的进度条同时标出个人最佳对局在同一时刻的进度
+var splitMarks = []int{25, 50, 75, 100}
+
+// 一个分段与个人最佳的差值，负数表示领先
+type splitResult struct {
+	mark  int
+	delta time.Duration
+}
+
+// 当前分类的个人最佳对局的进度，没有记录时返回 nil
+func (g *Game) bestProgress() []time.Duration {
+	if ds, ok := globalStats.Difficulties[statsKey(g.difficulty, g.variant())]; ok {
+		return ds.BestProgress
+	}
+	return nil
+}
+
+// 每次操作后调用，补齐本局进度并在经过分段时与个人最佳比较
+func (g *Game) trackProgress() {
+	if g.firstClick || g.cellCount() == g.mineCount() {
+		return
+	}
+	pct := g.progress()
+	best := g.bestProgress()
+	for len(g.splits) <= pct {
+		p := len(g.splits)
+		g.splits = append(g.splits, g.elapsedTime)
+		if isSplitMark(p) && p < len(best) {
+			g.lastSplit = &splitResult{mark: p, delta: g.elapsedTime - best[p]}
+		}
+	}
+}
+
+func isSplitMark(p int) bool {
+	for _, m := range splitMarks {
+		if m == p {
+			return true
+		}
+	}
+	return false
+}
+
+// 状态栏中最近一个分段的差值和颜色，领先为绿色，落后为红色
+func (g *Game) splitStatus() (string, color.Color) {
+	s := g.lastSplit
+	clr := color.RGBA{80, 200, 100, 255}
+	if s.delta > 0 {
+		clr = color.RGBA{230, 80, 70, 255}
+	}
+	return fmt.Sprintf("%d%% %+.1fs", s.mark, s.delta.Seconds()), clr
+}
+
+// 个人最佳对局在 elapsed 时的进度（百分比）
+func ghostProgress(best []time.Duration, elapsed time.Duration) int {
+	p := 0
+	for p+1 < len(best) && best[p+1] <= elapsed {
+		p++
+	}
+	return p
+}
+
+// 在状态栏上沿画出本局进度，并用竖线标出个人最佳对局的进度
+func (g *Game) drawGhost(screen *ebiten.Image) {
+	best := g.bestProgress()
+	if !globalConfig.Ghost || len(best) == 0 || g.firstClick || len(g.splits) == 0 {
+		return
+	}
+	w, y := float32(g.viewWidth()), float32(g.viewHeight())
+	mine := float32(len(g.splits)-1) / 100
+	vector.DrawFilledRect(screen, 0, y, w*mine, 2, activeTheme.Text, false)
+
+	ghost := float32(ghostProgress(best, g.elapsedTime)) / 100
+	clr := color.RGBA{120, 200, 255, 200}
+	vector.DrawFilledRect(screen, w*ghost-1, y, 2, 5, clr, false)
+}
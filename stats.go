@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"minesweeper/storage"
+)
+
+// 单个难度的统计数据
+type DifficultyStats struct {
+	Played        int             `json:"played"`
+	Won           int             `json:"won"`
+	BestTime      time.Duration   `json:"best_time"` // 0 表示还没有胜利记录
+	CurrentStreak int             `json:"current_streak"`
+	LongestStreak int             `json:"longest_streak"`
+	BestProgress  []time.Duration `json:"best_progress,omitempty"` // 最佳成绩那局的进度，用于分段比较，见 splits.go
+	Abandoned     int             `json:"abandoned,omitempty"`     // 进行中放弃的局数，同时计入总局数
+}
+
+type Stats struct {
+	Difficulties map[string]*DifficultyStats `json:"difficulties"`
+	EndlessBest  int                         `json:"endless_best"`           // 无尽模式踩雷前翻开最多的格子数
+	Achievements map[string]time.Time        `json:"achievements,omitempty"` // 已解锁的成就及解锁时间，见 achievements.go
+
+	name  string
+	store storage.Storage
+}
+
+var difficultyKeys = map[Difficulty]string{
+	Easy:   "easy",
+	Medium: "medium",
+	Hard:   "hard",
+	Huge:   "huge",
+	Custom: "custom",
+}
+
+func loadStats(store storage.Storage) (*Stats, error) {
+	s := &Stats{Difficulties: make(map[string]*DifficultyStats)}
+
+	s.name = "stats.json"
+	s.store = store
+
+	err := loadFile(s.store, s.name, "stats", s)
+	if s.Difficulties == nil {
+		s.Difficulties = make(map[string]*DifficultyStats)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("读取统计数据失败: %v", err)
+	}
+	return s, nil
+}
+
+func (s *Stats) save() error {
+	if s.name == "" {
+		return nil
+	}
+	return saveFile(s.store, s.name, "stats", s)
+}
+
+// 成绩分类的键，非默认拓扑的对局按拓扑单独记录，如 "easy/knight"
+func statsKey(d Difficulty, variant string) string {
+	if variant == "" {
+		return difficultyKeys[d]
+	}
+	return difficultyKeys[d] + "/" + variant
+}
+
+func (s *Stats) forDifficulty(d Difficulty) *DifficultyStats {
+	return s.forVariant(d, "")
+}
+
+func (s *Stats) forVariant(d Difficulty, variant string) *DifficultyStats {
+	key := statsKey(d, variant)
+	ds, ok := s.Difficulties[key]
+	if !ok {
+		ds = &DifficultyStats{}
+		s.Difficulties[key] = ds
+	}
+	return ds
+}
+
+func (s *Stats) record(e Event) {
+	ds := s.forVariant(e.Difficulty, e.Variant)
+	ds.Played++
+
+	if e.Type == EventGameWon {
+		ds.Won++
+		ds.CurrentStreak++
+		if ds.CurrentStreak > ds.LongestStreak {
+			ds.LongestStreak = ds.CurrentStreak
+		}
+		if ds.BestTime == 0 || e.Elapsed < ds.BestTime {
+			ds.BestTime = e.Elapsed
+			ds.BestProgress = append([]time.Duration(nil), e.Progress...)
+		}
+	} else {
+		ds.CurrentStreak = 0
+	}
+	if e.Type == EventGameAbandoned {
+		ds.Abandoned++
+	}
+}
+
+// 订阅游戏结束事件，每局结束立即保存
+func (s *Stats) subscribe(bus *EventBus) {
+	handler := func(e Event) {
+		s.record(e)
+		s.checkAchievements(e)
+		if err := s.save(); err != nil {
+			slog.Warn("保存统计数据失败", "err", err)
+		}
+	}
+	bus.Subscribe(EventGameWon, handler)
+	bus.Subscribe(EventGameLost, handler)
+	// 放弃的对局计入局数并中断连胜，但不检查成就
+	bus.Subscribe(EventGameAbandoned, func(e Event) {
+		s.record(e)
+		if err := s.save(); err != nil {
+			slog.Warn("保存统计数据失败", "err", err)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 棋盘下方的状态栏分两行：第一行左侧是剩余地雷数和计时，中间是笑脸重启按钮，
+// 右侧是难度和提示按钮；第二行显示对战进度、分段等附加信息和生命。
+// 窗口较窄时先隐藏难度，笑脸按钮让出左侧的位置
+const (
+	statusBarTop  = 4  // 第一行相对棋盘底边的位置
+	statusBarSize = 28 // 第一行的高度，也是笑脸和提示按钮的边长
+	statusInfoTop = 40 // 第二行相对棋盘底边的位置
+	statusIcon    = 14 // 图标的边长
+)
+
+// 每帧按窗口宽度排列状态栏，返回难度文字的位置，不显示时为 -1
+func (g *Game) layoutStatusBar(leftEnd int) int {
+	y := g.viewHeight() + statusBarTop
+	w := g.viewWidth()
+
+	g.hintBtn.X, g.hintBtn.Y, g.hintBtn.W, g.hintBtn.H = w-10-statusBarSize, y, statusBarSize, statusBarSize
+	g.hintBtn.Disabled = !g.radarAllowed() || time.Now().Before(radar.cooldown)
+
+	smileX := (w - statusBarSize) / 2
+	if smileX < leftEnd+8 {
+		smileX = leftEnd + 8
+	}
+	g.smileyBtn.X, g.smileyBtn.Y, g.smileyBtn.W, g.smileyBtn.H = smileX, y, statusBarSize, statusBarSize
+
+	label := tr(difficultyNames[g.difficulty])
+	labelX := g.hintBtn.X - 8 - text.BoundString(g.gameFont, label).Dx()
+	if labelX < smileX+statusBarSize+8 {
+		return -1
+	}
+	return labelX
+}
+
+// 点击笑脸重启，点击提示按钮触发雷达。返回 true 表示点击已被处理
+func (g *Game) updateStatusBar(x, y int) (bool, error) {
+	g.smileyBtn.Hover = g.smileyBtn.Contains(x, y)
+	g.hintBtn.Hover = g.hintBtn.Contains(x, y)
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return false, nil
+	}
+	switch {
+	case g.smileyBtn.Hover:
+		g.playSound("click")
+		return true, g.runCommand("restart")
+	case g.hintBtn.Hover && g.radarAllowed():
+		g.fireRadar()
+		return true, nil
+	}
+	return false, nil
+}
+
+func (g *Game) drawStatusBar(screen *ebiten.Image) {
+	y := g.viewHeight() + statusBarTop
+	baseline := y + 19
+	iconY := float32(y + (statusBarSize-statusIcon)/2)
+
+	// 剩余地雷数，插旗过多时为负数
+	x := 10
+	drawMineIcon(screen, float32(x), iconY, statusIcon)
+	count := strconv.Itoa(g.minesLeft())
+	text.Draw(screen, count, g.gameFont, x+statusIcon+4, baseline, activeTheme.Text)
+	x += statusIcon + 4 + text.BoundString(g.gameFont, count).Dx() + 14
+
+	// 计时，限时模式显示剩余时间，最后几秒变红
+	timeClr := activeTheme.Text
+	elapsed := formatDuration(g.elapsedTime)
+	if g.countdownActive() {
+		elapsed = formatDuration(g.timeLeft() + time.Second - time.Nanosecond)
+		if g.timeLeft() <= countdownWarning {
+			timeClr = color.RGBA{230, 60, 60, 255}
+		}
+	}
+	if g.noFlags {
+		elapsed += "  NF"
+	}
+	drawClockIcon(screen, float32(x), iconY, statusIcon, timeClr)
+	text.Draw(screen, elapsed, g.gameFont, x+statusIcon+4, baseline, timeClr)
+	x += statusIcon + 4 + text.BoundString(g.gameFont, elapsed).Dx()
+
+	if labelX := g.layoutStatusBar(x); labelX >= 0 {
+		text.Draw(screen, tr(difficultyNames[g.difficulty]), g.gameFont, labelX, baseline, activeTheme.Text)
+	}
+	g.drawSmiley(screen)
+	g.drawHintButton(screen)
+}
+
+// 状态栏第二行的附加信息：对战时显示双方进度，其他玩法的状态，
+// 经过分段时显示与个人最佳的差值，否则开局后显示本局的 3BV
+func (g *Game) statusInfo() (string, color.Color) {
+	switch {
+	case g.race != nil:
+		return fmt.Sprintf("%s %d%% : %d%%", tr("进度"), g.race.sentProgress, g.race.opponentProgress), activeTheme.Text
+	case g.coop != nil:
+		return g.coopStatus(), activeTheme.Text
+	case g.arcade != nil:
+		return g.arcadeStatus(), activeTheme.Text
+	case g.bot != nil:
+		return g.botStatus(), activeTheme.Text
+	case g.lastSplit != nil:
+		return g.splitStatus()
+	case g.bbbv > 0:
+		return fmt.Sprintf("3BV: %d", g.bbbv), activeTheme.Text
+	}
+	return "", nil
+}
+
+// 状态栏按钮的底色和边框，与普通按钮一致
+func (g *Game) drawStatusButton(screen *ebiten.Image, btn *Button) {
+	bg := activeTheme.ButtonBg
+	if btn.Hover && !btn.Disabled {
+		bg = activeTheme.ButtonHover
+	}
+	x, y, s := float32(btn.X), float32(btn.Y), float32(btn.W)
+	vector.DrawFilledRect(screen, x, y, s, s, bg, false)
+	vector.StrokeRect(screen, x, y, s, s, 1, activeTheme.ButtonBorder, false)
+}
+
+// 笑脸按钮：按下格子时惊讶，踩雷后晕倒，胜利后戴上墨镜
+func (g *Game) drawSmiley(screen *ebiten.Image) {
+	btn := g.smileyBtn
+	g.drawStatusButton(screen, btn)
+
+	s := float32(btn.W)
+	cx, cy, r := float32(btn.X)+s/2, float32(btn.Y)+s/2, s/2-4
+	ink := color.RGBA{30, 30, 30, 255}
+	vector.DrawFilledCircle(screen, cx, cy, r, color.RGBA{250, 215, 50, 255}, true)
+	vector.StrokeCircle(screen, cx, cy, r, 1, ink, true)
+
+	ex, ey := r*0.38, r*0.3
+	switch {
+	case g.gameOver:
+		for _, dx := range []float32{-ex, ex} {
+			vector.StrokeLine(screen, cx+dx-2, cy-ey-2, cx+dx+2, cy-ey+2, 1, ink, true)
+			vector.StrokeLine(screen, cx+dx-2, cy-ey+2, cx+dx+2, cy-ey-2, 1, ink, true)
+		}
+	case g.won:
+		vector.DrawFilledRect(screen, cx-ex-3, cy-ey-2, 2*ex+6, 4, ink, true)
+	default:
+		vector.DrawFilledCircle(screen, cx-ex, cy-ey, 1.5, ink, true)
+		vector.DrawFilledCircle(screen, cx+ex, cy-ey, 1.5, ink, true)
+	}
+
+	switch {
+	case g.gameOver:
+		drawArc(screen, cx, cy+r*0.75, r*0.4, math.Pi*1.2, math.Pi*1.8, ink)
+	case g.press != nil || g.chordHeld:
+		vector.StrokeCircle(screen, cx, cy+r*0.35, 2.5, 1, ink, true)
+	default:
+		drawArc(screen, cx, cy, r*0.55, math.Pi*0.2, math.Pi*0.8, ink)
+	}
+}
+
+// 提示按钮：灯泡形状，雷达冷却或不可用时为灰色
+func (g *Game) drawHintButton(screen *ebiten.Image) {
+	btn := g.hintBtn
+	g.drawStatusButton(screen, btn)
+
+	s := float32(btn.W)
+	cx, cy := float32(btn.X)+s/2, float32(btn.Y)+s/2-2
+	bulb := color.RGBA{255, 230, 100, 255}
+	if btn.Disabled {
+		bulb = color.RGBA{140, 140, 140, 255}
+	}
+	vector.DrawFilledCircle(screen, cx, cy, s/4, bulb, true)
+	vector.DrawFilledRect(screen, cx-s/8, cy+s/5, s/4, s/8, color.RGBA{110, 110, 110, 255}, false)
+}
+
+// 以 (cx, cy) 为圆心、从角度 from 到 to 画一段圆弧，y 轴向下
+func drawArc(screen *ebiten.Image, cx, cy, r float32, from, to float64, clr color.Color) {
+	const steps = 8
+	px, py := cx+r*float32(math.Cos(from)), cy+r*float32(math.Sin(from))
+	for i := 1; i <= steps; i++ {
+		a := from + (to-from)*float64(i)/steps
+		x, y := cx+r*float32(math.Cos(a)), cy+r*float32(math.Sin(a))
+		vector.StrokeLine(screen, px, py, x, y, 1.5, clr, true)
+		px, py = x, y
+	}
+}
+
+// 地雷图标：黑色圆形加四根尖刺，(x, y) 为左上角
+func drawMineIcon(screen *ebiten.Image, x, y, size float32) {
+	ink := color.RGBA{20, 20, 20, 255}
+	cx, cy := x+size/2, y+size/2
+	vector.DrawFilledCircle(screen, cx, cy, size*0.32, ink, true)
+	vector.StrokeLine(screen, x, cy, x+size, cy, 1.5, ink, true)
+	vector.StrokeLine(screen, cx, y, cx, y+size, 1.5, ink, true)
+	vector.DrawFilledCircle(screen, cx-size*0.1, cy-size*0.1, size*0.07, color.RGBA{230, 230, 230, 255}, true)
+}
+
+// 时钟图标：圆形表盘加两根指针
+func drawClockIcon(screen *ebiten.Image, x, y, size float32, clr color.Color) {
+	cx, cy := x+size/2, y+size/2
+	vector.StrokeCircle(screen, cx, cy, size/2-1, 1.5, clr, true)
+	vector.StrokeLine(screen, cx, cy, cx, cy-size*0.32, 1.5, clr, true)
+	vector.StrokeLine(screen, cx, cy, cx+size*0.25, cy, 1.5, clr, true)
+}
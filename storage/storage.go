@@ -0,0 +1,147 @@
+// Package storage 定义存档读写的统一接口，配置、统计等数据都通过它持久化
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Storage 按名称读写整个文件。名称是相对路径，例如 "stats.json"。
+// 文件不存在时 Read 返回的错误满足 errors.Is(err, fs.ErrNotExist)。
+type Storage interface {
+	Read(name string) ([]byte, error)
+	// Write 原子地替换文件内容，中途失败时旧内容保持不变
+	Write(name string, data []byte) error
+	// Remove 删除文件，文件不存在时不报错
+	Remove(name string) error
+}
+
+// Dir 将文件保存在本地磁盘的目录中
+type Dir string
+
+func (d Dir) path(name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("无效的文件名: %q", name)
+	}
+	return filepath.Join(string(d), filepath.FromSlash(name)), nil
+}
+
+func (d Dir) Read(name string) ([]byte, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (d Dir) Write(name string, data []byte) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
+
+func (d Dir) Remove(name string) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// WriteFileAtomic 先写入同目录下的临时文件再重命名，避免写到一半崩溃导致文件损坏
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("同步临时文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("关闭临时文件失败: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("替换文件失败: %v", err)
+	}
+	return nil
+}
+
+// Memory 将文件保存在内存中，用于测试或无法访问磁盘的环境
+type Memory struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string][]byte)}
+}
+
+func (m *Memory) Read(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *Memory) Write(name string, data []byte) error {
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("无效的文件名: %q", name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *Memory) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, name)
+	return nil
+}
+
+// Names 返回所有文件名，按字母顺序排列
+func (m *Memory) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 确保实现了接口
+var (
+	_ Storage = Dir("")
+	_ Storage = (*Memory)(nil)
+	_ Storage = (*Sync)(nil)
+	_ Remote  = (*HTTPRemote)(nil)
+)
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 所有实现都应满足的行为
+func testStorage(t *testing.T, s Storage) {
+	if _, err := s.Read("missing.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("读取不存在的文件返回 %v，期望 ErrNotExist", err)
+	}
+
+	if err := s.Write("stats.json", []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write("stats.json", []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Read("stats.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte("second")) {
+		t.Errorf("读取到 %q，期望 %q", data, "second")
+	}
+
+	// 嵌套目录
+	if err := s.Write("profiles/alice/config.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.Read("profiles/alice/config.json"); err != nil || string(data) != "{}" {
+		t.Errorf("读取嵌套文件得到 %q, %v", data, err)
+	}
+
+	if err := s.Remove("stats.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("stats.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("删除后读取返回 %v，期望 ErrNotExist", err)
+	}
+	if err := s.Remove("stats.json"); err != nil {
+		t.Errorf("删除不存在的文件返回 %v", err)
+	}
+
+	for _, name := range []string{"", "../escape.json", "/abs.json", "."} {
+		if err := s.Write(name, []byte("x")); err == nil {
+			t.Errorf("写入无效的文件名 %q 没有报错", name)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStorage(t, NewMemory())
+}
+
+func TestMemoryCopiesData(t *testing.T) {
+	m := NewMemory()
+	data := []byte("abc")
+	m.Write("a", data)
+	data[0] = 'x'
+	got, _ := m.Read("a")
+	got[1] = 'y'
+	if again, _ := m.Read("a"); string(again) != "abc" {
+		t.Errorf("内存中的数据被外部修改: %q", again)
+	}
+}
+
+func TestDir(t *testing.T) {
+	testStorage(t, Dir(t.TempDir()))
+}
+
+func TestDirLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	d := Dir(dir)
+	for i := 0; i < 3; i++ {
+		if err := d.Write("config.json", []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "config.json" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("目录中的文件: %v，期望只有 config.json", names)
+	}
+}
+
+func TestDirCreatesMissingDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "minesweeper", "profiles", "bob")
+	if err := Dir(dir).Write("stats.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stats.json")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Remote 是同步服务器上保存的文件，名称与 Storage 相同。
+// 文件不存在时 Get 返回的错误满足 errors.Is(err, fs.ErrNotExist)，Delete 不报错
+type Remote interface {
+	Get(name string) ([]byte, error)
+	Put(name string, data []byte) error
+	Delete(name string) error
+}
+
+// Sync 以本地存储为准，把写入和删除在后台同步到远端，供多台设备共用存档。
+// 本地没有的文件从远端取回并缓存到本地；同步失败的文件在下次写入时重试，
+// 远端无法连接不影响本地存档
+type Sync struct {
+	local  Storage
+	remote Remote
+
+	mu      sync.Mutex
+	pending map[string]int // 尚未同步到远端的文件及其修改次数，同步期间再次修改的不会被当作已同步
+	flushMu sync.Mutex     // 同一时间只有一次同步
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewSync 返回同步到 remote 的存储，并启动后台同步，不再使用时调用 Close
+func NewSync(local Storage, remote Remote) *Sync {
+	s := &Sync{
+		local:   local,
+		remote:  remote,
+		pending: make(map[string]int),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *Sync) run() {
+	defer close(s.stopped)
+	for {
+		select {
+		case <-s.wake:
+			s.Flush()
+		case <-s.stop:
+			s.Flush()
+			return
+		}
+	}
+}
+
+// Close 同步剩余的文件后停止后台同步。仍未同步的文件不会保存，下次修改时再上传
+func (s *Sync) Close() error {
+	close(s.stop)
+	<-s.stopped
+	if names := s.Pending(); len(names) > 0 {
+		return fmt.Errorf("%d 个文件未能同步", len(names))
+	}
+	return nil
+}
+
+func (s *Sync) Read(name string) ([]byte, error) {
+	data, err := s.local.Read(name)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return data, err
+	}
+	// 本地已删除、尚未同步的文件不应从远端恢复
+	s.mu.Lock()
+	_, deleted := s.pending[name]
+	s.mu.Unlock()
+	if deleted {
+		return nil, err
+	}
+	// 远端也没有或无法连接时按本地不存在处理，调用方使用默认值
+	data, rerr := s.remote.Get(name)
+	if rerr != nil {
+		return nil, err
+	}
+	if err := s.local.Write(name, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *Sync) Write(name string, data []byte) error {
+	if err := s.local.Write(name, data); err != nil {
+		return err
+	}
+	s.markPending(name)
+	return nil
+}
+
+func (s *Sync) Remove(name string) error {
+	if err := s.local.Remove(name); err != nil {
+		return err
+	}
+	s.markPending(name)
+	return nil
+}
+
+func (s *Sync) markPending(name string) {
+	s.mu.Lock()
+	s.pending[name]++
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pending 返回尚未同步到远端的文件名，按字母顺序排列
+func (s *Sync) Pending() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.pending))
+	for name := range s.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Flush 立即把等待中的文件同步到远端，上传本地的最新内容，本地已删除的从远端删除。
+// 遇到错误时停止，避免服务器无法连接时逐个等待超时，剩余的文件留待下次重试
+func (s *Sync) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	changes := make(map[string]int, len(s.pending))
+	names := make([]string, 0, len(s.pending))
+	for name, n := range s.pending {
+		changes[name] = n
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := s.push(name); err != nil {
+			return fmt.Errorf("同步 %s 失败: %v", name, err)
+		}
+		s.mu.Lock()
+		if s.pending[name] == changes[name] {
+			delete(s.pending, name)
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (s *Sync) push(name string) error {
+	data, err := s.local.Read(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s.remote.Delete(name)
+	}
+	if err != nil {
+		return err
+	}
+	return s.remote.Put(name, data)
+}
+
+// HTTPRemote 通过 HTTP 访问同步服务器：文件位于 URL/files/<名称>，
+// 用 GET、PUT 和 DELETE 读取、替换和删除
+type HTTPRemote struct {
+	URL    string
+	Client *http.Client // 为空时使用 10 秒超时的默认客户端
+}
+
+var defaultSyncClient = &http.Client{Timeout: 10 * time.Second}
+
+func (r *HTTPRemote) do(method, name string, body io.Reader) (*http.Response, error) {
+	u := strings.TrimRight(r.URL, "/") + "/files/" + (&url.URL{Path: name}).EscapedPath()
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	client := r.Client
+	if client == nil {
+		client = defaultSyncClient
+	}
+	return client.Do(req)
+}
+
+func (r *HTTPRemote) Get(name string) ([]byte, error) {
+	resp, err := r.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("同步服务器返回 %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (r *HTTPRemote) Put(name string, data []byte) error {
+	resp, err := r.do(http.MethodPut, name, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("同步服务器返回 %s", resp.Status)
+	}
+	return nil
+}
+
+func (r *HTTPRemote) Delete(name string) error {
+	resp, err := r.do(http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("同步服务器返回 %s", resp.Status)
+	}
+	return nil
+}
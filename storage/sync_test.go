@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 保存在内存中的远端，offline 时所有操作失败
+type memoryRemote struct {
+	mu      sync.Mutex
+	files   *Memory
+	offline bool
+}
+
+var errOffline = errors.New("无法连接")
+
+func newMemoryRemote() *memoryRemote {
+	return &memoryRemote{files: NewMemory()}
+}
+
+func (r *memoryRemote) setOffline(offline bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offline = offline
+}
+
+func (r *memoryRemote) check() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.offline {
+		return errOffline
+	}
+	return nil
+}
+
+func (r *memoryRemote) Get(name string) ([]byte, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	return r.files.Read(name)
+}
+
+func (r *memoryRemote) Put(name string, data []byte) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	return r.files.Write(name, data)
+}
+
+func (r *memoryRemote) Delete(name string) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	return r.files.Remove(name)
+}
+
+func TestSync(t *testing.T) {
+	s := NewSync(NewMemory(), newMemoryRemote())
+	defer s.Close()
+	testStorage(t, s)
+}
+
+func TestSyncUploadsAndDeletes(t *testing.T) {
+	remote := newMemoryRemote()
+	s := NewSync(NewMemory(), remote)
+	defer s.Close()
+
+	if err := s.Write("stats.json", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := remote.files.Read("stats.json"); err != nil || string(data) != "1" {
+		t.Fatalf("远端得到 %q, %v", data, err)
+	}
+
+	if err := s.Remove("stats.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if names := remote.files.Names(); len(names) != 0 {
+		t.Errorf("删除后远端仍有 %v", names)
+	}
+}
+
+func TestSyncRetriesWhenOffline(t *testing.T) {
+	remote := newMemoryRemote()
+	remote.setOffline(true)
+	s := NewSync(NewMemory(), remote)
+	defer s.Close()
+
+	// 远端无法连接时本地照常保存
+	if err := s.Write("stats.json", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.Read("stats.json"); err != nil || string(data) != "1" {
+		t.Fatalf("读取到 %q, %v", data, err)
+	}
+	if err := s.Flush(); err == nil {
+		t.Fatal("远端无法连接时 Flush 应返回错误")
+	}
+	if got := s.Pending(); len(got) != 1 || got[0] != "stats.json" {
+		t.Fatalf("等待同步的文件为 %v", got)
+	}
+
+	remote.setOffline(false)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Pending(); len(got) != 0 {
+		t.Errorf("重试后仍有 %v 等待同步", got)
+	}
+	if data, err := remote.files.Read("stats.json"); err != nil || string(data) != "1" {
+		t.Errorf("远端得到 %q, %v", data, err)
+	}
+}
+
+func TestSyncReadsFromRemote(t *testing.T) {
+	remote := newMemoryRemote()
+	remote.files.Write("stats.json", []byte("remote"))
+	local := NewMemory()
+	s := NewSync(local, remote)
+	defer s.Close()
+
+	if data, err := s.Read("stats.json"); err != nil || string(data) != "remote" {
+		t.Fatalf("读取到 %q, %v", data, err)
+	}
+	// 取回的文件缓存在本地
+	if data, err := local.Read("stats.json"); err != nil || string(data) != "remote" {
+		t.Errorf("本地得到 %q, %v", data, err)
+	}
+
+	// 本地删除后，即使尚未同步也不从远端恢复
+	remote.setOffline(true)
+	s.Remove("stats.json")
+	remote.setOffline(false)
+	if _, err := s.Read("stats.json"); err == nil {
+		t.Error("本地已删除的文件被远端恢复")
+	}
+}
+
+func TestHTTPRemote(t *testing.T) {
+	files := NewMemory()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutPrefix(r.URL.Path, "/files/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			data, err := files.Read(name)
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			files.Write(name, data)
+		case http.MethodDelete:
+			files.Remove(name)
+		}
+	}))
+	defer srv.Close()
+
+	r := &HTTPRemote{URL: srv.URL + "/"}
+	if _, err := r.Get("profiles/2/stats.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("读取不存在的文件返回 %v，期望 ErrNotExist", err)
+	}
+	if err := r.Put("profiles/2/stats.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := r.Get("profiles/2/stats.json"); err != nil || string(data) != "{}" {
+		t.Fatalf("读取到 %q, %v", data, err)
+	}
+	if err := r.Delete("profiles/2/stats.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete("profiles/2/stats.json"); err != nil {
+		t.Errorf("删除不存在的文件返回 %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"minesweeper/storage"
+)
+
+// 提交失败后的重试间隔：从 submitBackoff 开始每次翻倍，最多 submitMaxBackoff
+const (
+	submitBackoff    = 30 * time.Second
+	submitMaxBackoff = 6 * time.Hour
+	submitInterval   = 2 * time.Second // 两次请求之间的最小间隔，避免一次性发送大量积压
+)
+
+// 待提交到排行榜的挑战成绩
+type submission struct {
+	ID         string        `json:"id"` // 幂等键，重复提交时服务器据此去重
+	Server     string        `json:"server"`
+	Player     string        `json:"player"`
+	Kind       string        `json:"kind"`
+	Period     string        `json:"period"`
+	Token      string        `json:"token,omitempty"`
+	Difficulty string        `json:"difficulty"`
+	Seed       int64         `json:"seed"`
+	Layout     int           `json:"layout"` // 布雷算法的版本，服务器按同一版本重放验证
+	StartX     int           `json:"start_x"`
+	StartY     int           `json:"start_y"`
+	Time       time.Duration `json:"time"`
+	Moves      [][3]int      `json:"moves"` // 操作序列 [类型, x, y]，供服务器重放验证
+	WonAt      time.Time     `json:"won_at"`
+
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// 提交队列保存在磁盘上，离线时的成绩在之后启动时继续提交
+type SubmissionQueue struct {
+	Pending []*submission `json:"pending"`
+
+	name  string
+	store storage.Storage
+	mu    sync.Mutex
+	wake  chan struct{}
+	stop  chan struct{}
+}
+
+var globalSubmissions = &SubmissionQueue{}
+
+var submitClient = &http.Client{Timeout: 10 * time.Second}
+
+func loadSubmissionQueue(store storage.Storage) (*SubmissionQueue, error) {
+	q := &SubmissionQueue{wake: make(chan struct{}, 1), stop: make(chan struct{})}
+
+	q.name = "submissions.json"
+	q.store = store
+
+	err := loadFile(q.store, q.name, "submissions", q)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return q, fmt.Errorf("读取待提交成绩失败: %v", err)
+	}
+	return q, nil
+}
+
+// 调用方需持有 q.mu
+func (q *SubmissionQueue) save() error {
+	if q.name == "" {
+		return nil
+	}
+	return saveFile(q.store, q.name, "submissions", q)
+}
+
+// 挑战获胜时加入队列，只有配置了服务器时才需要提交
+func (q *SubmissionQueue) subscribe(bus *EventBus) {
+	bus.Subscribe(EventGameWon, func(e Event) {
+		c := e.Challenge
+		if c == nil || globalConfig.ChallengeServer == "" {
+			return
+		}
+		id, err := newSubmissionID()
+		if err != nil {
+			slog.Warn("生成提交编号失败", "err", err)
+			return
+		}
+		s := &submission{
+			ID:         id,
+			Server:     globalConfig.ChallengeServer,
+			Player:     playerName(),
+			Kind:       c.Kind,
+			Period:     c.Period,
+			Token:      c.Token,
+			Difficulty: c.Difficulty,
+			Seed:       c.Seed,
+			Layout:     c.Layout,
+			StartX:     c.StartX,
+			StartY:     c.StartY,
+			Time:       e.Elapsed,
+			WonAt:      time.Now(),
+		}
+		for _, m := range e.Moves {
+			s.Moves = append(s.Moves, [3]int{int(m.kind), m.x, m.y})
+		}
+
+		q.mu.Lock()
+		q.Pending = append(q.Pending, s)
+		if err := q.save(); err != nil {
+			slog.Warn("保存待提交成绩失败", "err", err)
+		}
+		q.mu.Unlock()
+
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// 排行榜上使用的名字
+func playerName() string {
+	if globalConfig.PlayerName != "" {
+		return globalConfig.PlayerName
+	}
+	if u, err := user.Current(); err == nil {
+		// Windows 上的用户名带有域名前缀
+		name := u.Username
+		if i := strings.LastIndex(name, `\`); i >= 0 {
+			name = name[i+1:]
+		}
+		return name
+	}
+	return "player"
+}
+
+func newSubmissionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// 在后台提交队列中到期的成绩，直到 close 被调用
+func (q *SubmissionQueue) start() {
+	go func() {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-q.stop:
+				return
+			case <-q.wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+			timer.Reset(q.flush())
+		}
+	}()
+}
+
+func (q *SubmissionQueue) close() {
+	if q.stop != nil {
+		close(q.stop)
+	}
+}
+
+// 提交所有到期的成绩，返回距离下一次需要重试的时间
+func (q *SubmissionQueue) flush() time.Duration {
+	q.mu.Lock()
+	var due []*submission
+	for _, s := range q.Pending {
+		if !time.Now().Before(s.NextAttempt) {
+			due = append(due, s)
+		}
+	}
+	q.mu.Unlock()
+
+	for i, s := range due {
+		if i > 0 {
+			select {
+			case <-time.After(submitInterval):
+			case <-q.stop:
+				return submitMaxBackoff
+			}
+		}
+		err := postSubmission(s)
+
+		q.mu.Lock()
+		var permanent *rejectedError
+		switch {
+		case err == nil:
+			q.remove(s)
+		case errors.As(err, &permanent):
+			slog.Warn("排行榜拒绝了成绩，不再重试", "err", err)
+			q.remove(s)
+		default:
+			s.Attempts++
+			backoff := submitBackoff << (s.Attempts - 1)
+			if backoff > submitMaxBackoff || backoff <= 0 {
+				backoff = submitMaxBackoff
+			}
+			s.NextAttempt = time.Now().Add(backoff)
+		}
+		if err := q.save(); err != nil {
+			slog.Warn("保存待提交成绩失败", "err", err)
+		}
+		q.mu.Unlock()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	next := submitMaxBackoff
+	for _, s := range q.Pending {
+		if d := time.Until(s.NextAttempt); d < next {
+			next = d
+		}
+	}
+	if next < 0 {
+		next = 0
+	}
+	return next
+}
+
+// 调用方需持有 q.mu
+func (q *SubmissionQueue) remove(s *submission) {
+	for i, p := range q.Pending {
+		if p == s {
+			q.Pending = append(q.Pending[:i], q.Pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// 服务器明确拒绝的成绩，重试也不会成功
+type rejectedError struct {
+	status string
+}
+
+func (e *rejectedError) Error() string {
+	return "服务器返回 " + e.status
+}
+
+// 服务器以 Idempotency-Key 去重：同一成绩重复提交返回 200 或 409，都视为成功
+func postSubmission(s *submission) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return &rejectedError{status: err.Error()}
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(s.Server, "/")+"/api/scores", bytes.NewReader(body))
+	if err != nil {
+		return &rejectedError{status: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", s.ID)
+
+	resp, err := submitClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300, resp.StatusCode == http.StatusConflict:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("服务器返回 %s", resp.Status)
+	default:
+		return &rejectedError{status: resp.Status}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"image/color"
+	"time"
+)
+
+// 主题配色
+type Theme struct {
+	Name         string
+	Title        string     // 显示名称
+	Background   color.RGBA // 窗口背景（状态栏区域）
+	TileTint     color.RGBA // 格子贴图着色，白色表示不改变原图
+	Text         color.RGBA
+	ButtonBg     color.RGBA
+	ButtonHover  color.RGBA
+	ButtonBorder color.RGBA
+	Overlay      color.RGBA // 结束画面遮罩
+	MenuOverlay  color.RGBA // 菜单遮罩
+	Unlock       string     // 解锁需要的成就，为空表示默认可用，见 cosmetics.go
+}
+
+var themes = map[string]Theme{
+	"default": {
+		Name:         "default",
+		Title:        "默认",
+		Background:   color.RGBA{0, 0, 0, 255},
+		TileTint:     color.RGBA{255, 255, 255, 255},
+		Text:         color.RGBA{255, 255, 255, 255},
+		ButtonBg:     color.RGBA{60, 60, 60, 255},
+		ButtonHover:  color.RGBA{80, 80, 80, 255},
+		ButtonBorder: color.RGBA{120, 120, 120, 255},
+		Overlay:      color.RGBA{0, 0, 0, 180},
+		MenuOverlay:  color.RGBA{0, 0, 0, 200},
+	},
+	"spring": {
+		Name:         "spring",
+		Title:        "春季",
+		Background:   color.RGBA{34, 68, 40, 255},
+		TileTint:     color.RGBA{225, 255, 225, 255},
+		Text:         color.RGBA{255, 235, 245, 255},
+		ButtonBg:     color.RGBA{70, 120, 75, 255},
+		ButtonHover:  color.RGBA{95, 150, 100, 255},
+		ButtonBorder: color.RGBA{240, 170, 200, 255},
+		Overlay:      color.RGBA{20, 50, 25, 180},
+		MenuOverlay:  color.RGBA{20, 50, 25, 200},
+	},
+	"winter": {
+		Name:         "winter",
+		Title:        "冬季",
+		Background:   color.RGBA{20, 36, 64, 255},
+		TileTint:     color.RGBA{220, 235, 255, 255},
+		Text:         color.RGBA{235, 245, 255, 255},
+		ButtonBg:     color.RGBA{50, 75, 115, 255},
+		ButtonHover:  color.RGBA{70, 100, 145, 255},
+		ButtonBorder: color.RGBA{200, 225, 255, 255},
+		Overlay:      color.RGBA{10, 20, 45, 180},
+		MenuOverlay:  color.RGBA{10, 20, 45, 200},
+	},
+	"midnight": {
+		Name:         "midnight",
+		Title:        "深夜",
+		Background:   color.RGBA{12, 10, 28, 255},
+		TileTint:     color.RGBA{190, 185, 235, 255},
+		Text:         color.RGBA{215, 210, 255, 255},
+		ButtonBg:     color.RGBA{40, 34, 80, 255},
+		ButtonHover:  color.RGBA{60, 52, 110, 255},
+		ButtonBorder: color.RGBA{150, 135, 230, 255},
+		Overlay:      color.RGBA{8, 6, 24, 180},
+		MenuOverlay:  color.RGBA{8, 6, 24, 200},
+		Unlock:       "hard-100",
+	},
+	"gold": {
+		Name:         "gold",
+		Title:        "鎏金",
+		Background:   color.RGBA{48, 34, 8, 255},
+		TileTint:     color.RGBA{255, 235, 180, 255},
+		Text:         color.RGBA{255, 225, 140, 255},
+		ButtonBg:     color.RGBA{100, 74, 20, 255},
+		ButtonHover:  color.RGBA{130, 98, 30, 255},
+		ButtonBorder: color.RGBA{240, 200, 90, 255},
+		Overlay:      color.RGBA{40, 28, 5, 180},
+		MenuOverlay:  color.RGBA{40, 28, 5, 200},
+		Unlock:       "games-100",
+	},
+}
+
+// 当前使用的主题，启动时由配置决定
+var activeTheme = themes["default"]
+
+// 降低颜色不透明度，用于禁用状态
+func dim(c color.RGBA) color.RGBA {
+	return color.RGBA{c.R / 3, c.G / 3, c.B / 3, c.A / 3}
+}
+
+// 季节主题的生效日期区间（含首尾，允许跨年）
+type seasonalSchedule struct {
+	Theme      string
+	StartMonth time.Month
+	StartDay   int
+	EndMonth   time.Month
+	EndDay     int
+}
+
+var seasonalSchedules = []seasonalSchedule{
+	{"spring", time.March, 1, time.May, 31},
+	{"winter", time.December, 1, time.February, 29},
+}
+
+// 返回指定日期应自动启用的季节主题
+func scheduledTheme(now time.Time) (string, bool) {
+	md := int(now.Month())*100 + now.Day()
+	for _, s := range seasonalSchedules {
+		start := int(s.StartMonth)*100 + s.StartDay
+		end := int(s.EndMonth)*100 + s.EndDay
+		if start <= end {
+			if md >= start && md <= end {
+				return s.Theme, true
+			}
+		} else if md >= start || md <= end {
+			return s.Theme, true
+		}
+	}
+	return "", false
+}
+
+// 根据配置和当前日期选择主题，尚未解锁的主题不生效
+func selectTheme(cfg *Config, now time.Time) Theme {
+	name := cfg.Theme
+	if cfg.SeasonalThemes {
+		if seasonal, ok := scheduledTheme(now); ok {
+			name = seasonal
+		}
+	}
+	if theme, ok := themes[name]; ok && cosmeticUnlocked(theme.Unlock) {
+		return theme
+	}
+	return themes["default"]
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"minesweeper/board"
+)
+
+// 难度按钮左侧的棋盘缩略图：按实际的长宽比例画出棋盘，地雷画成深色的点，
+// 让新玩家一眼看出 9x9 的 10 颗雷和 30x16 的 99 颗雷差在哪里
+const thumbnailSize = 32 // 缩略图长边的像素
+
+// 每个难度的缩略图只生成一次，每个格子一个像素
+var thumbnails = make(map[Difficulty]*ebiten.Image)
+
+func thumbnail(d Difficulty) *ebiten.Image {
+	if img, ok := thumbnails[d]; ok {
+		return img
+	}
+	config := difficultySettings[d]
+	// 固定种子，每次打开菜单看到的都一样
+	mines := board.PlaceMines(config.GridWidth, config.GridHeight, config.MineCount, int64(d)+1, -1, -1)
+	pix := image.NewRGBA(image.Rect(0, 0, config.GridWidth, config.GridHeight))
+	for y, row := range mines {
+		for x, mine := range row {
+			clr := color.RGBA{200, 200, 200, 255}
+			if mine {
+				clr = color.RGBA{30, 30, 30, 255}
+			}
+			pix.SetRGBA(x, y, clr)
+		}
+	}
+	img := ebiten.NewImageFromImage(pix)
+	thumbnails[d] = img
+	return img
+}
+
+// 在难度按钮内左侧居中画出缩略图
+func drawThumbnail(screen *ebiten.Image, btn *Button) {
+	img := thumbnail(btn.Difficulty)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	scale := float64(thumbnailSize) / math.Max(float64(w), float64(h))
+	sw, sh := float64(w)*scale, float64(h)*scale
+	x := float64(btn.X) + 4 + (thumbnailSize-sw)/2
+	y := float64(btn.Y) + (float64(btn.H)-sh)/2
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	// 放大时保持像素清晰，缩小时平均相邻的格子，地雷密度看起来更均匀
+	if scale < 1 {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(img, op)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(sw), float32(sh), 1, activeTheme.ButtonBorder, false)
+}
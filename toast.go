@@ -0,0 +1,39 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+)
+
+// 屏幕底部短暂显示的提示，全局保存以便重启后依然可见
+var toast struct {
+	msg   string
+	until time.Time
+}
+
+func showToast(msg string) {
+	toast.msg = msg
+	toast.until = time.Now().Add(2 * time.Second)
+}
+
+func drawToast(screen *ebiten.Image, face font.Face) {
+	if toast.msg == "" || time.Now().After(toast.until) {
+		return
+	}
+
+	bounds, _ := font.BoundString(face, toast.msg)
+	width := (bounds.Max.X - bounds.Min.X).Ceil()
+	height := (bounds.Max.Y - bounds.Min.Y).Ceil()
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x := (sw - width) / 2
+	y := sh - 100
+
+	vector.DrawFilledRect(screen, float32(x-10), float32(y-height-6), float32(width+20), float32(height+14),
+		color.RGBA{0, 0, 0, 200}, false)
+	text.Draw(screen, toast.msg, face, x, y, color.White)
+}
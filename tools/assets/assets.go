@@ -1,133 +1,326 @@
-package assets
-
-import (
-	"image"
-	"image/color"
-	"image/draw"
-	"image/png"
-	"os"
-	"path/filepath"
-)
-
-const (
-	tileSize = 32
-)
-
-// GenerateImages 生成所有图片资源
-func GenerateImages() error {
-	// 创建目录
-	os.MkdirAll("assets/images", 0755)
-
-	// 生成所有图片
-	if err := generateTile(); err != nil {
-		return err
-	}
-	if err := generateRevealed(); err != nil {
-		return err
-	}
-	if err := generateMine(); err != nil {
-		return err
-	}
-	if err := generateFlag(); err != nil {
-		return err
-	}
-	return nil
-}
-
-func generateTile() error {
-	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-
-	// 填充浅灰色背景
-	bgColor := color.RGBA{200, 200, 200, 255}
-	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
-
-	// 绘制3D效果的边框
-	lightColor := color.RGBA{230, 230, 230, 255}
-	darkColor := color.RGBA{160, 160, 160, 255}
-
-	// 上边和左边（亮色）
-	for i := 0; i < tileSize; i++ {
-		img.Set(i, 0, lightColor) // 上边
-		img.Set(0, i, lightColor) // 左边
-	}
-
-	// 下边和右边（暗色）
-	for i := 0; i < tileSize; i++ {
-		img.Set(i, tileSize-1, darkColor) // 下边
-		img.Set(tileSize-1, i, darkColor) // 右边
-	}
-
-	return saveImage(img, "tile.png")
-}
-
-func generateRevealed() error {
-	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-
-	// 填充深灰色背景
-	bgColor := color.RGBA{180, 180, 180, 255}
-	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
-
-	return saveImage(img, "revealed.png")
-}
-
-func generateMine() error {
-	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-
-	// 填充深灰色背景
-	bgColor := color.RGBA{180, 180, 180, 255}
-	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
-
-	// 绘制地雷（黑色圆形）
-	mineColor := color.RGBA{0, 0, 0, 255}
-	center := tileSize / 2
-	radius := tileSize / 4
-
-	for y := 0; y < tileSize; y++ {
-		for x := 0; x < tileSize; x++ {
-			dx := float64(x - center)
-			dy := float64(y - center)
-			if dx*dx+dy*dy <= float64(radius*radius) {
-				img.Set(x, y, mineColor)
-			}
-		}
-	}
-
-	return saveImage(img, "mine.png")
-}
-
-func generateFlag() error {
-	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-
-	// 填充浅灰色背景
-	bgColor := color.RGBA{200, 200, 200, 255}
-	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
-
-	// 绘制旗杆（深灰色）
-	poleColor := color.RGBA{80, 80, 80, 255}
-	for y := tileSize / 4; y < tileSize*3/4; y++ {
-		img.Set(tileSize/2, y, poleColor)
-	}
-
-	// 绘制旗帜（红色三角形）
-	flagColor := color.RGBA{255, 0, 0, 255}
-	for y := tileSize / 4; y < tileSize/2; y++ {
-		for x := tileSize / 2; x < tileSize*3/4; x++ {
-			if float64(x-tileSize/2) < float64(y-tileSize/4)*1.5 {
-				img.Set(x, y, flagColor)
-			}
-		}
-	}
-
-	return saveImage(img, "flag.png")
-}
-
-func saveImage(img *image.RGBA, filename string) error {
-	fullPath := filepath.Join("assets", "images", filename)
-	f, err := os.Create(fullPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return png.Encode(f, img)
-}
+package assets
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// 每种贴图生成的尺寸，渲染时选择最接近实际格子大小的一张，避免缩放模糊
+var spriteSizes = []int{16, 24, 32, 48, 64, 96, 128} // 64 及以上用于高分辨率屏幕
+
+// GenerateImages 生成所有图片资源
+func GenerateImages() error {
+	// 创建目录
+	os.MkdirAll("assets/images", 0755)
+
+	sprites := []struct {
+		name string
+		draw func(tileSize int) *image.RGBA
+	}{
+		{"tile", drawTile},
+		{"revealed", drawRevealed},
+		{"mine", drawMine},
+		{"flag", drawFlag},
+		{"misflag", drawMisflag},
+		{"exploded", drawExploded},
+	}
+
+	// 生成所有图片，每种贴图另有一张六边形版本供六边形模式使用
+	for _, size := range spriteSizes {
+		for _, sprite := range sprites {
+			img := sprite.draw(size)
+			if err := saveImage(img, sprite.name, size); err != nil {
+				return err
+			}
+			if err := saveImage(hexMask(img), "hex"+sprite.name, size); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 多帧贴图：各帧从左到右排成一行保存为一张图片，游戏加载时按高度切分成帧
+	strips := []struct {
+		name   string
+		frames int
+		draw   func(tileSize, frame, frames int) *image.RGBA
+	}{
+		{"fuse", 4, drawFuse},
+		{"flagwave", 4, drawFlagWave},
+		{"explosion", 6, drawExplosionFrame},
+	}
+	for _, size := range spriteSizes {
+		for _, strip := range strips {
+			img := image.NewRGBA(image.Rect(0, 0, size*strip.frames, size))
+			for f := 0; f < strip.frames; f++ {
+				rect := image.Rect(f*size, 0, (f+1)*size, size)
+				draw.Draw(img, rect, strip.draw(size, f, strip.frames), image.Point{}, draw.Src)
+			}
+			if err := saveImage(img, strip.name, size); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 把方形贴图裁成内切于正方形的尖顶六边形，六边形以外透明，边缘画一圈深色描边
+func hexMask(src *image.RGBA) *image.RGBA {
+	size := src.Bounds().Dx()
+	img := image.NewRGBA(src.Bounds())
+	edge := color.RGBA{120, 120, 120, 255}
+	radius := float64(size) / 2
+	border := float64(lineWidth(size))
+
+	// 到六边形边缘的距离，负数表示在六边形以外
+	inside := func(x, y float64) float64 {
+		dx, dy := math.Abs(x-radius), math.Abs(y-radius)
+		return math.Min(radius*math.Sqrt(3)/2-dx, (radius-dy-dx/math.Sqrt(3))*math.Sqrt(3)/2)
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			d := inside(float64(x)+0.5, float64(y)+0.5)
+			switch {
+			case d < 0:
+			case d < border:
+				img.Set(x, y, edge)
+			default:
+				img.Set(x, y, src.At(x, y))
+			}
+		}
+	}
+	return img
+}
+
+func drawTile(tileSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	// 填充浅灰色背景
+	bgColor := color.RGBA{200, 200, 200, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	// 绘制3D效果的边框，宽度随尺寸缩放
+	lightColor := color.RGBA{230, 230, 230, 255}
+	darkColor := color.RGBA{160, 160, 160, 255}
+	border := lineWidth(tileSize)
+
+	for w := 0; w < border; w++ {
+		// 上边和左边（亮色）
+		for i := 0; i < tileSize; i++ {
+			img.Set(i, w, lightColor) // 上边
+			img.Set(w, i, lightColor) // 左边
+		}
+
+		// 下边和右边（暗色）
+		for i := 0; i < tileSize; i++ {
+			img.Set(i, tileSize-1-w, darkColor) // 下边
+			img.Set(tileSize-1-w, i, darkColor) // 右边
+		}
+	}
+
+	return img
+}
+
+func drawRevealed(tileSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	// 填充深灰色背景
+	bgColor := color.RGBA{180, 180, 180, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	return img
+}
+
+func drawMine(tileSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	// 填充深灰色背景
+	bgColor := color.RGBA{180, 180, 180, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	drawMineShape(img, tileSize)
+
+	return img
+}
+
+// 踩中的地雷：红色背景
+func drawExploded(tileSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	bgColor := color.RGBA{230, 30, 30, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	drawMineShape(img, tileSize)
+
+	return img
+}
+
+// 插错旗的格子：地雷上画红色叉
+func drawMisflag(tileSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	bgColor := color.RGBA{180, 180, 180, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	drawMineShape(img, tileSize)
+
+	crossColor := color.RGBA{255, 0, 0, 255}
+	width := 2 * lineWidth(tileSize)
+	for i := tileSize / 5; i < tileSize*4/5; i++ {
+		for w := 0; w < width; w++ {
+			img.Set(i+w, i, crossColor)
+			img.Set(tileSize-1-i-w, i, crossColor)
+		}
+	}
+
+	return img
+}
+
+// 地雷的引信：从地雷右上方伸出，末端的火花逐帧变换大小和颜色
+func drawFuse(tileSize, frame, frames int) *image.RGBA {
+	img := drawMine(tileSize)
+
+	fuseColor := color.RGBA{90, 70, 50, 255}
+	center := tileSize / 2
+	length := tileSize / 6
+	for i := 0; i < length; i++ {
+		for w := 0; w < lineWidth(tileSize); w++ {
+			img.Set(center+tileSize/6+i+w, center-tileSize/6-i, fuseColor)
+		}
+	}
+
+	sparks := []color.RGBA{{255, 240, 120, 255}, {255, 170, 40, 255}, {255, 255, 220, 255}, {240, 100, 30, 255}}
+	sx, sy := center+tileSize/6+length, center-tileSize/6-length
+	radius := float64(tileSize) / 16 * (1 + float64(frame%2))
+	fillCircle(img, float64(sx), float64(sy), radius, sparks[frame%len(sparks)])
+	return img
+}
+
+// 迎风飘动的旗帜：旗面的尖端随帧上下摆动
+func drawFlagWave(tileSize, frame, frames int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	bgColor := color.RGBA{200, 200, 200, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	poleColor := color.RGBA{80, 80, 80, 255}
+	for y := tileSize / 4; y < tileSize*3/4; y++ {
+		for w := 0; w < lineWidth(tileSize); w++ {
+			img.Set(tileSize/2-w, y, poleColor)
+		}
+	}
+
+	// 旗面的上下边缘都从旗杆连到尖端，尖端的高度按正弦摆动
+	flagColor := color.RGBA{255, 0, 0, 255}
+	top, bottom := float64(tileSize)/4, float64(tileSize)/2
+	tipX := float64(tileSize) * 3 / 4
+	tipY := (top+bottom)/2 + math.Sin(2*math.Pi*float64(frame)/float64(frames))*float64(tileSize)/16
+	for x := tileSize / 2; x < tileSize*3/4; x++ {
+		t := (float64(x) - float64(tileSize)/2) / (tipX - float64(tileSize)/2)
+		y0 := top + (tipY-top)*t
+		y1 := bottom + (tipY-bottom)*t
+		for y := int(math.Round(y0)); y < int(math.Round(y1)); y++ {
+			img.Set(x, y, flagColor)
+		}
+	}
+
+	return img
+}
+
+// 爆炸：火球从地雷处扩散并逐渐变暗，最后一帧与踩中的地雷贴图相同
+func drawExplosionFrame(tileSize, frame, frames int) *image.RGBA {
+	img := drawExploded(tileSize)
+	if frame == frames-1 {
+		return img
+	}
+
+	t := float64(frame) / float64(frames-1)
+	center := float64(tileSize) / 2
+	outer := color.RGBA{255, uint8(200 - 150*t), 40, 255}
+	inner := color.RGBA{255, 255, uint8(200 - 200*t), 255}
+	radius := center * (0.4 + 0.6*t)
+	fillCircle(img, center, center, radius, outer)
+	fillCircle(img, center, center, radius*(1-t)*0.6, inner)
+	return img
+}
+
+// 以 (cx, cy) 为圆心填充半径为 radius 的圆
+func fillCircle(img *image.RGBA, cx, cy, radius float64, clr color.RGBA) {
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x, y, clr)
+			}
+		}
+	}
+}
+
+// 绘制地雷（黑色圆形）
+func drawMineShape(img *image.RGBA, tileSize int) {
+	mineColor := color.RGBA{0, 0, 0, 255}
+	center := tileSize / 2
+	radius := tileSize / 4
+
+	for y := 0; y < tileSize; y++ {
+		for x := 0; x < tileSize; x++ {
+			dx := float64(x - center)
+			dy := float64(y - center)
+			if dx*dx+dy*dy <= float64(radius*radius) {
+				img.Set(x, y, mineColor)
+			}
+		}
+	}
+}
+
+func drawFlag(tileSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+
+	// 填充浅灰色背景
+	bgColor := color.RGBA{200, 200, 200, 255}
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	// 绘制旗杆（深灰色）
+	poleColor := color.RGBA{80, 80, 80, 255}
+	for y := tileSize / 4; y < tileSize*3/4; y++ {
+		for w := 0; w < lineWidth(tileSize); w++ {
+			img.Set(tileSize/2-w, y, poleColor)
+		}
+	}
+
+	// 绘制旗帜（红色三角形）
+	flagColor := color.RGBA{255, 0, 0, 255}
+	for y := tileSize / 4; y < tileSize/2; y++ {
+		for x := tileSize / 2; x < tileSize*3/4; x++ {
+			if float64(x-tileSize/2) < float64(y-tileSize/4)*1.5 {
+				img.Set(x, y, flagColor)
+			}
+		}
+	}
+
+	return img
+}
+
+// 线条宽度，32 像素及以下为 1 像素
+func lineWidth(tileSize int) int {
+	if w := tileSize / 32; w > 1 {
+		return w
+	}
+	return 1
+}
+
+func saveImage(img *image.RGBA, name string, size int) error {
+	fullPath := filepath.Join("assets", "images", fmt.Sprintf("%s_%d.png", name, size))
+	f, err := os.Create(fullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
@@ -1,160 +1,208 @@
-package sounds
-
-import (
-	"encoding/binary"
-	"math"
-	"math/rand"
-	"os"
-	"path/filepath"
-	"time"
-)
-
-const (
-	sampleRate = 44100
-	duration   = 0.2 // 音效持续时间（秒）
-)
-
-// WAV文件头结构
-type wavHeader struct {
-	ChunkID       [4]byte // "RIFF"
-	ChunkSize     uint32  // 文件大小 - 8
-	Format        [4]byte // "WAVE"
-	Subchunk1ID   [4]byte // "fmt "
-	Subchunk1Size uint32  // 16 for PCM
-	AudioFormat   uint16  // 1 for PCM
-	NumChannels   uint16  // 1 for mono
-	SampleRate    uint32  // 44100
-	ByteRate      uint32  // SampleRate * NumChannels * BitsPerSample/8
-	BlockAlign    uint16  // NumChannels * BitsPerSample/8
-	BitsPerSample uint16  // 16
-	Subchunk2ID   [4]byte // "data"
-	Subchunk2Size uint32  // 数据大小
-}
-
-func init() {
-	// 初始化随机数生成器
-	rand.Seed(time.Now().UnixNano())
-}
-
-// GenerateSounds 生成所有音效
-func GenerateSounds() error {
-	// 创建目录
-	os.MkdirAll("assets/sounds", 0755)
-
-	// 生成所有音效
-	if err := generateClick(); err != nil {
-		return err
-	}
-	if err := generateExplosion(); err != nil {
-		return err
-	}
-	if err := generateWin(); err != nil {
-		return err
-	}
-	if err := generateFlag(); err != nil {
-		return err
-	}
-	return nil
-}
-
-func generateClick() error {
-	samples := make([]byte, int(sampleRate*duration)*2)
-	frequency := 440.0 // A4音符
-
-	for i := 0; i < len(samples)/2; i++ {
-		t := float64(i) / sampleRate
-		amplitude := math.Exp(-t * 20.0) // 衰减
-		v := int16(amplitude * 32767.0 * math.Sin(2.0*math.Pi*frequency*t))
-		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
-	}
-
-	return saveWav("click.wav", samples)
-}
-
-func generateExplosion() error {
-	samples := make([]byte, int(sampleRate*duration)*2)
-	baseFreq := 100.0
-
-	for i := 0; i < len(samples)/2; i++ {
-		t := float64(i) / sampleRate
-		amplitude := math.Exp(-t * 10.0)
-		// 使用噪声和基础频率的组合
-		noise := (rand.Float64()*2 - 1) * amplitude * 32767.0
-		freq := baseFreq * (1.0 + math.Sin(2.0*math.Pi*10.0*t)*0.5)
-		signal := math.Sin(2.0*math.Pi*freq*t) * amplitude * 32767.0
-		v := int16((noise + signal) * 0.5)
-		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
-	}
-
-	return saveWav("explosion.wav", samples)
-}
-
-func generateWin() error {
-	samples := make([]byte, int(sampleRate*duration)*2)
-	frequencies := []float64{523.25, 659.25, 783.99} // C5, E5, G5
-
-	for i := 0; i < len(samples)/2; i++ {
-		t := float64(i) / sampleRate
-		amplitude := math.Exp(-t * 5.0)
-		v := 0.0
-		for _, freq := range frequencies {
-			v += math.Sin(2.0 * math.Pi * freq * t)
-		}
-		v = v * amplitude * 10922.0 // 32767/3
-		sample := int16(v)
-		binary.LittleEndian.PutUint16(samples[i*2:], uint16(sample))
-	}
-
-	return saveWav("win.wav", samples)
-}
-
-func generateFlag() error {
-	samples := make([]byte, int(sampleRate*duration)*2)
-	frequency := 880.0 // A5音符
-
-	for i := 0; i < len(samples)/2; i++ {
-		t := float64(i) / sampleRate
-		amplitude := math.Exp(-t * 15.0)
-		v := int16(amplitude * 32767.0 * math.Sin(2.0*math.Pi*frequency*t))
-		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
-	}
-
-	return saveWav("flag.wav", samples)
-}
-
-func saveWav(filename string, samples []byte) error {
-	fullPath := filepath.Join("assets", "sounds", filename)
-	f, err := os.Create(fullPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	// 创建WAV文件头
-	header := wavHeader{
-		ChunkID:       [4]byte{'R', 'I', 'F', 'F'},
-		Format:        [4]byte{'W', 'A', 'V', 'E'},
-		Subchunk1ID:   [4]byte{'f', 'm', 't', ' '},
-		Subchunk1Size: 16,
-		AudioFormat:   1,
-		NumChannels:   1,
-		SampleRate:    sampleRate,
-		BitsPerSample: 16,
-		Subchunk2ID:   [4]byte{'d', 'a', 't', 'a'},
-		Subchunk2Size: uint32(len(samples)),
-	}
-
-	// 计算其他字段
-	header.ByteRate = header.SampleRate * uint32(header.NumChannels) * uint32(header.BitsPerSample) / 8
-	header.BlockAlign = header.NumChannels * header.BitsPerSample / 8
-	header.ChunkSize = 36 + header.Subchunk2Size
-
-	// 写入文件头
-	if err := binary.Write(f, binary.LittleEndian, &header); err != nil {
-		return err
-	}
-
-	// 写入音频数据
-	_, err = f.Write(samples)
-	return err
-}
+package sounds
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+const (
+	sampleRate = 44100
+	duration   = 0.2 // 音效持续时间（秒）
+)
+
+// WAV文件头结构
+type wavHeader struct {
+	ChunkID       [4]byte // "RIFF"
+	ChunkSize     uint32  // 文件大小 - 8
+	Format        [4]byte // "WAVE"
+	Subchunk1ID   [4]byte // "fmt "
+	Subchunk1Size uint32  // 16 for PCM
+	AudioFormat   uint16  // 1 for PCM
+	NumChannels   uint16  // 1 for mono
+	SampleRate    uint32  // 44100
+	ByteRate      uint32  // SampleRate * NumChannels * BitsPerSample/8
+	BlockAlign    uint16  // NumChannels * BitsPerSample/8
+	BitsPerSample uint16  // 16
+	Subchunk2ID   [4]byte // "data"
+	Subchunk2Size uint32  // 数据大小
+}
+
+// GenerateSounds 生成所有音效
+func GenerateSounds() error {
+	// 创建目录
+	os.MkdirAll("assets/sounds", 0755)
+
+	// 生成所有音效
+	if err := generateClick(); err != nil {
+		return err
+	}
+	if err := generateExplosion(); err != nil {
+		return err
+	}
+	if err := generateWin(); err != nil {
+		return err
+	}
+	if err := generateFlag(); err != nil {
+		return err
+	}
+	if err := generatePing(); err != nil {
+		return err
+	}
+	if err := generateCascade(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func generateClick() error {
+	samples := make([]byte, int(sampleRate*duration)*2)
+	frequency := 440.0 // A4音符
+
+	for i := 0; i < len(samples)/2; i++ {
+		t := float64(i) / sampleRate
+		amplitude := math.Exp(-t * 20.0) // 衰减
+		v := int16(amplitude * 32767.0 * math.Sin(2.0*math.Pi*frequency*t))
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
+	}
+
+	return saveWav("click.wav", samples)
+}
+
+func generateExplosion() error {
+	samples := make([]byte, int(sampleRate*duration)*2)
+	baseFreq := 100.0
+
+	for i := 0; i < len(samples)/2; i++ {
+		t := float64(i) / sampleRate
+		amplitude := math.Exp(-t * 10.0)
+		// 使用噪声和基础频率的组合
+		noise := (rand.Float64()*2 - 1) * amplitude * 32767.0
+		freq := baseFreq * (1.0 + math.Sin(2.0*math.Pi*10.0*t)*0.5)
+		signal := math.Sin(2.0*math.Pi*freq*t) * amplitude * 32767.0
+		v := int16((noise + signal) * 0.5)
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
+	}
+
+	return saveWav("explosion.wav", samples)
+}
+
+func generateWin() error {
+	samples := make([]byte, int(sampleRate*duration)*2)
+	frequencies := []float64{523.25, 659.25, 783.99} // C5, E5, G5
+
+	for i := 0; i < len(samples)/2; i++ {
+		t := float64(i) / sampleRate
+		amplitude := math.Exp(-t * 5.0)
+		v := 0.0
+		for _, freq := range frequencies {
+			v += math.Sin(2.0 * math.Pi * freq * t)
+		}
+		v = v * amplitude * 10922.0 // 32767/3
+		sample := int16(v)
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(sample))
+	}
+
+	return saveWav("win.wav", samples)
+}
+
+func generateFlag() error {
+	samples := make([]byte, int(sampleRate*duration)*2)
+	frequency := 880.0 // A5音符
+
+	for i := 0; i < len(samples)/2; i++ {
+		t := float64(i) / sampleRate
+		amplitude := math.Exp(-t * 15.0)
+		v := int16(amplitude * 32767.0 * math.Sin(2.0*math.Pi*frequency*t))
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
+	}
+
+	return saveWav("flag.wav", samples)
+}
+
+// 合作模式的提示音：两个快速上升的短音
+func generatePing() error {
+	samples := make([]byte, int(sampleRate*duration)*2)
+	half := duration / 2
+
+	for i := 0; i < len(samples)/2; i++ {
+		t := float64(i) / sampleRate
+		frequency := 1318.51 // E6
+		local := t
+		if t >= half {
+			frequency = 1760.0 // A6
+			local = t - half
+		}
+		amplitude := math.Exp(-local*30.0) * 0.6
+		v := int16(amplitude * 32767.0 * math.Sin(2.0*math.Pi*frequency*t))
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(v))
+	}
+
+	return saveWav("ping.wav", samples)
+}
+
+// 连锁翻开：多个音高递增、逐渐减弱的点击声层叠在一起
+func generateCascade() error {
+	const length = 0.4
+	samples := make([]byte, int(sampleRate*length)*2)
+	const clicks = 6
+	const spacing = 0.04
+
+	for i := 0; i < len(samples)/2; i++ {
+		t := float64(i) / sampleRate
+		v := 0.0
+		for k := 0; k < clicks; k++ {
+			start := float64(k) * spacing
+			if t < start {
+				break
+			}
+			local := t - start
+			frequency := 440.0 * math.Pow(2, float64(k)/12*2) // 每次升高一个全音
+			gain := 1.0 - float64(k)*0.12
+			v += gain * math.Exp(-local*25.0) * math.Sin(2.0*math.Pi*frequency*local)
+		}
+		v = math.Max(-1, math.Min(1, v*0.45))
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(int16(v*32767.0)))
+	}
+
+	return saveWav("cascade.wav", samples)
+}
+
+func saveWav(filename string, samples []byte) error {
+	fullPath := filepath.Join("assets", "sounds", filename)
+	f, err := os.Create(fullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 创建WAV文件头
+	header := wavHeader{
+		ChunkID:       [4]byte{'R', 'I', 'F', 'F'},
+		Format:        [4]byte{'W', 'A', 'V', 'E'},
+		Subchunk1ID:   [4]byte{'f', 'm', 't', ' '},
+		Subchunk1Size: 16,
+		AudioFormat:   1,
+		NumChannels:   1,
+		SampleRate:    sampleRate,
+		BitsPerSample: 16,
+		Subchunk2ID:   [4]byte{'d', 'a', 't', 'a'},
+		Subchunk2Size: uint32(len(samples)),
+	}
+
+	// 计算其他字段
+	header.ByteRate = header.SampleRate * uint32(header.NumChannels) * uint32(header.BitsPerSample) / 8
+	header.BlockAlign = header.NumChannels * header.BitsPerSample / 8
+	header.ChunkSize = 36 + header.Subchunk2Size
+
+	// 写入文件头
+	if err := binary.Write(f, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+
+	// 写入音频数据
+	_, err = f.Write(samples)
+	return err
+}
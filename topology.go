@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"strings"
+
+	"minesweeper/board"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 棋盘的相邻关系由 board.Topology 决定，对局开始时选定。数字、连锁翻开、安全区、
+// 3BV 和推理都按拓扑计算，新的玩法只需要实现新的拓扑。
+// 非默认拓扑的对局单独记录成绩，不计入种子记录，无猜模式也不适用
+
+// 拓扑的显示名称
+var topologyTitles = map[string]string{
+	"square":  "经典",
+	"square4": "四邻格",
+	"torus":   "环面",
+	"knight":  "马步",
+	"hex":     "六边形",
+}
+
+// 方格棋盘上可以使用的拓扑的名称，六边形棋盘有单独的界面，见 hex.go
+func squareTopologies() []string {
+	var names []string
+	for _, t := range board.Topologies {
+		if _, hex := t.(board.Hex); !hex {
+			names = append(names, t.Name())
+		}
+	}
+	return names
+}
+
+// 按名称查找方格棋盘上可以使用的拓扑，空名称为经典棋盘
+func squareTopology(name string) (board.Topology, bool) {
+	if name == "" {
+		return board.Square8{}, true
+	}
+	t, ok := board.TopologyByName(name)
+	if _, hex := t.(board.Hex); hex {
+		return board.Square8{}, false
+	}
+	return t, ok
+}
+
+// 以指定的拓扑和形状开始新的一局，棋盘大小与难度相同，shape 为空时是完整的矩形棋盘
+func (g *Game) newVariantRound(difficulty Difficulty, t board.Topology, shape string) error {
+	return g.newVariantRoundSized(difficulty, g.sizeFor(difficulty), t, shape)
+}
+
+// 与 newVariantRound 相同，棋盘大小由 config 指定
+func (g *Game) newVariantRoundSized(difficulty Difficulty, config DifficultyConfig, t board.Topology, shape string) error {
+	prev := g.variant()
+	if err := g.newRoundSized(difficulty, config); err != nil {
+		return err
+	}
+	g.topology = t
+	if shape != "" {
+		mask, err := g.loadShape(shape)
+		if err != nil {
+			slog.Warn("加载形状失败", "err", err)
+			showToast(tr("无法加载形状"))
+		}
+		g.shape = mask
+	}
+	// 切换到其他玩法时提示一次，重新开始时不再提示
+	if v := g.variant(); v != "" && v != prev {
+		showToast(variantTitle(v))
+	}
+	return nil
+}
+
+// 以当前难度、拓扑和形状重新开始，谜题从头再来
+func (g *Game) restartRound() error {
+	if g.arcade != nil {
+		return g.continueArcade()
+	}
+	if g.puzzle != nil {
+		return g.beginPuzzle(g.puzzle)
+	}
+	return g.newVariantRound(g.difficulty, g.topology, g.shapeName())
+}
+
+// 非默认拓扑的名称，经典棋盘为空
+func (g *Game) topologyName() string {
+	if g.topology == (board.Square8{}) {
+		return ""
+	}
+	return g.topology.Name()
+}
+
+// 玩法的名称，由自定义尺寸、拓扑和形状组成，如 "knight+heart"，谜题为 "puzzle"，经典棋盘为空，用于区分成绩
+func (g *Game) variant() string {
+	// 机器人参与过的对局不与玩家自己的成绩混在一起
+	if g.bot != nil {
+		return "bot"
+	}
+	// 调试模式等辅助看到过地雷或推理结果，同样单独记录
+	if g.assisted {
+		return "assist"
+	}
+	if g.puzzle != nil {
+		return "puzzle"
+	}
+	if g.arcade != nil {
+		return "arcade"
+	}
+	var parts []string
+	// 自定义难度的尺寸和地雷数由启动参数决定，按尺寸分别记录，如 "30x20-120"
+	if g.difficulty == Custom {
+		parts = append(parts, fmt.Sprintf("%dx%d-%d", g.gridWidth, g.gridHeight, g.mineCount()))
+	}
+	if name := g.topologyName(); name != "" {
+		parts = append(parts, name)
+	}
+	if g.shape != nil {
+		parts = append(parts, g.shape.Name)
+	}
+	if len(g.relocations) > 0 {
+		parts = append(parts, "lives")
+	}
+	if g.noFlags {
+		parts = append(parts, "nf")
+	}
+	if g.countdownActive() {
+		parts = append(parts, "countdown")
+	}
+	if g.retry {
+		parts = append(parts, "retry")
+	}
+	return strings.Join(parts, "+")
+}
+
+// 玩法的显示名称，如 "马步 · 爱心"
+func variantTitle(v string) string {
+	var titles []string
+	for _, part := range strings.Split(v, "+") {
+		if title, ok := topologyTitles[part]; ok {
+			part = title
+		} else if title, ok := shapeTitles[part]; ok {
+			part = title
+		} else if part == "puzzle" {
+			part = "谜题"
+		} else if part == "bot" {
+			part = "机器人"
+		} else if part == "assist" {
+			part = "辅助"
+		} else if part == "lives" {
+			part = "轻松"
+		} else if part == "nf" {
+			part = "无旗"
+		} else if part == "countdown" {
+			part = "限时"
+		} else if part == "arcade" {
+			part = "街机"
+		} else if part == "retry" {
+			part = "重试"
+		}
+		titles = append(titles, tr(part))
+	}
+	return strings.Join(titles, " · ")
+}
+
+// 本局的难度和玩法，如 "自定义 · 谜题"，窗口标题和分享的结果共用
+func (g *Game) roundTitle() string {
+	title := tr(difficultyNames[g.difficulty])
+	if v := g.variant(); v != "" {
+		title += " · " + variantTitle(v)
+	}
+	return title
+}
+
+// 棋盘的边缘是否与对边相连，相连时镜头可以无限滚动
+func (g *Game) wraps() bool {
+	_, ok := g.topology.(board.Torus)
+	return ok
+}
+
+// 绘制棋盘缓存的位置偏移。环面棋盘在镜头越过边缘时需要在右侧和下方再画一份
+func (g *Game) boardTiles() []image.Point {
+	if !g.wraps() {
+		return []image.Point{{}}
+	}
+	w, h := g.gridWidth*cellSize, g.gridHeight*cellSize
+	return []image.Point{{}, {w, 0}, {0, h}, {w, h}}
+}
+
+// 把相对镜头的坐标换算到一圈之内，左侧和上方只露出一部分的格子画在负坐标处
+func torusScreenPos(p, size int) int {
+	p = (p%size + size) % size
+	if p > size-cellSize {
+		p -= size
+	}
+	return p
+}
+
+// 非经典拓扑中鼠标停在已翻开的数字上时，框出它计数的格子，马步棋盘上尤其需要
+func (g *Game) drawNeighborHint(screen *ebiten.Image) {
+	if g.topologyName() == "" || g.gameOver || g.won {
+		return
+	}
+	x, y, ok := g.cellAt(cursorPosition())
+	if !ok || !g.grid[y][x].revealed || g.grid[y][x].neighbors == 0 {
+		return
+	}
+	for _, p := range g.adjacency().Neighbors(nil, g.gridWidth, g.gridHeight, x, y) {
+		px, py := g.cellScreenPos(p[0], p[1])
+		vector.StrokeRect(screen, px+2, py+2, cellSize-4, cellSize-4, 2, color.RGBA{255, 200, 0, 200}, false)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	transitionOutFrames  = 18 // 旧棋盘滑出淡出的帧数
+	transitionDropFrames = 12 // 单个格子落下所需帧数
+	transitionSpread     = 24 // 最后一个格子相对第一个格子的延迟帧数
+	transitionDropHeight = 3 * cellSize
+)
+
+// 重启或切换难度时的棋盘过渡动画，进行中时锁定输入
+type boardTransition struct {
+	oldBoard *ebiten.Image
+	frame    int
+}
+
+// 将当前棋盘绘制到离屏图像，作为过渡动画中的旧棋盘
+func (g *Game) snapshotBoard() *ebiten.Image {
+	img := ebiten.NewImage(g.viewWidth(), g.viewHeight())
+	g.drawBoard(img)
+	return img
+}
+
+func (g *Game) startTransition(oldBoard *ebiten.Image) {
+	if !globalConfig.Animations {
+		oldBoard.Dispose()
+		return
+	}
+	g.transition = &boardTransition{oldBoard: oldBoard}
+}
+
+func (g *Game) updateTransition() {
+	g.transition.frame++
+	if g.transition.frame < g.transitionFrames() {
+		return
+	}
+
+	g.transition.oldBoard.Dispose()
+	g.transition = nil
+	// 动画期间不计时
+	if !g.firstClick {
+		g.startTime = time.Now().Add(-g.elapsedTime)
+	}
+}
+
+// 整个过渡动画的总帧数
+func (g *Game) transitionFrames() int {
+	return transitionOutFrames/2 + transitionSpread + transitionDropFrames
+}
+
+// 格子 (x, y) 开始落下前的延迟帧数，从左上角向右下角依次落下
+func (g *Game) tileDelay(x, y int) int {
+	steps := (g.viewWidth()+g.viewHeight())/cellSize - 2
+	if steps == 0 {
+		return 0
+	}
+	return (x + y) * transitionSpread / steps
+}
+
+func (g *Game) drawTransition(screen *ebiten.Image) {
+	t := g.transition
+
+	// 旧棋盘向下滑出并淡出
+	if t.frame < transitionOutFrames {
+		progress := float64(t.frame) / transitionOutFrames
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(0, progress*float64(t.oldBoard.Bounds().Dy())/4)
+		op.ColorScale.ScaleAlpha(float32(1 - progress))
+		screen.DrawImage(t.oldBoard, op)
+	}
+
+	// 新棋盘的格子依次从上方落下，新的一局镜头位于左上角，只需绘制窗口内的格子
+	start := t.frame - transitionOutFrames/2
+	for y := 0; y < g.viewHeight()/cellSize; y++ {
+		for x := 0; x < g.viewWidth()/cellSize; x++ {
+			local := start - g.tileDelay(x, y)
+			if local < 0 {
+				continue
+			}
+			progress := float64(local) / transitionDropFrames
+			if progress > 1 {
+				progress = 1
+			}
+			// 缓出曲线
+			eased := 1 - (1-progress)*(1-progress)
+			g.drawCell(screen, x, y, -(1-eased)*transitionDropHeight, float32(eased))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "time"
+
+// 连锁翻开时格子从点击处逐圈向外显示，整个波纹所需的时间，与空白区域的大小无关
+const revealRippleDuration = 150 * time.Millisecond
+
+// 连锁翻开的波纹，只是显示效果。翻开、计数和胜负判断仍在点击的那一帧完成，
+// 之后每个格子按它与点击处的距离分到一个显示时间，时间未到的格子仍显示为未翻开，
+// 只有新出现的格子需要重绘。棋盘最大 maxGridSize 见方，一次连锁翻开在一帧内完成，
+// 无需分帧；无尽模式的区块按需生成，见 endless.go
+type revealWave struct {
+	start   time.Time
+	at      [][]time.Duration // 每个格子相对 start 的显示时间，-1 表示不属于本次波纹
+	elapsed time.Duration     // 最近一次 tick 时经过的时间
+}
+
+func (g *Game) startRevealWave(x, y int, opened []cellPos) {
+	if !globalConfig.ProgressiveReveal || len(opened) < 2 {
+		return
+	}
+	w := &revealWave{start: time.Now(), at: make([][]time.Duration, len(g.grid))}
+	for i := range w.at {
+		w.at[i] = make([]time.Duration, len(g.grid[i]))
+		for j := range w.at[i] {
+			w.at[i][j] = -1
+		}
+	}
+
+	// 沿翻开的格子按相邻关系逐圈扩散，环面等拓扑上同样从点击处向外
+	dist := map[cellPos]int{{x, y}: 0}
+	inWave := make(map[cellPos]bool, len(opened))
+	for _, p := range opened {
+		inWave[p] = true
+	}
+	queue, maxDist := []cellPos{{x, y}}, 0
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range g.adjacency().Neighbors(nil, g.gridWidth, g.gridHeight, p.x, p.y) {
+			q := cellPos{n[0], n[1]}
+			if _, seen := dist[q]; seen || !inWave[q] {
+				continue
+			}
+			dist[q] = dist[p] + 1
+			if dist[q] > maxDist {
+				maxDist = dist[q]
+			}
+			queue = append(queue, q)
+		}
+	}
+	if maxDist == 0 {
+		return
+	}
+	for _, p := range opened {
+		w.at[p.y][p.x] = revealRippleDuration * time.Duration(dist[p]) / time.Duration(maxDist)
+	}
+	// 新的连锁翻开开始时，上一个波纹直接显示完毕
+	g.wave = w
+}
+
+func (w *revealWave) tick(now time.Time) bool {
+	w.elapsed = now.Sub(w.start)
+	return w.elapsed < revealRippleDuration
+}
+
+// 格子当前应显示的状态，波纹尚未到达的格子仍显示为未翻开
+func (g *Game) shownCell(x, y int) Cell {
+	cell := g.grid[y][x]
+	if w := g.wave; w != nil && w.at[y][x] > w.elapsed {
+		cell.revealed = false
+	}
+	return cell
+}
+
+func chebyshev(dx, dy int) int {
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
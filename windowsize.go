@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	zoomStep    = 0.25
+	minZoom     = 0.5
+	maxZoom     = 4
+	resizeDelay = 500 * time.Millisecond // 拖动窗口停止这么久后才保存大小
+)
+
+// 每个难度上次使用的窗口大小和缩放比例，切换难度时恢复。
+// 自定义尺寸的棋盘（包括谜题和街机）按棋盘大小分别记录
+type windowPreset struct {
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Zoom   float64 `json:"zoom"` // 相对 32 像素格子的缩放比例
+}
+
+// 窗口的位置、大小和所在的显示器，退出时保存，下次启动时恢复。
+// 位置相对于所在显示器的左上角，显示器已经不存在时只恢复大小
+type windowGeometry struct {
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Monitor string `json:"monitor,omitempty"`
+}
+
+// 按配置恢复窗口，没有记录或全屏时按画布的原始大小
+func restoreWindowGeometry(g *Game) {
+	w := globalConfig.Window
+	if w == nil || w.Width <= 0 || w.Height <= 0 || fullscreen() {
+		setWindowSize(g.canvasWidth(), g.canvasHeight())
+		return
+	}
+	setWindowSize(w.Width, w.Height)
+	for _, m := range ebiten.AppendMonitors(nil) {
+		if m.Name() == w.Monitor {
+			ebiten.SetMonitor(m)
+			ebiten.SetWindowPosition(w.X, w.Y)
+			return
+		}
+	}
+}
+
+// 每帧记录窗口当前的位置和大小，退出时由 main 写入配置。全屏和最小化时不记录
+func trackWindowGeometry() {
+	if fullscreen() || ebiten.IsWindowMinimized() {
+		return
+	}
+	x, y := ebiten.WindowPosition()
+	w, h := ebiten.WindowSize()
+	geo := windowGeometry{X: x, Y: y, Width: w, Height: h}
+	if m := ebiten.Monitor(); m != nil {
+		geo.Monitor = m.Name()
+	}
+	if globalConfig.Window == nil || *globalConfig.Window != geo {
+		globalConfig.Window = &geo
+	}
+}
+
+// 窗口大小的跟踪状态，区分玩家拖动和程序设置的大小
+var windowSize struct {
+	width, height int       // 最近一次设置或保存的大小
+	changedAt     time.Time // 玩家调整后尚未保存时为调整的时间
+}
+
+// 预设在配置中的键，标准难度为难度名，自定义尺寸如 "custom/30x20"
+func (g *Game) windowPresetKey() string {
+	if g.difficulty == Custom {
+		return fmt.Sprintf("%s/%dx%d", difficultyKeys[Custom], g.gridWidth, g.gridHeight)
+	}
+	return difficultyKeys[g.difficulty]
+}
+
+func (g *Game) windowPreset() windowPreset {
+	return globalConfig.Windows[g.windowPresetKey()]
+}
+
+// 按当前难度的记录设置窗口大小，没有记录时按画布的原始大小
+func (g *Game) applyWindowPreset() {
+	p := g.windowPreset()
+	w, h := g.canvasWidth(), g.canvasHeight()
+	switch {
+	case p.Width > 0 && p.Height > 0:
+		w, h = p.Width, p.Height
+	case p.Zoom > 0:
+		w, h = int(float64(w)*p.Zoom), int(float64(h)*p.Zoom)
+	}
+	setWindowSize(w, h)
+}
+
+func setWindowSize(w, h int) {
+	windowSize.width, windowSize.height = w, h
+	windowSize.changedAt = time.Time{}
+	ebiten.SetWindowSize(w, h)
+}
+
+// 按步长缩放窗口，dir 为 0 时恢复原始大小
+func (g *Game) zoom(dir int) error {
+	p := g.windowPreset()
+	z := p.Zoom
+	if z <= 0 {
+		z = g.currentZoom()
+	}
+	z = math.Round(z/zoomStep) * zoomStep
+	if dir == 0 {
+		z = 1
+	} else {
+		z = math.Max(minZoom, math.Min(maxZoom, z+float64(dir)*zoomStep))
+	}
+	w, h := int(float64(g.canvasWidth())*z), int(float64(g.canvasHeight())*z)
+	g.saveWindowPreset(windowPreset{Width: w, Height: h, Zoom: z})
+	setWindowSize(w, h)
+	return nil
+}
+
+// 当前窗口相对画布的缩放比例，与系统缩放无关
+func (g *Game) currentZoom() float64 {
+	w, h := ebiten.WindowSize()
+	return math.Min(float64(w)/float64(g.canvasWidth()), float64(h)/float64(g.canvasHeight()))
+}
+
+// 只保存这一个预设，不把其他尚未保存的设置一起写入
+func (g *Game) saveWindowPreset(p windowPreset) {
+	key := g.windowPresetKey()
+	err := patchConfig(func(cfg *Config) {
+		if cfg.Windows == nil {
+			cfg.Windows = make(map[string]windowPreset)
+		}
+		cfg.Windows[key] = p
+	})
+	if err != nil {
+		slog.Warn("保存配置失败", "err", err)
+	}
+}
+
+// 记录玩家拖动调整的窗口大小，停止拖动一段时间后才保存。全屏时不记录
+func (g *Game) updateWindowSize() {
+	trackWindowGeometry()
+	if fullscreen() || ebiten.IsWindowMinimized() || g.scene != ScenePlaying {
+		return
+	}
+	w, h := ebiten.WindowSize()
+	if w != windowSize.width || h != windowSize.height {
+		windowSize.width, windowSize.height = w, h
+		windowSize.changedAt = time.Now()
+		return
+	}
+	if windowSize.changedAt.IsZero() || time.Since(windowSize.changedAt) < resizeDelay {
+		return
+	}
+	windowSize.changedAt = time.Time{}
+	g.saveWindowPreset(windowPreset{Width: w, Height: h, Zoom: g.currentZoom()})
+}
+
+// 当前的窗口标题，只在变化时才设置
+var windowTitle string
+
+func setWindowTitle(title string) {
+	if title != windowTitle {
+		windowTitle = title
+		ebiten.SetWindowTitle(title)
+	}
+}
+
+// 对局中在标题上显示难度、剩余地雷数和用时，窗口在任务栏或缩略图中也能看到进度
+func (g *Game) updateWindowTitle() {
+	title := tr("扫雷游戏")
+	if g.scene == ScenePlaying {
+		title = fmt.Sprintf("%s – %s – %d💣 – %s", title, g.roundTitle(),
+			g.minesLeft(), formatDuration(g.elapsedTime))
+	}
+	setWindowTitle(title)
+}